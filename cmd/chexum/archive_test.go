@@ -0,0 +1,85 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Les-El/chexum/internal/color"
+	"github.com/Les-El/chexum/internal/config"
+	"github.com/Les-El/chexum/internal/console"
+	"github.com/Les-El/chexum/internal/errors"
+	"github.com/Les-El/chexum/internal/manifest"
+)
+
+func TestArchiveMembers(t *testing.T) {
+	dir := t.TempDir()
+	bundle := filepath.Join(dir, "bundle.zip")
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("bin/tool")
+	w.Write([]byte("content1"))
+	zw.Close()
+	os.WriteFile(bundle, buf.Bytes(), 0644)
+	loose := filepath.Join(dir, "tool")
+	os.WriteFile(loose, []byte("content1"), 0644)
+	member := bundle + "!/bin/tool"
+	manifestPath := filepath.Join(dir, "out.json")
+
+	// SHA256 of "content1"
+	ref := "d0b425e00e15a0d36b9b361f02bab63563aed6cb4665083905386c55d5b679fa"
+
+	run := func(args ...string) (int, string) {
+		t.Helper()
+		cfg, _, err := config.ParseArgs(args)
+		if err != nil {
+			t.Fatalf("ParseArgs(%v) error = %v", args, err)
+		}
+		var outBuf, errBuf bytes.Buffer
+		streams := &console.Streams{Out: &outBuf, Err: &errBuf}
+		colorHandler := color.NewColorHandler()
+		errHandler := errors.NewErrorHandler(colorHandler)
+		if err := prepareFiles(context.Background(), cfg, errHandler, streams); err != nil {
+			t.Fatalf("prepareFiles() error = %v", err)
+		}
+		return executeMode(context.Background(), cfg, colorHandler, streams, errHandler), outBuf.String()
+	}
+
+	t.Run("members group with loose files", func(t *testing.T) {
+		_, out := run("--archives", "--output-manifest", manifestPath, bundle, loose)
+		groups := strings.Split(out, "\n\n")
+		if !strings.Contains(groups[0], member) || !strings.Contains(groups[0], loose) {
+			t.Errorf("expected member and loose file in one group, got %q", out)
+		}
+
+		m, err := manifest.Load(manifestPath)
+		if err != nil {
+			t.Fatalf("manifest.Load() error = %v", err)
+		}
+		found := false
+		for _, f := range m.Files {
+			found = found || (f.Path == member && f.Hash == ref)
+		}
+		if !found {
+			t.Errorf("manifest does not record %s", member)
+		}
+	})
+
+	t.Run("members take part in pool matching", func(t *testing.T) {
+		code, out := run("--archives", "--any-match", bundle, ref)
+		if code != config.ExitSuccess || !strings.Contains(out, member) {
+			t.Errorf("expected a pool match for %s (exit %d), got %q", member, code, out)
+		}
+	})
+
+	t.Run("opt-in", func(t *testing.T) {
+		_, out := run(bundle)
+		if strings.Contains(out, member) {
+			t.Errorf("members should not be hashed without --archives, got %q", out)
+		}
+	})
+}
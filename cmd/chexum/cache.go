@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Les-El/chexum/internal/cache"
+	"github.com/Les-El/chexum/internal/config"
+	"github.com/Les-El/chexum/internal/console"
+	"github.com/Les-El/chexum/internal/hash"
+)
+
+// attachCache gives computer the persistent hash cache unless --no-cache is
+// set, and returns the function that writes the cache out once hashing is
+// done. The cache only ever saves work, so a cache that cannot be opened or
+// saved is reported and the run carries on without it.
+func attachCache(cfg *config.Config, computer *hash.Computer, streams *console.Streams) func() {
+	if cfg.NoCache {
+		return func() {}
+	}
+	dir := cfg.CacheDir
+	if dir == "" {
+		var err error
+		if dir, err = cache.DefaultDir(); err != nil {
+			if cfg.Verbose {
+				fmt.Fprintf(streams.Err, "Cache: disabled: %v\n", err)
+			}
+			return func() {}
+		}
+	}
+
+	c, err := cache.Open(dir)
+	if err != nil {
+		if !cfg.Quiet {
+			fmt.Fprintf(streams.Err, "Warning: hash cache disabled: %v\n", err)
+		}
+		return func() {}
+	}
+	computer.SetCache(c)
+
+	return func() {
+		stats := c.Stats()
+		if err := c.Close(); err != nil && !cfg.Quiet {
+			fmt.Fprintf(streams.Err, "Warning: could not save hash cache: %v\n", err)
+		}
+		if cfg.Verbose && !cfg.Quiet && stats.Lookups > 0 {
+			fmt.Fprintf(streams.Err, "Cache: %d of %d files unchanged and not read (%s)\n",
+				stats.Hits, stats.Lookups, c.Dir())
+		}
+	}
+}
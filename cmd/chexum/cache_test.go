@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Les-El/chexum/internal/color"
+	"github.com/Les-El/chexum/internal/config"
+	"github.com/Les-El/chexum/internal/console"
+	"github.com/Les-El/chexum/internal/errors"
+	"github.com/Les-El/chexum/internal/hash"
+)
+
+func TestCacheFlags(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.txt")
+	os.WriteFile(file, []byte("hello"), 0644)
+
+	run := func(args ...string) (int, string) {
+		t.Helper()
+		cfg, _, err := config.ParseArgs(args)
+		if err != nil {
+			t.Fatalf("ParseArgs() error = %v", err)
+		}
+		var buf bytes.Buffer
+		streams := &console.Streams{Out: &buf, Err: &buf}
+		colorHandler := color.NewColorHandler()
+		errHandler := errors.NewErrorHandler(colorHandler)
+		if err := prepareFiles(context.Background(), cfg, errHandler, streams); err != nil {
+			t.Fatalf("prepareFiles() error = %v", err)
+		}
+		return executeMode(context.Background(), cfg, colorHandler, streams, errHandler), buf.String()
+	}
+
+	info, _ := os.Stat(file)
+	if _, ok := hash.FileIDOf(info); !ok {
+		t.Skip("the hash cache is not used on this platform")
+	}
+
+	cacheDir := filepath.Join(dir, "cache")
+	code, out := run("--verbose", "--cache-dir", cacheDir, file)
+	if code != config.ExitSuccess || !strings.Contains(out, "Cache: 0 of 1 files unchanged") {
+		t.Errorf("exit = %d, output %q", code, out)
+	}
+	if _, err := os.Stat(cacheDir); err != nil {
+		t.Errorf("--cache-dir was not used: %v", err)
+	}
+
+	unused := filepath.Join(dir, "unused")
+	if code, out := run("--verbose", "--no-cache", "--cache-dir", unused, file); code != config.ExitSuccess || strings.Contains(out, "Cache:") {
+		t.Errorf("exit = %d, output %q", code, out)
+	}
+	if _, err := os.Stat(unused); !os.IsNotExist(err) {
+		t.Error("--no-cache should not touch the cache directory")
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Les-El/chexum/internal/config"
+	"github.com/Les-El/chexum/internal/console"
+	"github.com/Les-El/chexum/internal/errors"
+	"github.com/Les-El/chexum/internal/hash"
+	"github.com/Les-El/chexum/internal/progress"
+	"github.com/Les-El/chexum/internal/security"
+)
+
+// runCheckMode verifies files against the checksum files named in
+// cfg.Files, or read from stdin when there are none (--check). Output and
+// exit status follow sha256sum -c: a "name: OK" or "name: FAILED" line per
+// listed file, warnings summarising what went wrong on stderr, and exit
+// status 1 when anything did not verify. Names in a checksum file are
+// relative to the working directory, not to the checksum file.
+func runCheckMode(ctx context.Context, cfg *config.Config, streams *console.Streams, errHandler *errors.Handler) int {
+	sources := cfg.Files
+	if len(sources) == 0 {
+		sources = []string{"-"}
+	}
+	code := config.ExitSuccess
+	for _, source := range sources {
+		switch c := checkSource(ctx, cfg, source, streams, errHandler); c {
+		case config.ExitSuccess:
+		case config.ExitInterrupted:
+			return c
+		default:
+			code = c
+		}
+	}
+	return code
+}
+
+// checkSource checks the files listed in one checksum file.
+func checkSource(ctx context.Context, cfg *config.Config, source string, streams *console.Streams, errHandler *errors.Handler) int {
+	lines, invalid, err := readChecksums(source, cfg, streams.Input())
+	if err != nil {
+		fmt.Fprintln(streams.Err, errHandler.FormatError(err))
+		return config.ExitNoMatches
+	}
+	name := security.SanitizeOutput(source)
+	if source == "-" {
+		name = "standard input"
+	}
+	if len(lines) == 0 {
+		fmt.Fprintf(streams.Err, "%s: no properly formatted checksum lines found\n", name)
+		return config.ExitNoMatches
+	}
+
+	digests, err := hashListed(ctx, cfg, lines, streams)
+	if err != nil {
+		fmt.Fprintln(streams.Err, errHandler.FormatError(err))
+		return config.ExitInvalidArgs
+	}
+	if ctx.Err() != nil {
+		fmt.Fprintf(streams.Err, "Interrupted: %s was not fully checked\n", name)
+		return config.ExitInterrupted
+	}
+
+	var summary checkSummary
+	for _, line := range lines {
+		summary.add(line, digests[checkKey{line.Path, line.Algorithm}], cfg, streams, errHandler)
+	}
+	return summary.finish(name, invalid, cfg, streams)
+}
+
+// readChecksums parses the checksum file at source, or stdin for "-".
+// The configured algorithms settle which of several algorithms with the
+// same digest length an untagged line is checked with.
+func readChecksums(source string, cfg *config.Config, stdin io.Reader) ([]hash.ChecksumLine, int, error) {
+	r := stdin
+	if source != "-" {
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, 0, err
+		}
+		defer f.Close()
+		r = f
+	}
+	return hash.ParseChecksums(r, cfg.AlgorithmList())
+}
+
+// checkKey identifies one digest to compute. A file listed twice with the
+// same algorithm is read once.
+type checkKey struct {
+	path, algorithm string
+}
+
+// hashListed hashes the files lines list with ComputeBatch, one batch per
+// algorithm so that each file is read only for the algorithms it is listed
+// with.
+func hashListed(ctx context.Context, cfg *config.Config, lines []hash.ChecksumLine, streams *console.Streams) (map[checkKey]hash.Entry, error) {
+	var algorithms []string
+	batches := map[string][]string{}
+	seen := map[checkKey]bool{}
+	for _, line := range lines {
+		key := checkKey{line.Path, line.Algorithm}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if _, ok := batches[line.Algorithm]; !ok {
+			algorithms = append(algorithms, line.Algorithm)
+		}
+		batches[line.Algorithm] = append(batches[line.Algorithm], line.Path)
+	}
+
+	var bar *progress.Bar
+	if !cfg.Quiet && !cfg.Status {
+		bar = progress.NewBar(&progress.Options{
+			Total:       int64(len(seen)),
+			Description: "Checking files...",
+			Writer:      streams.Err,
+		})
+		defer bar.Finish()
+	}
+	digests := make(map[checkKey]hash.Entry, len(seen))
+	for _, algorithm := range algorithms {
+		computer, err := newComputer(cfg, algorithm)
+		if err != nil {
+			return nil, err
+		}
+		workers, finish := prepareComputer(cfg, computer, streams)
+		for entry := range computer.ComputeBatch(ctx, batches[algorithm], workers) {
+			digests[checkKey{entry.Original, algorithm}] = entry
+			if bar != nil {
+				bar.Add(1)
+			}
+		}
+		finish()
+	}
+	return digests, nil
+}
+
+// checkSummary counts the outcomes of the lines of one checksum file.
+type checkSummary struct {
+	verified   int // Read and matched
+	failed     int // Read and did not match
+	unreadable int // Missing or could not be read
+}
+
+// add prints the outcome for one line, as sha256sum -c would, and counts it.
+// A missing file is reported as MISSING rather than "FAILED open or read".
+func (s *checkSummary) add(line hash.ChecksumLine, entry hash.Entry, cfg *config.Config, streams *console.Streams, errHandler *errors.Handler) {
+	var status string
+	switch {
+	case entry.Error != nil && os.IsNotExist(entry.Error):
+		if cfg.IgnoreMissing {
+			return
+		}
+		s.unreadable++
+		status = "MISSING"
+	case entry.Error != nil:
+		s.unreadable++
+		status = "FAILED open or read"
+		if !cfg.Status {
+			fmt.Fprintln(streams.Err, errHandler.FormatError(entry.Error))
+		}
+	case entry.Hash != line.Hash: // Both are lowercase hex
+		s.failed++
+		status = "FAILED"
+	default:
+		s.verified++
+		if cfg.Quiet {
+			return
+		}
+		status = "OK"
+	}
+	if cfg.Status {
+		return
+	}
+	name, escaped := hash.EscapeChecksumPath(line.Path)
+	if escaped {
+		name = `\` + name
+	}
+	fmt.Fprintf(streams.Out, "%s: %s\n", security.SanitizeOutput(name), status)
+}
+
+// finish prints the warnings sha256sum -c prints after a checksum file and
+// returns its exit status: 1 if any listed file failed to verify, if
+// --strict is set and a line was improperly formatted, or if
+// --ignore-missing left nothing to verify.
+func (s checkSummary) finish(name string, invalid int, cfg *config.Config, streams *console.Streams) int {
+	if !cfg.Status {
+		if invalid > 0 {
+			fmt.Fprintf(streams.Err, "WARNING: %s improperly formatted\n", countOf(invalid, "line is", "lines are"))
+		}
+		if s.unreadable > 0 {
+			fmt.Fprintf(streams.Err, "WARNING: %s not be read\n", countOf(s.unreadable, "listed file could", "listed files could"))
+		}
+		if s.failed > 0 {
+			fmt.Fprintf(streams.Err, "WARNING: %s NOT match\n", countOf(s.failed, "computed checksum did", "computed checksums did"))
+		}
+	}
+	if cfg.IgnoreMissing && s.verified+s.failed+s.unreadable == 0 {
+		fmt.Fprintf(streams.Err, "%s: no file was verified\n", name)
+		return config.ExitNoMatches
+	}
+	if s.failed > 0 || s.unreadable > 0 || (cfg.Strict && invalid > 0) {
+		return config.ExitNoMatches
+	}
+	return config.ExitSuccess
+}
+
+// countOf renders n followed by the singular or plural phrase.
+func countOf(n int, singular, plural string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Les-El/chexum/internal/color"
+	"github.com/Les-El/chexum/internal/config"
+	"github.com/Les-El/chexum/internal/console"
+	"github.com/Les-El/chexum/internal/errors"
+)
+
+func TestCheckMode(t *testing.T) {
+	dir := t.TempDir()
+	good, bad := filepath.Join(dir, "good"), filepath.Join(dir, "bad")
+	os.WriteFile(good, []byte("abc"), 0644)
+	os.WriteFile(bad, []byte("tampered"), 0644)
+	const (
+		sha256Abc = "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+		md5Abc    = "900150983cd24fb0d6963f7d28e17f72"
+	)
+	write := func(name string, lines ...string) string {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+		return path
+	}
+	allGood := write("good.sums", sha256Abc+"  "+good, "MD5 ("+good+") = "+md5Abc)
+	mixed := write("mixed.sums",
+		sha256Abc+" *"+good,
+		sha256Abc+"  "+bad,
+		sha256Abc+"  "+filepath.Join(dir, "gone"),
+		"not a checksum line")
+	missing := write("missing.sums", sha256Abc+"  "+good, sha256Abc+"  "+filepath.Join(dir, "gone"))
+	malformed := write("malformed.sums", sha256Abc+"  "+good, "not a checksum line")
+	onlyMissing := write("only-missing.sums", sha256Abc+"  "+filepath.Join(dir, "gone"))
+	empty := write("empty.sums", "# nothing here")
+
+	run := func(stdin string, args ...string) (int, string, string) {
+		t.Helper()
+		cfg, _, err := config.ParseArgs(args)
+		if err != nil {
+			t.Fatalf("ParseArgs(%v) error = %v", args, err)
+		}
+		var out, errOut bytes.Buffer
+		streams := &console.Streams{Out: &out, Err: &errOut, In: strings.NewReader(stdin)}
+		errHandler := errors.NewErrorHandler(color.NewColorHandler())
+		return runCheckMode(context.Background(), cfg, streams, errHandler), out.String(), errOut.String()
+	}
+
+	tests := []struct {
+		name    string
+		stdin   string
+		args    []string
+		code    int
+		out     []string // Lines expected on stdout, in order
+		warning string   // Expected on stderr; "" means stderr must be empty
+	}{
+		{"all good", "", []string{"--check", allGood}, config.ExitSuccess,
+			[]string{good + ": OK", good + ": OK"}, ""},
+		{"mixed", "", []string{"--check", mixed}, config.ExitNoMatches,
+			[]string{good + ": OK", bad + ": FAILED", filepath.Join(dir, "gone") + ": MISSING"},
+			"WARNING: 1 line is improperly formatted\nWARNING: 1 listed file could not be read\nWARNING: 1 computed checksum did NOT match\n"},
+		{"quiet omits OK", "", []string{"--check", "--quiet", mixed}, config.ExitNoMatches,
+			[]string{bad + ": FAILED", filepath.Join(dir, "gone") + ": MISSING"}, "WARNING: 1 computed checksum did NOT match"},
+		{"status prints nothing", "", []string{"--check", "--status", mixed}, config.ExitNoMatches, nil, ""},
+		{"status success", "", []string{"--check", "--status", allGood}, config.ExitSuccess, nil, ""},
+		{"ignore missing", "", []string{"--check", "--ignore-missing", missing}, config.ExitSuccess,
+			[]string{good + ": OK"}, ""},
+		{"ignore missing with nothing verified", "", []string{"--check", "--ignore-missing", onlyMissing}, config.ExitNoMatches,
+			nil, "only-missing.sums: no file was verified"},
+		{"malformed lines only warn", "", []string{"--check", malformed}, config.ExitSuccess,
+			[]string{good + ": OK"}, "WARNING: 1 line is improperly formatted"},
+		{"strict fails on malformed lines", "", []string{"--check", "--strict", malformed}, config.ExitNoMatches,
+			[]string{good + ": OK"}, "WARNING: 1 line is improperly formatted"},
+		{"no checksum lines", "", []string{"--check", empty}, config.ExitNoMatches,
+			nil, "empty.sums: no properly formatted checksum lines found"},
+		{"stdin", md5Abc + "  " + good + "\n", []string{"--check"}, config.ExitSuccess,
+			[]string{good + ": OK"}, ""},
+		{"stdin marker", "junk\n", []string{"--check", "-"}, config.ExitNoMatches,
+			nil, "standard input: no properly formatted checksum lines found"},
+		{"several files", "", []string{"--check", "--quiet", allGood, mixed}, config.ExitNoMatches,
+			[]string{bad + ": FAILED", filepath.Join(dir, "gone") + ": MISSING"}, "WARNING"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, out, errOut := run(tt.stdin, tt.args...)
+			if code != tt.code {
+				t.Errorf("exit = %d, want %d; stdout %q, stderr %q", code, tt.code, out, errOut)
+			}
+			want := ""
+			if len(tt.out) > 0 {
+				want = strings.Join(tt.out, "\n") + "\n"
+			}
+			if out != want {
+				t.Errorf("stdout = %q, want %q", out, want)
+			}
+			if tt.warning == "" && errOut != "" || !strings.Contains(errOut, tt.warning) {
+				t.Errorf("stderr = %q, want %q", errOut, tt.warning)
+			}
+		})
+	}
+
+	if code, _, errOut := run("", "--check", filepath.Join(dir, "absent.sums")); code != config.ExitNoMatches || errOut == "" {
+		t.Errorf("unreadable checksum file: exit = %d, stderr %q", code, errOut)
+	}
+}
+
+func TestCheckMode_EscapedNames(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "two\nlines")
+	if err := os.WriteFile(name, []byte("abc"), 0644); err != nil {
+		t.Skipf("file names with newlines not supported: %v", err)
+	}
+	sums := `\ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad  ` + strings.ReplaceAll(name, "\n", `\n`) + "\n"
+
+	cfg, _, err := config.ParseArgs([]string{"--check"})
+	if err != nil {
+		t.Fatalf("ParseArgs() error = %v", err)
+	}
+	var out bytes.Buffer
+	streams := &console.Streams{Out: &out, Err: &out, In: strings.NewReader(sums)}
+	code := runCheckMode(context.Background(), cfg, streams, errors.NewErrorHandler(color.NewColorHandler()))
+	if want := `\` + strings.ReplaceAll(name, "\n", `\n`) + ": OK\n"; code != config.ExitSuccess || out.String() != want {
+		t.Errorf("exit = %d, output %q; want %q", code, out.String(), want)
+	}
+
+	// A backslash alone is enough for the name to be printed escaped, even
+	// when the checksum line did not escape it.
+	name = filepath.Join(dir, `back\slash`)
+	if err := os.WriteFile(name, []byte("abc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	streams.In = strings.NewReader(`ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad  ` + name + "\n")
+	code = runCheckMode(context.Background(), cfg, streams, errors.NewErrorHandler(color.NewColorHandler()))
+	if want := `\` + strings.ReplaceAll(name, `\`, `\\`) + ": OK\n"; code != config.ExitSuccess || out.String() != want {
+		t.Errorf("exit = %d, output %q; want %q", code, out.String(), want)
+	}
+}
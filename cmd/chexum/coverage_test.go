@@ -2,8 +2,15 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/Les-El/chexum/internal/color"
 	"github.com/Les-El/chexum/internal/config"
@@ -44,7 +51,7 @@ func TestPrepareFiles_Coverage(t *testing.T) {
 
 	t.Run("EmptyFilesAndNoHashes", func(t *testing.T) {
 		cfg := &config.Config{}
-		err := prepareFiles(cfg, errHandler, streams)
+		err := prepareFiles(context.Background(), cfg, errHandler, streams)
 		if err != nil {
 			t.Errorf("prepareFiles() error = %v", err)
 		}
@@ -56,7 +63,7 @@ func TestPrepareFiles_Coverage(t *testing.T) {
 		tmpFile.Close()
 
 		cfg := &config.Config{Files: []string{tmpFile.Name()}}
-		err := prepareFiles(cfg, errHandler, streams)
+		err := prepareFiles(context.Background(), cfg, errHandler, streams)
 		if err != nil {
 			t.Errorf("prepareFiles() error = %v", err)
 		}
@@ -67,11 +74,20 @@ func TestPrepareFiles_Coverage(t *testing.T) {
 
 	t.Run("NonExistentFile", func(t *testing.T) {
 		cfg := &config.Config{Files: []string{"non_existent_file_12345.txt"}}
-		err := prepareFiles(cfg, errHandler, streams)
+		err := prepareFiles(context.Background(), cfg, errHandler, streams)
 		if err == nil {
 			t.Error("Expected error for non-existent file")
 		}
 	})
+
+	t.Run("Interrupted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		cfg := &config.Config{Files: []string{t.TempDir()}, Recursive: true}
+		if err := prepareFiles(ctx, cfg, errHandler, streams); err != context.Canceled {
+			t.Errorf("prepareFiles() error = %v, want context.Canceled", err)
+		}
+	})
 }
 
 func TestExecuteMode_Coverage(t *testing.T) {
@@ -85,7 +101,7 @@ func TestExecuteMode_Coverage(t *testing.T) {
 			Files:  []string{"file1.txt", "file2.txt"},
 			Hashes: []string{"hash1"},
 		}
-		code := executeMode(cfg, colorHandler, streams, errHandler)
+		code := executeMode(context.Background(), cfg, colorHandler, streams, errHandler)
 		if code != config.ExitInvalidArgs {
 			t.Errorf("executeMode() = %d; want %d", code, config.ExitInvalidArgs)
 		}
@@ -96,7 +112,7 @@ func TestExecuteMode_Coverage(t *testing.T) {
 			Files:  []string{"-"},
 			Hashes: []string{"hash1"},
 		}
-		code := executeMode(cfg, colorHandler, streams, errHandler)
+		code := executeMode(context.Background(), cfg, colorHandler, streams, errHandler)
 		if code != config.ExitInvalidArgs {
 			t.Errorf("executeMode() = %d; want %d", code, config.ExitInvalidArgs)
 		}
@@ -104,7 +120,7 @@ func TestExecuteMode_Coverage(t *testing.T) {
 
 	t.Run("NoFilesNoHashes", func(t *testing.T) {
 		cfg := &config.Config{}
-		code := executeMode(cfg, colorHandler, streams, errHandler)
+		code := executeMode(context.Background(), cfg, colorHandler, streams, errHandler)
 		if code != config.ExitSuccess {
 			t.Errorf("executeMode() = %d; want %d", code, config.ExitSuccess)
 		}
@@ -126,7 +142,10 @@ func TestExpandStdinFiles_Coverage(t *testing.T) {
 	}()
 
 	files := []string{"-", "existing.txt"}
-	result := expandStdinFiles(files)
+	result, err := expandStdinFiles(files, os.Stdin, false)
+	if err != nil {
+		t.Fatalf("expandStdinFiles() error = %v", err)
+	}
 
 	expected := []string{"existing.txt", "file1.txt", "file2.txt"}
 	if len(result) != len(expected) {
@@ -139,6 +158,71 @@ func TestExpandStdinFiles_Coverage(t *testing.T) {
 	}
 }
 
+func TestReadPathList(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		null  bool
+		want  []string
+	}{
+		{"lines", "a.txt\n  b.txt  \r\n\n", false, []string{"a.txt", "b.txt"}},
+		{"nul", "new\nline\x00 spaced \x00\x00", true, []string{"new\nline", " spaced "}},
+		{"nul without -0", "a\x00b\nc\n", false, []string{"a\x00b", "c"}},
+		{"one name with -0", "one name\n", true, []string{"one name\n"}},
+		{"empty", "", true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readPathList(strings.NewReader(tt.input), tt.null)
+			if err != nil {
+				t.Fatalf("readPathList() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("readPathList() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandPathLists(t *testing.T) {
+	list := filepath.Join(t.TempDir(), "list")
+	os.WriteFile(list, []byte("from file\n"), 0644)
+
+	cfg := config.DefaultConfig()
+	cfg.Files = []string{"arg"}
+	cfg.StdinPaths = true
+	cfg.FilesFrom = list
+	got, err := expandPathLists(context.Background(), cfg, strings.NewReader("from stdin\n"))
+	if err != nil {
+		t.Fatalf("expandPathLists() error = %v", err)
+	}
+	if want := []string{"arg", "from stdin", "from file"}; !slices.Equal(got, want) {
+		t.Errorf("expandPathLists() = %q, want %q", got, want)
+	}
+
+	cfg.FilesFrom = list + ".missing"
+	if _, err := expandPathLists(context.Background(), cfg, strings.NewReader("")); !os.IsNotExist(err) {
+		t.Errorf("missing list: error = %v, want not exist", err)
+	}
+
+	// A failed read of stdin fails the list instead of truncating it.
+	cfg.FilesFrom = ""
+	readErr := io.ErrUnexpectedEOF
+	if _, err := expandPathLists(context.Background(), cfg, io.MultiReader(strings.NewReader("a\n"), iotest.ErrReader(readErr))); err == nil || !strings.Contains(err.Error(), readErr.Error()) {
+		t.Errorf("failed stdin read: error = %v, want %v", err, readErr)
+	}
+
+	// A list that never ends, like an idle pipe, does not outlast Ctrl-C.
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	cfg.FilesFrom = ""
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err := expandPathLists(ctx, cfg, pr); err != context.Canceled {
+		t.Errorf("interrupted list: error = %v, want context.Canceled", err)
+	}
+}
+
 func TestRunStandardHashingMode_InvalidAlgorithm(t *testing.T) {
 	colorHandler := color.NewColorHandler()
 	errHandler := errors.NewErrorHandler(colorHandler)
@@ -149,7 +233,7 @@ func TestRunStandardHashingMode_InvalidAlgorithm(t *testing.T) {
 		Algorithm: "invalid-alg",
 		Files:     []string{"file.txt"},
 	}
-	code := runStandardHashingMode(cfg, colorHandler, streams, errHandler)
+	code := runStandardHashingMode(context.Background(), cfg, colorHandler, streams, errHandler)
 	if code != config.ExitInvalidArgs {
 		t.Errorf("Expected ExitInvalidArgs, got %d", code)
 	}
@@ -232,7 +316,7 @@ func TestReportValidHash_Coverage(t *testing.T) {
 
 	t.Run("OneAlgorithm", func(t *testing.T) {
 		errBuf.Reset()
-		reportValidHash("abc", []string{"sha256"}, &config.Config{}, colorHandler, streams)
+		reportValidHash("abc", hash.DecodedDigest{Hex: "abc", Encoding: hash.EncodingHex, Algorithm: "sha256"}, &config.Config{}, colorHandler, streams)
 		if !bytes.Contains(errBuf.Bytes(), []byte("Algorithm: sha256")) {
 			t.Errorf("Expected algorithm in output, got %s", errBuf.String())
 		}
@@ -240,11 +324,24 @@ func TestReportValidHash_Coverage(t *testing.T) {
 
 	t.Run("MultipleAlgorithms", func(t *testing.T) {
 		errBuf.Reset()
-		reportValidHash("abc", []string{"md5", "sha1"}, &config.Config{}, colorHandler, streams)
-		if !bytes.Contains(errBuf.Bytes(), []byte("Possible algorithms: md5 or sha1")) {
+		reportValidHash("abc", hash.DecodedDigest{Hex: strings.Repeat("a", 64), Encoding: hash.EncodingHex}, &config.Config{}, colorHandler, streams)
+		if !bytes.Contains(errBuf.Bytes(), []byte("Possible algorithms: ")) {
 			t.Errorf("Expected possible algorithms in output, got %s", errBuf.String())
 		}
 	})
+
+	t.Run("EncodedDigest", func(t *testing.T) {
+		errBuf.Reset()
+		sri := "sha256-LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ="
+		digest, ok := hash.DecodeDigest(sri)
+		if !ok {
+			t.Fatalf("DecodeDigest(%q) failed", sri)
+		}
+		reportValidHash(sri, digest, &config.Config{}, colorHandler, streams)
+		if !bytes.Contains(errBuf.Bytes(), []byte("Encoding: sri (hex 2cf24dba")) {
+			t.Errorf("Expected encoding in output, got %s", errBuf.String())
+		}
+	})
 }
 
 func TestReportInvalidHash_Coverage(t *testing.T) {
@@ -259,25 +356,6 @@ func TestReportInvalidHash_Coverage(t *testing.T) {
 	}
 }
 
-func TestFormatSize_Coverage(t *testing.T) {
-	tests := []struct {
-		size int64
-		want string
-	}{
-		{500, "500 B"},
-		{1024, "1.0 KB"},
-		{1024 * 1024, "1.0 MB"},
-		{1024 * 1024 * 1024, "1.0 GB"},
-	}
-
-	for _, tt := range tests {
-		got := formatSize(tt.size)
-		if got != tt.want {
-			t.Errorf("formatSize(%d) = %q, want %q", tt.size, got, tt.want)
-		}
-	}
-}
-
 func TestRunDryRunMode_Coverage(t *testing.T) {
 	colorHandler := color.NewColorHandler()
 	colorHandler.SetEnabled(false)
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/Les-El/chexum/internal/color"
+	"github.com/Les-El/chexum/internal/config"
+	"github.com/Les-El/chexum/internal/console"
+	"github.com/Les-El/chexum/internal/errors"
+	"github.com/Les-El/chexum/internal/hash"
+	"github.com/Les-El/chexum/internal/progress"
+	"github.com/Les-El/chexum/internal/security"
+)
+
+// streamsDiscovery reports whether files can be hashed while discovery is
+// still walking the tree. Modes that need the whole list before they start
+// (dry runs, duplicate search, --only-changed, path lists and checking
+// bare hashes) discover everything first in prepareFiles.
+func streamsDiscovery(cfg *config.Config) bool {
+	if cfg.DryRun || cfg.Duplicates || cfg.StdinPaths || cfg.FilesFrom != "" || (cfg.OnlyChanged && cfg.Manifest != "") {
+		return false
+	}
+	return len(cfg.Files) > 0 || len(cfg.Hashes) == 0
+}
+
+// runStreamingMode is runStandardHashingMode for arguments that have not
+// been discovered yet: files are hashed as the walk finds them.
+func runStreamingMode(ctx context.Context, cfg *config.Config, colorHandler *color.Handler, streams *console.Streams, errHandler *errors.Handler) int {
+	roots := cfg.Files
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+	if err := checkRoots(roots); err != nil {
+		fmt.Fprintln(streams.Err, errHandler.FormatError(err))
+		return errors.DetermineDiscoveryExitCode(err)
+	}
+	return hashFiles(ctx, cfg, roots, colorHandler, streams, errHandler)
+}
+
+// checkRoots fails if a path argument does not exist or cannot be examined.
+// A mistyped argument is a usage error that ends the run before anything is
+// hashed; paths discovered beneath the arguments are merely skipped.
+func checkRoots(roots []string) error {
+	for _, root := range roots {
+		if root == "-" {
+			continue
+		}
+		if _, err := os.Lstat(root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reportDiscoveryErrors records paths discovery skipped in cfg, so every
+// mode can include them in its results, and prints them unless --quiet.
+func reportDiscoveryErrors(cfg *config.Config, skipped []error, bar *progress.Bar, streams *console.Streams, errHandler *errors.Handler) {
+	cfg.DiscoveryErrors = append(cfg.DiscoveryErrors, skipped...)
+	if cfg.Quiet {
+		return
+	}
+	for _, err := range skipped {
+		msg := errHandler.FormatError(err)
+		if bar != nil {
+			bar.WriteMessage(msg)
+		} else {
+			fmt.Fprintln(streams.Err, msg)
+		}
+	}
+}
+
+// reportSkipped lists, with --verbose, the symbolic links, loops and mount
+// points discovery passed over on purpose.
+func reportSkipped(cfg *config.Config, skipped []hash.SkippedPath, bar *progress.Bar, streams *console.Streams) {
+	if !cfg.Verbose || cfg.Quiet {
+		return
+	}
+	for _, s := range skipped {
+		msg := fmt.Sprintf("Discovery: skipped %s (%s)", security.SanitizeOutput(s.Path), s.Reason)
+		if bar != nil {
+			bar.WriteMessage(msg)
+		} else {
+			fmt.Fprintln(streams.Err, msg)
+		}
+	}
+}
+
+// discoveryFeed hands files from a hash.Discovery to the hashing workers
+// and keeps the list of everything found, for ordering and manifests.
+type discoveryFeed struct {
+	discovery *hash.Discovery
+	found     []hash.Found
+	done      chan struct{}
+}
+
+// startDiscovery starts walking roots with the configured criteria.
+func startDiscovery(ctx context.Context, cfg *config.Config, roots []string) *discoveryFeed {
+	return &discoveryFeed{
+		discovery: hash.Discover(ctx, roots, discoveryOptions(cfg)),
+		done:      make(chan struct{}),
+	}
+}
+
+// forward returns the files to hash as they are discovered. The stdin
+// marker is recorded but not forwarded; it is hashed separately. Once the
+// walk completes, bar (if any) learns the total size.
+func (f *discoveryFeed) forward(ctx context.Context, bar *progress.Bar) <-chan hash.Found {
+	paths := make(chan hash.Found)
+	go func() {
+		defer close(f.done)
+		defer close(paths)
+		var total int64
+		for found := range f.discovery.Files {
+			f.found = append(f.found, found)
+			total += found.Size
+			if found.Path == "-" {
+				continue
+			}
+			select {
+			case paths <- found:
+			case <-ctx.Done():
+				// Keep draining: the walk shares ctx and ends shortly.
+			}
+		}
+		if bar != nil {
+			bar.SetTotal(total)
+		}
+	}()
+	return paths
+}
+
+// files waits for the walk to end and returns what it found, in the order
+// hash.DiscoverFiles would have, along with the paths it had to skip.
+func (f *discoveryFeed) files() ([]string, []error) {
+	<-f.done
+	hash.SortFound(f.found)
+	files := make([]string, len(f.found))
+	for i, found := range f.found {
+		files[i] = found.Path
+	}
+	return files, f.discovery.Errors()
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Les-El/chexum/internal/color"
+	"github.com/Les-El/chexum/internal/config"
+	"github.com/Les-El/chexum/internal/console"
+	"github.com/Les-El/chexum/internal/errors"
+	"github.com/Les-El/chexum/internal/hash"
+)
+
+func TestStreamsDiscovery(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{"directory", []string{"-r", "dir"}, true},
+		{"no arguments", []string{}, true},
+		{"dry run", []string{"--dry-run", "dir"}, false},
+		{"duplicates", []string{"--duplicates", "dir"}, false},
+		{"incremental", []string{"--only-changed", "--manifest", "m.json", "dir"}, false},
+		{"paths on stdin", []string{"--stdin-paths"}, false},
+		{"hashes only", []string{"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, _, err := config.ParseArgs(tt.args)
+			if err != nil {
+				t.Fatalf("ParseArgs(%v) error = %v", tt.args, err)
+			}
+			if got := streamsDiscovery(cfg); got != tt.want {
+				t.Errorf("streamsDiscovery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscoveryOptions_IgnoreFiles(t *testing.T) {
+	tests := []struct {
+		name            string
+		args            []string
+		wantIgnoreFiles bool
+		wantGitIgnore   bool
+	}{
+		{"default", []string{}, true, true},
+		{"no gitignore", []string{"--no-gitignore"}, true, false},
+		{"deprecated gitignore", []string{"--gitignore"}, true, true},
+		{"deprecated gitignore=false", []string{"--gitignore=false"}, true, false},
+		{"last spelling wins", []string{"--no-gitignore", "--gitignore"}, true, true},
+		{"no ignore", []string{"--no-ignore"}, false, false},
+		{"no ignore wins", []string{"--gitignore", "--no-ignore"}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, _, err := config.ParseArgs(tt.args)
+			if err != nil {
+				t.Fatalf("ParseArgs(%v) error = %v", tt.args, err)
+			}
+			opts := discoveryOptions(cfg)
+			if opts.IgnoreFiles != tt.wantIgnoreFiles || opts.GitIgnore != tt.wantGitIgnore {
+				t.Errorf("IgnoreFiles, GitIgnore = %v, %v, want %v, %v", opts.IgnoreFiles, opts.GitIgnore, tt.wantIgnoreFiles, tt.wantGitIgnore)
+			}
+		})
+	}
+}
+
+func TestDiscoveryOptions_Traversal(t *testing.T) {
+	cfg, _, err := config.ParseArgs([]string{"--max-depth", "2", "--xdev", "--follow-symlinks"})
+	if err != nil {
+		t.Fatalf("ParseArgs() error = %v", err)
+	}
+	opts := discoveryOptions(cfg)
+	if !opts.Recursive || opts.MaxDepth != 2 || !opts.OneFileSystem || !opts.FollowSymlinks {
+		t.Errorf("discoveryOptions() = %+v, want recursion to depth 2 on one file system, following links", opts)
+	}
+}
+
+func TestReportSkipped(t *testing.T) {
+	skipped := []hash.SkippedPath{{Path: "dir/link", Reason: hash.SkipSymlink}}
+	for _, verbose := range []bool{false, true} {
+		cfg := config.DefaultConfig()
+		cfg.Verbose = verbose
+		var buf bytes.Buffer
+		reportSkipped(cfg, skipped, nil, &console.Streams{Out: &buf, Err: &buf})
+		want := ""
+		if verbose {
+			want = "Discovery: skipped dir/link (symbolic link not followed)\n"
+		}
+		if buf.String() != want {
+			t.Errorf("verbose %v: output %q, want %q", verbose, buf.String(), want)
+		}
+	}
+}
+
+func TestRunStreamingMode(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"b", "a/c", "a"} {
+		os.MkdirAll(filepath.Join(dir, sub), 0755)
+		os.WriteFile(filepath.Join(dir, sub, "f.txt"), []byte(sub), 0644)
+	}
+
+	run := func(args ...string) (int, string, *config.Config) {
+		t.Helper()
+		cfg, _, err := config.ParseArgs(args)
+		if err != nil {
+			t.Fatalf("ParseArgs() error = %v", err)
+		}
+		var buf bytes.Buffer
+		streams := &console.Streams{Out: &buf, Err: &buf}
+		colorHandler := color.NewColorHandler()
+		errHandler := errors.NewErrorHandler(colorHandler)
+		code := runStreamingMode(context.Background(), cfg, colorHandler, streams, errHandler)
+		return code, buf.String(), cfg
+	}
+
+	code, out, cfg := run("-r", "--no-cache", "--plain", dir)
+	if code != config.ExitSuccess {
+		t.Fatalf("exit = %d, output %q", code, out)
+	}
+	want := []string{
+		filepath.Join(dir, "a", "c", "f.txt"),
+		filepath.Join(dir, "a", "f.txt"),
+		filepath.Join(dir, "b", "f.txt"),
+	}
+	if strings.Join(cfg.Files, "\n") != strings.Join(want, "\n") {
+		t.Errorf("cfg.Files = %v, want %v", cfg.Files, want)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("output %q, want %d lines", out, len(want))
+	}
+	for i, line := range lines {
+		if !strings.Contains(line, want[i]) {
+			t.Errorf("line %d = %q, want %s", i, line, want[i])
+		}
+	}
+
+	// ParseArgs treats a missing path as a hash to look up, so build the
+	// configuration by hand as the other modes' tests do.
+	missing := config.DefaultConfig()
+	missing.Files = []string{filepath.Join(dir, "missing")}
+	var buf bytes.Buffer
+	colorHandler := color.NewColorHandler()
+	streams := &console.Streams{Out: &buf, Err: &buf}
+	if code := runStreamingMode(context.Background(), missing, colorHandler, streams, errors.NewErrorHandler(colorHandler)); code != config.ExitFileNotFound {
+		t.Errorf("missing argument: exit = %d, output %q", code, buf.String())
+	}
+
+	empty := filepath.Join(dir, "empty")
+	os.Mkdir(empty, 0755)
+	if code, out, _ := run("-r", empty); code != config.ExitSuccess || out != "" {
+		t.Errorf("empty directory: exit = %d, output %q", code, out)
+	}
+
+	// An unreadable subdirectory is reported and skipped; the rest is hashed.
+	if os.Geteuid() == 0 {
+		t.Log("skipping unreadable directory check: running as root")
+		return
+	}
+	locked := filepath.Join(dir, "b")
+	os.Chmod(locked, 0)
+	defer os.Chmod(locked, 0755)
+	code, out, cfg = run("-r", "--no-cache", "--plain", dir)
+	if code != config.ExitPartialFailure {
+		t.Errorf("unreadable directory: exit = %d, want %d", code, config.ExitPartialFailure)
+	}
+	if len(cfg.Files) != 2 || len(cfg.DiscoveryErrors) != 1 {
+		t.Errorf("unreadable directory: files %v, errors %v", cfg.Files, cfg.DiscoveryErrors)
+	}
+	if !strings.Contains(out, want[0]) {
+		t.Errorf("unreadable directory: readable files missing from %q", out)
+	}
+}
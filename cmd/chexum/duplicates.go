@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Les-El/chexum/internal/config"
+	"github.com/Les-El/chexum/internal/console"
+	"github.com/Les-El/chexum/internal/errors"
+	"github.com/Les-El/chexum/internal/hash"
+	"github.com/Les-El/chexum/internal/output"
+	"github.com/Les-El/chexum/internal/progress"
+)
+
+// runDuplicatesMode reports groups of identical files (--duplicates).
+// Unlike runStandardHashingMode it only fully hashes files that still
+// collide after the size and partial-hash passes; see hash.FindDuplicates.
+func runDuplicatesMode(ctx context.Context, cfg *config.Config, streams *console.Streams, errHandler *errors.Handler) int {
+	computer, err := newComputer(cfg, cfg.AlgorithmList()...)
+	if err != nil {
+		fmt.Fprintln(streams.Err, errHandler.FormatError(err))
+		return config.ExitInvalidArgs
+	}
+
+	var bar *progress.Bar
+	if !cfg.Quiet {
+		bar = progress.NewBar(&progress.Options{
+			Total:       int64(len(cfg.Files)),
+			Description: "Finding duplicates...",
+			Writer:      streams.Err,
+		})
+	}
+	workers, finish := prepareComputer(cfg, computer, streams)
+	opts := hash.DuplicateOptions{Workers: workers}
+	if bar != nil {
+		opts.Progress = func(n int) { bar.Add(int64(n)) }
+	}
+
+	results, stats := computer.FindDuplicates(ctx, cfg.Files, opts)
+	if bar != nil {
+		bar.Finish()
+	}
+	finish()
+	results.Unknowns = cfg.Unknowns
+
+	if !cfg.Quiet {
+		for _, err := range results.Errors {
+			fmt.Fprintln(streams.Err, errHandler.FormatError(err))
+		}
+		if results.Incomplete {
+			fmt.Fprintln(streams.Err, "Interrupted: results are incomplete; some duplicates may be missing")
+		}
+		if cfg.Verbose {
+			fmt.Fprintf(streams.Err, "Duplicates: %d files, %d share a size, %d share a partial hash; read %s\n",
+				stats.Files, stats.SizeMatched, stats.PartialMatched, output.FormatBytes(stats.BytesRead))
+		}
+		if len(results.Matches) == 0 {
+			fmt.Fprintf(streams.Err, "No duplicates found among %d files\n", stats.Files)
+		}
+	}
+
+	// Paths skipped during discovery were reported when they were found.
+	results.Errors = append(results.Errors, cfg.DiscoveryErrors...)
+
+	outputResults(results, cfg, streams)
+	return errors.DetermineExitCode(cfg, results)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Les-El/chexum/internal/color"
+	"github.com/Les-El/chexum/internal/config"
+	"github.com/Les-El/chexum/internal/console"
+	"github.com/Les-El/chexum/internal/errors"
+)
+
+func TestDuplicatesMode(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("same content"), 0644)
+	os.WriteFile(filepath.Join(dir, "b.txt"), []byte("same content"), 0644)
+	os.WriteFile(filepath.Join(dir, "c.txt"), []byte("diff content"), 0644)
+	os.WriteFile(filepath.Join(dir, "d.txt"), []byte("unique"), 0644)
+
+	run := func(args ...string) (int, string) {
+		t.Helper()
+		cfg, _, err := config.ParseArgs(args)
+		if err != nil {
+			t.Fatalf("ParseArgs() error = %v", err)
+		}
+		var buf bytes.Buffer
+		streams := &console.Streams{Out: &buf, Err: &buf}
+		colorHandler := color.NewColorHandler()
+		errHandler := errors.NewErrorHandler(colorHandler)
+		if err := prepareFiles(context.Background(), cfg, errHandler, streams); err != nil {
+			t.Fatalf("prepareFiles() error = %v", err)
+		}
+		return executeMode(context.Background(), cfg, colorHandler, streams, errHandler), buf.String()
+	}
+
+	code, out := run("--duplicates", "--any-match", dir)
+	if code != config.ExitSuccess {
+		t.Fatalf("exit = %d, output %q", code, out)
+	}
+	if !strings.Contains(out, "a.txt") || !strings.Contains(out, "b.txt") || !strings.Contains(out, "12 B wasted") {
+		t.Errorf("expected a.txt and b.txt grouped with wasted space, got %q", out)
+	}
+	if strings.Contains(out, "c.txt") || strings.Contains(out, "d.txt") {
+		t.Errorf("files without a duplicate should not be listed, got %q", out)
+	}
+
+	os.Remove(filepath.Join(dir, "b.txt"))
+	if code, out := run("--duplicates", "--any-match", dir); code != config.ExitNoMatches || !strings.Contains(out, "No duplicates found among 3 files") {
+		t.Errorf("exit = %d, output %q", code, out)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Les-El/chexum/internal/color"
+	"github.com/Les-El/chexum/internal/config"
+	"github.com/Les-El/chexum/internal/console"
+	"github.com/Les-El/chexum/internal/errors"
+)
+
+func TestEncodingOutput(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "hello.txt")
+	os.WriteFile(file, []byte("hello"), 0644)
+	manifestPath := filepath.Join(dir, "out.json")
+	sri := "sha256-LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ="
+
+	run := func(args ...string) (int, string) {
+		t.Helper()
+		cfg, _, err := config.ParseArgs(args)
+		if err != nil {
+			t.Fatalf("ParseArgs() error = %v", err)
+		}
+		var buf bytes.Buffer
+		streams := &console.Streams{Out: &buf, Err: &buf}
+		colorHandler := color.NewColorHandler()
+		errHandler := errors.NewErrorHandler(colorHandler)
+		if err := prepareFiles(context.Background(), cfg, errHandler, streams); err != nil {
+			t.Fatalf("prepareFiles() error = %v", err)
+		}
+		return executeMode(context.Background(), cfg, colorHandler, streams, errHandler), buf.String()
+	}
+
+	t.Run("SRIOutputAndManifest", func(t *testing.T) {
+		code, out := run("--encoding", "sri", "--output-manifest", manifestPath, file)
+		if code != config.ExitSuccess || !strings.Contains(out, sri) {
+			t.Fatalf("exit = %d, output %q", code, out)
+		}
+		data, err := os.ReadFile(manifestPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), sri) || !strings.Contains(string(data), `"encoding": "sri"`) {
+			t.Errorf("manifest should hold SRI digests: %s", data)
+		}
+	})
+
+	t.Run("EncodedReferenceHash", func(t *testing.T) {
+		code, out := run("--any-match", file, "QmRN6wdp1S2A5EtjW9A3M1vKSBuQQGcgvuhoMUoEz4iiT5")
+		if code != config.ExitSuccess {
+			t.Errorf("multihash reference should match its hex digest, exit = %d, output %q", code, out)
+		}
+	})
+}
@@ -3,6 +3,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -190,7 +191,7 @@ func TestProperty_DefaultBehavior(t *testing.T) {
 	}
 
 	// We simulate the discovery logic from main.go
-	discovered, err := hash.DiscoverFiles(nil, opts)
+	discovered, err := hash.DiscoverFiles(context.Background(), nil, opts)
 	if err != nil {
 		t.Fatalf("discovery failed: %v", err)
 	}
@@ -212,13 +213,13 @@ func TestProperty_Idempotence(t *testing.T) {
 	computer, _ := hash.NewComputer("sha256")
 
 	// First run
-	res1, err1 := computer.ComputeFile(path)
+	res1, err1 := computer.ComputeFile(context.Background(), path)
 	if err1 != nil {
 		t.Fatalf("first run failed: %v", err1)
 	}
 
 	// Second run
-	res2, err2 := computer.ComputeFile(path)
+	res2, err2 := computer.ComputeFile(context.Background(), path)
 	if err2 != nil {
 		t.Fatalf("second run failed: %v", err2)
 	}
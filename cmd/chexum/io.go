@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+
+	"github.com/Les-El/chexum/internal/config"
+	"github.com/Les-El/chexum/internal/console"
+	"github.com/Les-El/chexum/internal/governor"
+	"github.com/Les-El/chexum/internal/hash"
+	"github.com/Les-El/chexum/internal/output"
+	"github.com/Les-El/chexum/internal/security"
+)
+
+// applyIOLimits sizes the computer's per-device worker pools from the [io]
+// config table. Each key must be a mount point; a path that is not one
+// right now (say, an unplugged drive's mount directory) is skipped so its
+// limit cannot throttle the filesystem underneath. Devices without a limit
+// keep the --jobs worker count.
+func applyIOLimits(cfg *config.Config, computer *hash.Computer, streams *console.Streams) {
+	if len(cfg.IOLimits) == 0 {
+		return
+	}
+	mounts := make([]string, 0, len(cfg.IOLimits))
+	for mount := range cfg.IOLimits {
+		mounts = append(mounts, mount)
+	}
+	sort.Strings(mounts)
+
+	limits := make(map[uint64]int, len(mounts))
+	for _, mount := range mounts {
+		device, ok := hash.MountDevice(mount)
+		if !ok {
+			if cfg.Verbose && !cfg.Quiet {
+				fmt.Fprintf(streams.Err, "I/O: ignoring limit for %s: not a mount point\n", security.SanitizeOutput(mount))
+			}
+			continue
+		}
+		limits[device] = cfg.IOLimits[mount]
+		if cfg.Verbose && !cfg.Quiet {
+			fmt.Fprintf(streams.Err, "I/O: %s reads at most %d files at once\n", security.SanitizeOutput(mount), cfg.IOLimits[mount])
+		}
+	}
+	if len(limits) > 0 {
+		computer.SetDeviceWorkers(func(device uint64) int { return limits[device] })
+	}
+}
+
+// prepareComputer applies the settings shared by every mode that hashes
+// files from disk: per-device pools, the --max-rate cap, the read buffer
+// size, adaptive worker tuning with --jobs 0 and the hash cache. It
+// returns the worker count the device pools without a limit share, and a
+// function that must be called once hashing is done; it reports the tuning
+// outcome and writes out the cache.
+func prepareComputer(cfg *config.Config, computer *hash.Computer, streams *console.Streams) (int, func()) {
+	applyIOLimits(cfg, computer, streams)
+	computer.SetMaxRate(cfg.MaxRate)
+	if err := computer.SetBufferSize(int(cfg.BufferSize)); err != nil {
+		fmt.Fprintf(streams.Err, "Warning: %v; using the default\n", err)
+	}
+	closeCache := attachCache(cfg, computer, streams)
+
+	workers := calculateWorkers(cfg.Jobs, runtime.NumCPU())
+	if !cfg.AdaptiveJobs {
+		return workers, closeCache
+	}
+	adaptive := newAdaptive(workers)
+	computer.SetAdaptive(adaptive)
+	return workers, func() {
+		if cfg.Verbose && !cfg.Quiet {
+			fmt.Fprintln(streams.Err, describeAdaptive(adaptive.Stats()))
+		}
+		closeCache()
+	}
+}
+
+// newAdaptive returns the controller used with --jobs 0. The Neighborhood
+// Policy's worker count becomes the ceiling rather than a fixed choice, and
+// the controller yields whenever other work keeps every CPU busy or memory
+// runs short.
+func newAdaptive(ceiling int) *hash.Adaptive {
+	return hash.NewAdaptive(hash.AdaptiveOptions{
+		Max:               ceiling,
+		Load:              governor.LoadAverage,
+		MaxLoad:           float64(runtime.NumCPU()),
+		MemoryPressure:    governor.MemoryPressure,
+		MaxMemoryPressure: 0.10,
+	})
+}
+
+// describeAdaptive summarises the controller's choices for --verbose.
+func describeAdaptive(stats hash.AdaptiveStats) string {
+	desc := fmt.Sprintf("Workers: adaptive, %d active at the end (peak %d, ceiling %d)",
+		stats.Active, stats.Peak, stats.Max)
+	if stats.BestRate > 0 {
+		desc += fmt.Sprintf(", best %s/s", output.FormatBytes(int64(stats.BestRate)))
+	}
+	if stats.Backoffs > 0 {
+		desc += fmt.Sprintf(", backed off %d times for load or memory pressure", stats.Backoffs)
+	}
+	return desc
+}
+
+// applyGovernor lowers chexum's own CPU and I/O priority as configured and,
+// with --verbose, reports every limit in effect. A limit that cannot be
+// applied is a warning: the run is slower to yield, not wrong.
+func applyGovernor(cfg *config.Config, streams *console.Streams) {
+	limits := cfg.ResourceLimits()
+	if err := governor.Apply(limits); err != nil && !cfg.Quiet {
+		fmt.Fprintf(streams.Err, "Warning: %v\n", err)
+	}
+	if cfg.Verbose && !cfg.Quiet && limits != (governor.Limits{}) {
+		fmt.Fprintf(streams.Err, "Limits: %s\n", governor.Describe(limits))
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Les-El/chexum/internal/config"
+	"github.com/Les-El/chexum/internal/console"
+	"github.com/Les-El/chexum/internal/hash"
+)
+
+func TestApplyIOLimits(t *testing.T) {
+	if _, ok := hash.DeviceOf("/"); !ok {
+		t.Skip("device numbers are not available on this platform")
+	}
+	cfg := config.DefaultConfig()
+	cfg.Verbose = true
+	cfg.IOLimits = map[string]int{"/": 2, t.TempDir(): 1}
+
+	var buf bytes.Buffer
+	computer, _ := hash.NewComputer(hash.AlgorithmSHA256)
+	applyIOLimits(cfg, computer, &console.Streams{Out: &buf, Err: &buf})
+
+	out := buf.String()
+	if !strings.Contains(out, "I/O: / reads at most 2 files at once") {
+		t.Errorf("missing limit for /, got %q", out)
+	}
+	if !strings.Contains(out, "not a mount point") {
+		t.Errorf("a plain directory should be skipped, got %q", out)
+	}
+}
+
+func TestPrepareComputer_AdaptiveOptIn(t *testing.T) {
+	for _, args := range [][]string{{"-v"}, {"-v", "--jobs", "0"}} {
+		cfg, _, err := config.ParseArgs(args)
+		if err != nil {
+			t.Fatalf("ParseArgs(%v) error = %v", args, err)
+		}
+		var buf bytes.Buffer
+		computer, _ := hash.NewComputer(hash.AlgorithmSHA256)
+		_, finish := prepareComputer(cfg, computer, &console.Streams{Out: &buf, Err: &buf})
+		finish()
+		if got, want := strings.Contains(buf.String(), "Workers: adaptive"), cfg.AdaptiveJobs; got != want {
+			t.Errorf("%v: adaptive tuning reported = %v, want %v; output %q", args, got, want, buf.String())
+		}
+	}
+}
+
+func TestDescribeAdaptive(t *testing.T) {
+	tests := []struct {
+		stats hash.AdaptiveStats
+		want  string
+	}{
+		{hash.AdaptiveStats{Active: 2, Peak: 2, Max: 6}, "Workers: adaptive, 2 active at the end (peak 2, ceiling 6)"},
+		{hash.AdaptiveStats{Active: 3, Peak: 5, Max: 6, BestRate: 200 * 1024 * 1024, Backoffs: 2},
+			"Workers: adaptive, 3 active at the end (peak 5, ceiling 6), best 200.0 MB/s, backed off 2 times for load or memory pressure"},
+	}
+	for _, tt := range tests {
+		if got := describeAdaptive(tt.stats); got != tt.want {
+			t.Errorf("describeAdaptive() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Les-El/chexum/internal/color"
+	"github.com/Les-El/chexum/internal/config"
+	"github.com/Les-El/chexum/internal/console"
+	"github.com/Les-El/chexum/internal/errors"
+)
+
+func TestKeyedHashing(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.txt")
+	os.WriteFile(file, []byte("content1"), 0644)
+	keyFile := filepath.Join(dir, "key")
+	os.WriteFile(keyFile, []byte("do-not-leak\n"), 0600)
+	manifestPath := filepath.Join(dir, "out.json")
+
+	cfg, _, err := config.ParseArgs([]string{"-a", "hmac-sha256", "--key-file", keyFile, "--json", "--output-manifest", manifestPath, file})
+	if err != nil {
+		t.Fatalf("ParseArgs() error = %v", err)
+	}
+	var buf bytes.Buffer
+	streams := &console.Streams{Out: &buf, Err: &buf}
+	colorHandler := color.NewColorHandler()
+	errHandler := errors.NewErrorHandler(colorHandler)
+	if err := prepareFiles(context.Background(), cfg, errHandler, streams); err != nil {
+		t.Fatalf("prepareFiles() error = %v", err)
+	}
+	if code := executeMode(context.Background(), cfg, colorHandler, streams, errHandler); code != config.ExitSuccess {
+		t.Fatalf("exit = %d, output %q", code, buf.String())
+	}
+
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, text := range map[string]string{"output": buf.String(), "manifest": string(data)} {
+		if strings.Contains(text, "do-not-leak") || strings.Contains(text, keyFile) {
+			t.Errorf("%s reveals the key or its source: %s", name, text)
+		}
+	}
+	if !strings.Contains(string(data), `"keyed": true`) || !strings.Contains(string(data), `"algorithm": "hmac-sha256"`) {
+		t.Errorf("manifest should record that a keyed algorithm was used: %s", data)
+	}
+}
@@ -13,10 +13,11 @@
 package main
 
 import (
-	"bufio"
+	"context"
 	"fmt"
+	"io"
 	"os"
-	"runtime"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -70,6 +71,13 @@ func run() int {
 	if cfg.Test {
 		return diagnostics.RunDiagnostics(cfg, streams)
 	}
+
+	// From here on every hashing and walking call is given ctx, so the first
+	// Ctrl-C cancels it and the run stops cleanly with what it finished; a
+	// second one falls back to an immediate exit. Until now (diagnostics
+	// included) nothing watches a context, and the first Ctrl-C still exits.
+	ctx := sigHandler.Context(context.Background())
+
 	if len(warnings) > 0 {
 		fmt.Fprint(streams.Err, conflict.FormatAllWarnings(warnings))
 	}
@@ -79,12 +87,36 @@ func run() int {
 	}
 
 	validateFlagsUsage(cfg)
+	applyGovernor(cfg, streams)
+
+	// The arguments of --check are checksum files, not files to hash.
+	if cfg.Check {
+		return runCheckMode(ctx, cfg, streams, errHandler)
+	}
+
+	if cfg.Passthrough {
+		return runPassthroughMode(ctx, cfg, colorHandler, streams, errHandler)
+	}
+
+	// Tree mode needs the directory arguments themselves, not the flattened
+	// file list that prepareFiles produces.
+	if cfg.Tree {
+		return runTreeMode(ctx, cfg, streams, errHandler)
+	}
 
-	if err := prepareFiles(cfg, errHandler, streams); err != nil {
+	// The common case hashes files while the tree is still being walked.
+	if streamsDiscovery(cfg) {
+		return runStreamingMode(ctx, cfg, colorHandler, streams, errHandler)
+	}
+
+	if err := prepareFiles(ctx, cfg, errHandler, streams); err != nil {
+		if ctx.Err() != nil {
+			return config.ExitInterrupted
+		}
 		return errors.DetermineDiscoveryExitCode(err)
 	}
 
-	return executeMode(cfg, colorHandler, streams, errHandler)
+	return executeMode(ctx, cfg, colorHandler, streams, errHandler)
 }
 
 func handleBasicFlags(cfg *config.Config, streams *console.Streams) (int, bool) {
@@ -130,27 +162,41 @@ func performProactiveCleanup(cfg *config.Config, cleanupMgr *checkpoint.CleanupM
 	}
 }
 
-func prepareFiles(cfg *config.Config, errHandler *errors.Handler, streams *console.Streams) error {
-	if cfg.HasStdinMarker() {
-		cfg.Files = expandStdinFiles(cfg.Files)
-	}
-
-	if len(cfg.Files) > 0 || len(cfg.Hashes) == 0 {
-		discOpts := hash.DiscoveryOptions{
-			Recursive:      cfg.Recursive,
-			Hidden:         cfg.Hidden,
-			Include:        cfg.Include,
-			Exclude:        cfg.Exclude,
-			MinSize:        cfg.MinSize,
-			MaxSize:        cfg.MaxSize,
-			ModifiedAfter:  cfg.ModifiedAfter,
-			ModifiedBefore: cfg.ModifiedBefore,
+// prepareFiles replaces cfg.Files with every file to hash: the listed
+// paths, walked with the discovery criteria, then narrowed to the files
+// changed since the manifest with --only-changed. It returns ctx.Err()
+// if it is interrupted before the list is complete.
+func prepareFiles(ctx context.Context, cfg *config.Config, errHandler *errors.Handler, streams *console.Streams) error {
+	listed := cfg.StdinPaths || cfg.FilesFrom != ""
+	if listed {
+		files, err := expandPathLists(ctx, cfg, streams.Input())
+		if ctx.Err() != nil {
+			reportInterruptedDiscovery(cfg, streams)
+			return ctx.Err()
 		}
-		discovered, err := hash.DiscoverFiles(cfg.Files, discOpts)
 		if err != nil {
 			fmt.Fprintln(streams.Err, errHandler.FormatError(err))
 			return err
 		}
+		cfg.Files = files
+	}
+
+	// An empty path list means "nothing to do", not "the current directory".
+	if len(cfg.Files) > 0 || (len(cfg.Hashes) == 0 && !listed) {
+		if err := checkRoots(cfg.Files); err != nil {
+			fmt.Fprintln(streams.Err, errHandler.FormatError(err))
+			return err
+		}
+		feed := startDiscovery(ctx, cfg, cfg.Files)
+		for range feed.forward(ctx, nil) {
+		}
+		discovered, unreadable := feed.files()
+		if ctx.Err() != nil {
+			reportInterruptedDiscovery(cfg, streams)
+			return ctx.Err()
+		}
+		reportDiscoveryErrors(cfg, unreadable, nil, streams, errHandler)
+		reportSkipped(cfg, feed.discovery.Skipped(), nil, streams)
 		cfg.Files = discovered
 	}
 
@@ -175,38 +221,116 @@ func prepareFiles(cfg *config.Config, errHandler *errors.Handler, streams *conso
 	return nil
 }
 
-func executeMode(cfg *config.Config, colorHandler *color.Handler, streams *console.Streams, errHandler *errors.Handler) int {
-	// Edge case validation
-	if len(cfg.Hashes) > 0 {
-		if cfg.HasStdinMarker() {
-			fmt.Fprintln(streams.Err, errHandler.FormatError(fmt.Errorf("Cannot use stdin input with hash comparison")))
-			return config.ExitInvalidArgs
-		}
+// reportInterruptedDiscovery tells the user that Ctrl-C ended the run
+// before anything was hashed.
+func reportInterruptedDiscovery(cfg *config.Config, streams *console.Streams) {
+	if !cfg.Quiet {
+		fmt.Fprintln(streams.Err, "Interrupted: the file list was not complete, so nothing was hashed")
+	}
+}
+
+// hashStdin hashes the data piped to chexum as a single <stdin> entry.
+func hashStdin(ctx context.Context, computer *hash.Computer, streams *console.Streams) hash.Entry {
+	entry, err := computer.ComputeStream(ctx, hash.StdinEntryName, streams.Input())
+	if err != nil {
+		return hash.Entry{Original: hash.StdinEntryName, Error: fmt.Errorf("reading stdin: %w", err)}
+	}
+	return *entry
+}
+
+// newComputer builds a hash.Computer for algorithms, loading the secret from
+// --key-file/--key-env when any of them is keyed. The key lives only inside
+// the Computer; it is never copied into output, logs or manifests.
+func newComputer(cfg *config.Config, algorithms ...string) (*hash.Computer, error) {
+	key, err := config.LoadKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return hash.NewComputer(algorithms...)
+	}
+	return hash.NewKeyedComputer(key, algorithms...)
+}
+
+// discoveryOptions builds the file discovery criteria from the configuration.
+func discoveryOptions(cfg *config.Config) hash.DiscoveryOptions {
+	return hash.DiscoveryOptions{
+		Recursive:      cfg.Recursive || cfg.MaxDepth > 0,
+		Hidden:         cfg.Hidden,
+		IgnoreFiles:    !cfg.NoIgnore,
+		GitIgnore:      !cfg.NoGitIgnore && !cfg.NoIgnore,
+		Include:        cfg.Include,
+		Exclude:        cfg.Exclude,
+		MinSize:        cfg.MinSize,
+		MaxSize:        cfg.MaxSize,
+		ModifiedAfter:  cfg.ModifiedAfter,
+		ModifiedBefore: cfg.ModifiedBefore,
+		PathRegex:      cfg.PathRegex,
+		Types:          cfg.Types,
+		Owners:         cfg.Owners,
+		Groups:         cfg.Groups,
+		Perm:           cfg.Perm,
+		ChangedAfter:   cfg.ChangedAfter,
+		ChangedBefore:  cfg.ChangedBefore,
+		AccessedAfter:  cfg.AccessedAfter,
+		AccessedBefore: cfg.AccessedBefore,
+		MaxDepth:       cfg.MaxDepth,
+		OneFileSystem:  cfg.OneFileSystem,
+		FollowSymlinks: cfg.FollowSymlinks,
 	}
+}
 
+func executeMode(ctx context.Context, cfg *config.Config, colorHandler *color.Handler, streams *console.Streams, errHandler *errors.Handler) int {
 	if len(cfg.Files) == 0 && len(cfg.Hashes) > 0 {
 		return runHashValidationMode(cfg, colorHandler, streams)
 	}
 	if cfg.DryRun {
 		return runDryRunMode(cfg, colorHandler, streams)
 	}
+	if cfg.Duplicates && len(cfg.Files) > 0 {
+		return runDuplicatesMode(ctx, cfg, streams, errHandler)
+	}
 	// Note: 1 file + 1 hash is now handled by runStandardHashingMode for consistency
 	if len(cfg.Files) > 0 {
-		return runStandardHashingMode(cfg, colorHandler, streams, errHandler)
+		return runStandardHashingMode(ctx, cfg, colorHandler, streams, errHandler)
 	}
 
 	return config.ExitSuccess
 }
 
 // runStandardHashingMode processes multiple files, computing hashes and formatting output.
-func runStandardHashingMode(cfg *config.Config, colorHandler *color.Handler, streams *console.Streams, errHandler *errors.Handler) int {
-	computer, err := hash.NewComputer(cfg.Algorithm)
+func runStandardHashingMode(ctx context.Context, cfg *config.Config, colorHandler *color.Handler, streams *console.Streams, errHandler *errors.Handler) int {
+	return hashFiles(ctx, cfg, nil, colorHandler, streams, errHandler)
+}
+
+// hashFiles hashes cfg.Files or, given roots, the files discovered under
+// them as the walk finds them, and reports the results. After a streaming
+// walk cfg.Files holds the discovered files, as prepareFiles would have.
+func hashFiles(ctx context.Context, cfg *config.Config, roots []string, colorHandler *color.Handler, streams *console.Streams, errHandler *errors.Handler) int {
+	computer, err := newComputer(cfg, cfg.AlgorithmList()...)
 	if err != nil {
 		fmt.Fprintln(streams.Err, errHandler.FormatError(err))
 		return config.ExitInvalidArgs
 	}
 
-	results := executeHashing(computer, cfg, streams, errHandler)
+	if cfg.Archives {
+		computer.EnableArchives(archiveLimits(cfg))
+	}
+	workers, finish := prepareComputer(cfg, computer, streams)
+
+	results := executeHashing(ctx, computer, cfg, roots, workers, streams, errHandler)
+	finish()
+	if roots != nil && len(cfg.Files) == 0 && len(cfg.DiscoveryErrors) == 0 && !results.Incomplete {
+		// Nothing qualified: finish as executeMode does for an empty list.
+		if len(cfg.Hashes) > 0 {
+			return runHashValidationMode(cfg, colorHandler, streams)
+		}
+		return config.ExitSuccess
+	}
+	if results.Incomplete && !cfg.Quiet {
+		fmt.Fprintf(streams.Err, "Interrupted: results are incomplete (%d of %d files hashed)\n",
+			results.FilesProcessed, len(cfg.Files))
+	}
 	sortResults(results, cfg.Files)
 	groupResultsByConfig(results, cfg)
 
@@ -216,27 +340,74 @@ func runStandardHashingMode(cfg *config.Config, colorHandler *color.Handler, str
 	return errors.DetermineExitCode(cfg, results)
 }
 
-func executeHashing(computer *hash.Computer, cfg *config.Config, streams *console.Streams, errHandler *errors.Handler) *hash.Result {
+// executeHashing hashes cfg.Files, or with roots, streams discovery under
+// them straight into the workers. Paths discovery skipped are added to the
+// result's errors.
+func executeHashing(ctx context.Context, computer *hash.Computer, cfg *config.Config, roots []string, numWorkers int, streams *console.Streams, errHandler *errors.Handler) *hash.Result {
 	results := &hash.Result{
 		Entries:  make([]hash.Entry, 0, len(cfg.Files)),
 		Unknowns: cfg.Unknowns,
 	}
-	bar := setupProgressBar(cfg, streams)
+	// While discovery is still walking, the total is not known yet.
+	var total int64 = progress.UnknownTotal
+	if roots == nil {
+		total = totalFileSize(cfg.Files)
+	}
+	bar := setupProgressBar(cfg, total, streams)
 	if bar != nil {
 		defer bar.Finish()
+		// Workers report bytes as they read, so the bar moves during large files.
+		computer.SetProgressFunc(bar.Add)
 	}
 
 	start := time.Now()
-	numWorkers := calculateWorkers(cfg.Jobs, runtime.NumCPU())
 
-	resultChan := computer.ComputeBatch(cfg.Files, numWorkers)
+	// "-" is hashed from the input stream rather than opened as a path.
+	sources := cfg.Files
+	if roots != nil {
+		sources = roots
+	}
+	if slices.Contains(sources, "-") {
+		processEntry(hashStdin(ctx, computer, streams), results, bar, cfg, streams, errHandler)
+	}
+
+	var resultChan <-chan hash.Entry
+	var feed *discoveryFeed
+	if roots == nil {
+		resultChan = computer.ComputeBatch(ctx, cfg.FilesWithoutStdin(), numWorkers)
+	} else {
+		feed = startDiscovery(ctx, cfg, roots)
+		resultChan = computer.ComputeFiles(ctx, feed.forward(ctx, bar), numWorkers)
+	}
 	for entry := range resultChan {
 		processEntry(entry, results, bar, cfg, streams, errHandler)
 	}
 	results.Duration = time.Since(start)
+	results.Incomplete = ctx.Err() != nil
+
+	if feed != nil {
+		files, unreadable := feed.files()
+		cfg.Files = files
+		reportDiscoveryErrors(cfg, unreadable, bar, streams, errHandler)
+		reportSkipped(cfg, feed.discovery.Skipped(), bar, streams)
+	}
+	results.Errors = append(results.Errors, cfg.DiscoveryErrors...)
 	return results
 }
 
+// archiveLimits converts the --archive-* settings into hash.ArchiveLimits.
+func archiveLimits(cfg *config.Config) hash.ArchiveLimits {
+	limits := hash.DefaultArchiveLimits()
+	limits.MaxMembers = cfg.ArchiveMaxMembers
+	limits.MaxTotalSize = cfg.ArchiveMaxSize
+	if limits.MaxMemberSize > limits.MaxTotalSize {
+		limits.MaxMemberSize = limits.MaxTotalSize
+	}
+	return limits
+}
+
+// sortResults restores input order. Archive members sort with their archive
+// and keep the order in which they were read from it.
 func sortResults(results *hash.Result, originalFiles []string) {
 	if len(results.Entries) <= 1 {
 		return
@@ -245,8 +416,18 @@ func sortResults(results *hash.Result, originalFiles []string) {
 	for i, p := range originalFiles {
 		order[p] = i
 	}
-	sort.Slice(results.Entries, func(i, j int) bool {
-		return order[results.Entries[i].Original] < order[results.Entries[j].Original]
+	position := func(e hash.Entry) int {
+		if i, ok := order[e.Original]; ok {
+			return i
+		}
+		if e.Original == hash.StdinEntryName {
+			return order["-"]
+		}
+		archive, _, _ := hash.SplitArchivePath(e.Original)
+		return order[archive]
+	}
+	sort.SliceStable(results.Entries, func(i, j int) bool {
+		return position(results.Entries[i]) < position(results.Entries[j])
 	})
 }
 
@@ -266,7 +447,7 @@ func groupResultsByConfig(results *hash.Result, cfg *config.Config) {
 		for _, entry := range results.Entries {
 			if entry.Error == nil {
 				for _, h := range cfg.Hashes {
-					if strings.EqualFold(entry.Hash, h) {
+					if entry.HasDigest(h) {
 						results.PoolMatches = append(results.PoolMatches, hash.PoolMatch{
 							FilePath:     entry.Original,
 							ComputedHash: entry.Hash,
@@ -284,7 +465,22 @@ func saveManifestIfRequested(results *hash.Result, cfg *config.Config, streams *
 	if cfg.OutputManifest == "" {
 		return
 	}
-	m := manifest.New(cfg.Algorithm, results.Entries)
+	m := manifest.New(cfg.Algorithm, hash.EncodeEntries(results.Entries, cfg.Encoding))
+	if cfg.Encoding != hash.EncodingHex {
+		m.Encoding = cfg.Encoding
+	}
+	if algorithms := cfg.AlgorithmList(); len(algorithms) > 1 {
+		m.Algorithms = algorithms
+	}
+	m.Incomplete = results.Incomplete
+	for _, alg := range cfg.AlgorithmList() {
+		if hash.IsKeyed(alg) {
+			m.Keyed = true
+		}
+		if hash.IsSampled(alg) {
+			m.Sampled = true
+		}
+	}
 	if err := manifest.Save(m, cfg.OutputManifest); err != nil {
 		fmt.Fprintf(streams.Err, "Error saving manifest: %v\n", errHandler.FormatError(err))
 	} else if !cfg.Quiet {
@@ -293,17 +489,33 @@ func saveManifestIfRequested(results *hash.Result, cfg *config.Config, streams *
 }
 
 
-func setupProgressBar(cfg *config.Config, streams *console.Streams) *progress.Bar {
+// setupProgressBar sizes the bar by total, the combined size of the files
+// to hash, so throughput and ETA reflect bytes rather than file count. The
+// total may be progress.UnknownTotal until discovery completes.
+func setupProgressBar(cfg *config.Config, total int64, streams *console.Streams) *progress.Bar {
 	if !cfg.Quiet && !cfg.Bool {
 		return progress.NewBar(&progress.Options{
-			Total:       int64(len(cfg.Files)),
+			Total:       total,
 			Description: "Hashing files...",
+			ShowBytes:   true,
 			Writer:      streams.Err,
 		})
 	}
 	return nil
 }
 
+// totalFileSize sums the sizes of files. Files that cannot be stat'ed count
+// as zero; hashing will report the error itself.
+func totalFileSize(files []string) int64 {
+	var total int64
+	for _, f := range files {
+		if info, err := os.Stat(f); err == nil {
+			total += info.Size()
+		}
+	}
+	return total
+}
+
 func processEntry(entry hash.Entry, results *hash.Result, bar *progress.Bar, cfg *config.Config, streams *console.Streams, errHandler *errors.Handler) {
 	if entry.Error != nil {
 		results.Errors = append(results.Errors, entry.Error)
@@ -321,9 +533,6 @@ func processEntry(entry hash.Entry, results *hash.Result, bar *progress.Bar, cfg
 		results.FilesProcessed++
 		results.BytesProcessed += entry.Size
 	}
-	if bar != nil {
-		bar.Increment()
-	}
 }
 
 func outputResults(results *hash.Result, cfg *config.Config, streams *console.Streams) {
@@ -331,11 +540,30 @@ func outputResults(results *hash.Result, cfg *config.Config, streams *console.St
 		success := isSuccess(results, cfg)
 		fmt.Fprintln(streams.Out, success)
 	} else if !cfg.Quiet {
-		formatter := output.NewFormatter(cfg.OutputFormat, cfg.PreserveOrder)
-		fmt.Fprintln(streams.Out, formatter.Format(results))
+		writeFormatted(streams.Out, cfg, results)
 	}
 }
 
+// writeFormatted prints results in cfg's output format. --print0 records
+// carry their own terminators, so no newline follows them.
+func writeFormatted(w io.Writer, cfg *config.Config, results *hash.Result) {
+	if cfg.Print0 {
+		fmt.Fprint(w, newFormatter(cfg).Format(results))
+		return
+	}
+	fmt.Fprintln(w, newFormatter(cfg).Format(results))
+}
+
+// newFormatter builds the formatter for cfg: digests are encoded as
+// requested, then sampled fingerprints are labelled.
+func newFormatter(cfg *config.Config) output.Formatter {
+	base := output.NewFormatter(cfg.OutputFormat, cfg.PreserveOrder)
+	if cfg.Print0 {
+		base = &output.PlainFormatter{Null: true}
+	}
+	return output.WithEncoding(output.WithSampledLabels(base), cfg.Encoding)
+}
+
 func isSuccess(results *hash.Result, cfg *config.Config) bool {
 	// Handle new match flags
 	if cfg.AnyMatch || cfg.MatchRequired {
@@ -395,15 +623,35 @@ func initializeGroups(files []hash.Entry) (map[string][]hash.Entry, []string) {
 }
 
 func consumeReferenceHashes(groups map[string][]hash.Entry, refHashes []string, algorithm string) map[int]bool {
+	// A reference hash may match any digest computed for a file (e.g. an MD5
+	// reference when hashing with -a sha256,md5), so index every digest back
+	// to the primary-hash group it belongs to.
+	type groupRef struct {
+		key       string
+		algorithm string
+	}
+	index := make(map[string]groupRef)
+	for key, entries := range groups {
+		for _, e := range entries {
+			for _, d := range e.Digests() {
+				index[strings.ToLower(d.Hash)] = groupRef{key: key, algorithm: d.Algorithm}
+			}
+		}
+	}
+
 	consumedRefs := make(map[int]bool)
 	for i, h := range refHashes {
 		normalized := strings.ToLower(h)
-		if _, exists := groups[normalized]; exists {
-			groups[normalized] = append(groups[normalized], hash.Entry{
+		if ref, exists := index[normalized]; exists {
+			refAlgorithm := ref.algorithm
+			if refAlgorithm == "" {
+				refAlgorithm = algorithm
+			}
+			groups[ref.key] = append(groups[ref.key], hash.Entry{
 				Original:    h,
 				Hash:        normalized,
 				IsReference: true,
-				Algorithm:   algorithm,
+				Algorithm:   refAlgorithm,
 			})
 			consumedRefs[i] = true
 		}
@@ -497,26 +745,30 @@ func runHashValidationMode(cfg *config.Config, colorHandler *color.Handler, stre
 }
 
 func validateHash(hashStr string, cfg *config.Config, colorHandler *color.Handler, streams *console.Streams) bool {
-	algorithms := hash.DetectHashAlgorithm(hashStr)
-	if len(algorithms) == 0 {
+	digest, ok := hash.DecodeDigest(hashStr)
+	if !ok {
 		reportInvalidHash(hashStr, cfg, colorHandler, streams)
 		return false
 	}
 
-	reportValidHash(hashStr, algorithms, cfg, colorHandler, streams)
+	reportValidHash(hashStr, digest, cfg, colorHandler, streams)
 	return true
 }
 
 func reportInvalidHash(hashStr string, cfg *config.Config, colorHandler *color.Handler, streams *console.Streams) {
 	if !cfg.Quiet {
 		fmt.Fprintf(streams.Err, "%s %s - Invalid hash format\n", colorHandler.Red("✗"), hashStr)
-		fmt.Fprintf(streams.Err, "  Hash strings must contain only hexadecimal characters and have a valid length.\n")
+		fmt.Fprintf(streams.Err, "  Hash strings must be digests of a supported length, written in %s.\n", formatAlgorithmList(hash.Encodings))
 	}
 }
 
-func reportValidHash(hashStr string, algorithms []string, cfg *config.Config, colorHandler *color.Handler, streams *console.Streams) {
+func reportValidHash(hashStr string, digest hash.DecodedDigest, cfg *config.Config, colorHandler *color.Handler, streams *console.Streams) {
 	if !cfg.Quiet {
+		algorithms := digest.Candidates()
 		fmt.Fprintf(streams.Err, "%s %s - Valid hash\n", colorHandler.Green("✓"), hashStr)
+		if digest.Encoding != hash.EncodingHex {
+			fmt.Fprintf(streams.Err, "  Encoding: %s (hex %s)\n", digest.Encoding, digest.Hex)
+		}
 		if len(algorithms) == 1 {
 			fmt.Fprintf(streams.Err, "  Algorithm: %s\n", algorithms[0])
 		} else {
@@ -551,17 +803,21 @@ func formatAlgorithmList(algorithms []string) string {
 // runFileHashComparisonMode compares a file's hash against a provided hash string.
 // This mode is triggered when exactly one file and one hash string are provided.
 // Requirements: 25.1, 25.2, 25.3
-func runFileHashComparisonMode(cfg *config.Config, colorHandler *color.Handler, streams *console.Streams) int {
+func runFileHashComparisonMode(ctx context.Context, cfg *config.Config, colorHandler *color.Handler, streams *console.Streams) int {
 	filePath := cfg.Files[0]
 	expectedHash := cfg.Hashes[0]
 
-	computer, err := hash.NewComputer(cfg.Algorithm)
+	computer, err := newComputer(cfg, cfg.Algorithm)
 	if err != nil {
 		handleComparisonError(err, "Failed to initialize hash computer", cfg, colorHandler, streams)
 		return config.ExitInvalidArgs
 	}
 
-	entry, err := computer.ComputeFile(filePath)
+	entry, err := computer.ComputeFile(ctx, filePath)
+	if ctx.Err() != nil {
+		fmt.Fprintln(streams.Err, "Interrupted: the hash was not computed")
+		return config.ExitInterrupted
+	}
 	if err != nil {
 		handleComparisonError(err,
 			fmt.Sprintf("Failed to compute hash for %s", security.SanitizeOutput(filePath)),
@@ -603,8 +859,56 @@ func outputComparisonResult(match bool, filePath, expected, computed string, cfg
 	}
 }
 
-// expandStdinFiles reads file paths from stdin and adds them to the file list.
-func expandStdinFiles(files []string) []string {
+// expandPathLists adds the paths listed on stdin (--stdin-paths) and in the
+// --files-from file to cfg.Files and returns the result. A list still
+// being written, such as a pipe from a slow find, is abandoned when ctx is
+// cancelled; the read is left blocked, as the run is ending anyway.
+func expandPathLists(ctx context.Context, cfg *config.Config, stdin io.Reader) ([]string, error) {
+	type result struct {
+		files []string
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		files, err := readPathLists(cfg, stdin)
+		done <- result{files, err}
+	}()
+	select {
+	case r := <-done:
+		return r.files, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// readPathLists does the reading for expandPathLists.
+func readPathLists(cfg *config.Config, stdin io.Reader) ([]string, error) {
+	files := cfg.Files
+	if cfg.StdinPaths {
+		var err error
+		if files, err = expandStdinFiles(files, stdin, cfg.Null); err != nil {
+			return nil, fmt.Errorf("reading standard input: %w", err)
+		}
+	}
+	if cfg.FilesFrom != "" {
+		list, err := os.Open(cfg.FilesFrom)
+		if err != nil {
+			return nil, err
+		}
+		defer list.Close()
+		paths, err := readPathList(list, cfg.Null)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", cfg.FilesFrom, err)
+		}
+		files = append(files, paths...)
+	}
+	return files, nil
+}
+
+// expandStdinFiles reads file paths from stdin (--stdin-paths) and adds them
+// to the file list. A read error fails the whole list rather than leaving
+// it silently cut short.
+func expandStdinFiles(files []string, stdin io.Reader, null bool) ([]string, error) {
 	var result []string
 
 	// Remove the "-" marker
@@ -614,16 +918,34 @@ func expandStdinFiles(files []string) []string {
 		}
 	}
 
-	// Read from stdin
-	scanner := bufio.NewScanner(os.Stdin)
-	for scanner.Scan() {
-		path := strings.TrimSpace(scanner.Text())
+	paths, err := readPathList(stdin, null)
+	if err != nil {
+		return nil, err
+	}
+	return append(result, paths...), nil
+}
+
+// readPathList reads a list of paths. With null (-0) the list is split on
+// NULs and its names are kept byte for byte, so names with newlines or
+// surrounding spaces survive find -print0. Otherwise it is read one path
+// per line, with surrounding whitespace trimmed. Empty entries are dropped
+// either way.
+func readPathList(r io.Reader, null bool) ([]string, error) {
+	data, err := io.ReadAll(r)
+	sep := "\n"
+	if null {
+		sep = "\x00"
+	}
+	var paths []string
+	for _, path := range strings.Split(string(data), sep) {
+		if sep == "\n" {
+			path = strings.TrimSpace(path)
+		}
 		if path != "" {
-			result = append(result, path)
+			paths = append(paths, path)
 		}
 	}
-
-	return result
+	return paths, err
 }
 
 // runDryRunMode enumerates files and displays a preview without hashing.
@@ -636,6 +958,12 @@ func runDryRunMode(cfg *config.Config, colorHandler *color.Handler, streams *con
 	fileCount := 0
 
 	for _, path := range cfg.Files {
+		if path == "-" {
+			if !cfg.Quiet {
+				fmt.Fprintf(streams.Out, "%s    (size unknown until read)\n", hash.StdinEntryName)
+			}
+			continue
+		}
 		info, err := os.Stat(path)
 		if err != nil {
 			if !cfg.Quiet {
@@ -647,7 +975,7 @@ func runDryRunMode(cfg *config.Config, colorHandler *color.Handler, streams *con
 		if !info.IsDir() {
 			if !cfg.Quiet {
 				fmt.Fprintf(streams.Out, "%s    (estimated size: %s)\n",
-					security.SanitizeOutput(path), formatSize(info.Size()))
+					security.SanitizeOutput(path), output.FormatBytes(info.Size()))
 			}
 			totalSize += info.Size()
 			fileCount++
@@ -657,24 +985,17 @@ func runDryRunMode(cfg *config.Config, colorHandler *color.Handler, streams *con
 	if !cfg.Quiet {
 		fmt.Fprintf(streams.Err, "\nSummary:\n")
 		fmt.Fprintf(streams.Err, "  Files to process: %d\n", fileCount)
-		fmt.Fprintf(streams.Err, "  Aggregate size:   %s\n", formatSize(totalSize))
+		fmt.Fprintf(streams.Err, "  Aggregate size:   %s\n", output.FormatBytes(totalSize))
 		fmt.Fprintf(streams.Err, "  Estimated time:   %s\n", estimateTime(totalSize))
+		if len(cfg.DiscoveryErrors) > 0 {
+			fmt.Fprintf(streams.Err, "  Skipped paths:    %d (could not be read)\n", len(cfg.DiscoveryErrors))
+		}
 	}
 
-	return config.ExitSuccess
-}
-
-func formatSize(size int64) string {
-	const unit = 1024
-	if size < unit {
-		return fmt.Sprintf("%d B", size)
-	}
-	div, exp := int64(unit), 0
-	for n := size / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
+	if len(cfg.DiscoveryErrors) > 0 {
+		return config.ExitPartialFailure
 	}
-	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+	return config.ExitSuccess
 }
 
 func estimateTime(size int64) string {
@@ -3,6 +3,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -48,6 +49,9 @@ func TestMain(m *testing.M) {
 		os.Exit(1)
 	}
 
+	// Keep the hash cache of these tests out of the user's cache directory.
+	os.Setenv("XDG_CACHE_HOME", filepath.Join(tmpDir, "cache"))
+
 	// Add binary path to PATH
 	oldPath := os.Getenv("PATH")
 	os.Setenv("PATH", tmpDir+string(os.PathListSeparator)+oldPath)
@@ -66,10 +70,12 @@ func TestMain(m *testing.M) {
 // **Validates: Requirements 24.2, 24.4**
 func TestHashValidationMode_ReportsCorrectAlgorithms_Property(t *testing.T) {
 	validLengths := map[int][]string{
+		8:   {hash.AlgorithmCRC32C},
+		16:  {hash.AlgorithmXXH64},
 		32:  {hash.AlgorithmMD5},
 		40:  {hash.AlgorithmSHA1},
-		64:  {hash.AlgorithmSHA256},
-		128: {hash.AlgorithmSHA512, hash.AlgorithmBLAKE2b},
+		64:  {hash.AlgorithmSHA256, hash.AlgorithmSHA3_256, hash.AlgorithmBLAKE2s, hash.AlgorithmBLAKE3},
+		128: {hash.AlgorithmSHA512, hash.AlgorithmBLAKE2b, hash.AlgorithmSHA3_512},
 	}
 
 	f := func(hashLength int, hexChars []byte) bool {
@@ -84,7 +90,7 @@ func TestHashValidationMode_ReportsCorrectAlgorithms_Property(t *testing.T) {
 
 	config := &quick.Config{
 		Values: func(values []reflect.Value, rand *rand.Rand) {
-			lengths := []int{32, 40, 64, 128}
+			lengths := []int{8, 16, 32, 40, 64, 128}
 			values[0] = reflect.ValueOf(lengths[rand.Intn(len(lengths))])
 			values[1] = reflect.ValueOf(generateRandomHexChars(rand))
 		},
@@ -159,7 +165,9 @@ func TestHashValidationMode_ValidHashStrings(t *testing.T) {
 	}{
 		{"MD5", "d41d8cd98f00b204e9800998ecf8427e", []string{hash.AlgorithmMD5}},
 		{"SHA1", "da39a3ee5e6b4b0d3255bfef95601890afd80709", []string{hash.AlgorithmSHA1}},
-		{"SHA256", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", []string{hash.AlgorithmSHA256}},
+		{"SHA256", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", []string{hash.AlgorithmSHA256, hash.AlgorithmSHA3_256, hash.AlgorithmBLAKE2s, hash.AlgorithmBLAKE3}},
+		{"CRC32C", "e3069283", []string{hash.AlgorithmCRC32C}},
+		{"XXH64", "ef46db3751d8e999", []string{hash.AlgorithmXXH64}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -282,7 +290,7 @@ func createTempFile(content []byte) (string, func()) {
 
 func computeSHA256(path string) string {
 	c, _ := hash.NewComputer("sha256")
-	e, _ := c.ComputeFile(path)
+	e, _ := c.ComputeFile(context.Background(), path)
 	return e.Hash
 }
 
@@ -305,7 +313,7 @@ func runComparisonAndVerifyExitCode(path, expected string, shouldMatch bool) boo
 		Quiet:     true,
 	}
 	streams := &console.Streams{Out: io.Discard, Err: io.Discard}
-	exitCode := runFileHashComparisonMode(cfg, color.NewColorHandler(), streams)
+	exitCode := runFileHashComparisonMode(context.Background(), cfg, color.NewColorHandler(), streams)
 	if shouldMatch {
 		return exitCode == config.ExitSuccess
 	}
@@ -347,7 +355,7 @@ func verifyBoolOutput(path, expected string, shouldMatch bool) bool {
 
 	var buf bytes.Buffer
 	streams := &console.Streams{Out: &buf, Err: io.Discard}
-	exitCode := runFileHashComparisonMode(cfg, color.NewColorHandler(), streams)
+	exitCode := runFileHashComparisonMode(context.Background(), cfg, color.NewColorHandler(), streams)
 
 	output := buf.String()
 	expectedOutput := "false\n"
@@ -377,7 +385,7 @@ func TestFileHashComparisonMode_MatchingHash(t *testing.T) {
 	colorHandler.SetEnabled(false)
 	streams := &console.Streams{Out: io.Discard, Err: io.Discard}
 
-	if exitCode := runFileHashComparisonMode(cfg, colorHandler, streams); exitCode != config.ExitSuccess {
+	if exitCode := runFileHashComparisonMode(context.Background(), cfg, colorHandler, streams); exitCode != config.ExitSuccess {
 		t.Errorf("Expected exit code %d for matching hash, got %d", config.ExitSuccess, exitCode)
 	}
 }
@@ -399,7 +407,7 @@ func TestFileHashComparisonMode_MatchingHashBool(t *testing.T) {
 	colorHandler.SetEnabled(false)
 	streams := &console.Streams{Out: io.Discard, Err: io.Discard}
 
-	if exitCode := runFileHashComparisonMode(cfg, colorHandler, streams); exitCode != config.ExitSuccess {
+	if exitCode := runFileHashComparisonMode(context.Background(), cfg, colorHandler, streams); exitCode != config.ExitSuccess {
 		t.Errorf("Expected exit code %d for matching hash in bool mode, got %d", config.ExitSuccess, exitCode)
 	}
 }
@@ -417,7 +425,7 @@ func setupMatchingHashFile(t *testing.T) (string, string) {
 	tmpFile.Close()
 
 	computer, _ := hash.NewComputer("sha256")
-	entry, _ := computer.ComputeFile(tmpFile.Name())
+	entry, _ := computer.ComputeFile(context.Background(), tmpFile.Name())
 	return tmpFile.Name(), entry.Hash
 }
 
@@ -453,7 +461,7 @@ func TestFileHashComparisonMode_MismatchingHash(t *testing.T) {
 	colorHandler.SetEnabled(false)
 
 	streams := &console.Streams{Out: io.Discard, Err: io.Discard}
-	exitCode := runFileHashComparisonMode(cfg, colorHandler, streams)
+	exitCode := runFileHashComparisonMode(context.Background(), cfg, colorHandler, streams)
 
 	if exitCode != config.ExitNoMatches {
 		t.Errorf("Expected exit code %d for mismatching hash, got %d", config.ExitNoMatches, exitCode)
@@ -464,7 +472,7 @@ func TestFileHashComparisonMode_MismatchingHash(t *testing.T) {
 	cfg.Quiet = true // Bool implies Quiet behavior
 
 	streams = &console.Streams{Out: io.Discard, Err: io.Discard}
-	exitCode = runFileHashComparisonMode(cfg, colorHandler, streams)
+	exitCode = runFileHashComparisonMode(context.Background(), cfg, colorHandler, streams)
 
 	if exitCode != config.ExitNoMatches {
 		t.Errorf("Expected exit code %d for mismatching hash in bool mode, got %d", config.ExitNoMatches, exitCode)
@@ -510,7 +518,7 @@ func TestFileHashComparisonMode_FileNotFound(t *testing.T) {
 	colorHandler.SetEnabled(false)
 
 	streams := &console.Streams{Out: io.Discard, Err: io.Discard}
-	exitCode := runFileHashComparisonMode(cfg, colorHandler, streams)
+	exitCode := runFileHashComparisonMode(context.Background(), cfg, colorHandler, streams)
 
 	if exitCode != config.ExitFileNotFound {
 		t.Errorf("Expected exit code %d for file not found, got %d", config.ExitFileNotFound, exitCode)
@@ -633,7 +641,7 @@ func TestIncrementalHashingMode(t *testing.T) {
 	cfg1.OutputManifest = manifestPath
 
 	streams1 := &console.Streams{Out: io.Discard, Err: io.Discard}
-	runStandardHashingMode(cfg1, colorHandler, streams1, errHandler)
+	runStandardHashingMode(context.Background(), cfg1, colorHandler, streams1, errHandler)
 
 	if _, err := os.Stat(manifestPath); os.IsNotExist(err) {
 		t.Fatalf("Manifest was not created at %s", manifestPath)
@@ -649,7 +657,7 @@ func TestIncrementalHashingMode(t *testing.T) {
 	streams2 := &console.Streams{Out: &outBuf2, Err: &errBuf2}
 
 	// Prepare files should filter them out
-	err := prepareFiles(cfg2, errHandler, streams2)
+	err := prepareFiles(context.Background(), cfg2, errHandler, streams2)
 	if err != nil {
 		t.Fatalf("prepareFiles failed: %v", err)
 	}
@@ -669,7 +677,7 @@ func TestIncrementalHashingMode(t *testing.T) {
 	cfg3.Manifest = manifestPath
 	cfg3.OnlyChanged = true
 
-	err = prepareFiles(cfg3, errHandler, streams2)
+	err = prepareFiles(context.Background(), cfg3, errHandler, streams2)
 	if err != nil {
 		t.Fatalf("prepareFiles failed: %v", err)
 	}
@@ -694,7 +702,7 @@ func TestStandardHashingMode(t *testing.T) {
 	var outBuf, errBuf bytes.Buffer
 	streams := &console.Streams{Out: &outBuf, Err: &errBuf}
 
-	if exitCode := runStandardHashingMode(cfg, colorHandler, streams, errHandler); exitCode != config.ExitSuccess {
+	if exitCode := runStandardHashingMode(context.Background(), cfg, colorHandler, streams, errHandler); exitCode != config.ExitSuccess {
 		t.Errorf("Expected exit code 0, got %d. Stderr: %s", exitCode, errBuf.String())
 	}
 
@@ -705,7 +713,7 @@ func TestStandardHashingMode(t *testing.T) {
 	t.Run("JSON", func(t *testing.T) {
 		outBuf.Reset()
 		cfg.OutputFormat = "json"
-		runStandardHashingMode(cfg, colorHandler, streams, errHandler)
+		runStandardHashingMode(context.Background(), cfg, colorHandler, streams, errHandler)
 		verifyJSONResult(t, outBuf.Bytes())
 	})
 }
@@ -844,3 +852,16 @@ func stringSlicesEqual(a, b []string) bool {
 	}
 	return true
 }
+
+func TestFileHashComparisonMode_Interrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "big")
+	os.WriteFile(path, bytes.Repeat([]byte("x"), 1<<20), 0644)
+	cfg := &config.Config{Files: []string{path}, Hashes: []string{strings.Repeat("0", 64)}, Algorithm: "sha256"}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var errOut bytes.Buffer
+	streams := &console.Streams{Out: io.Discard, Err: &errOut}
+	if code := runFileHashComparisonMode(ctx, cfg, color.NewColorHandler(), streams); code != config.ExitInterrupted {
+		t.Errorf("exit = %d, want %d; stderr %q", code, config.ExitInterrupted, errOut.String())
+	}
+}
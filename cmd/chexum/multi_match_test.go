@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
@@ -105,11 +106,11 @@ func runMultiMatchSubtest(t *testing.T, args []string, expectedStatus int, expec
 	colorHandler := color.NewColorHandler()
 	errHandler := errors.NewErrorHandler(colorHandler)
 
-	if err := prepareFiles(cfg, errHandler, streams); err != nil {
+	if err := prepareFiles(context.Background(), cfg, errHandler, streams); err != nil {
 		t.Fatalf("failed to prepare files: %v", err)
 	}
 
-	status := executeMode(cfg, colorHandler, streams, errHandler)
+	status := executeMode(context.Background(), cfg, colorHandler, streams, errHandler)
 
 	if status != expectedStatus {
 		t.Errorf("expected status %d, got %d", expectedStatus, status)
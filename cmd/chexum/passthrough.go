@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/Les-El/chexum/internal/color"
+	"github.com/Les-El/chexum/internal/config"
+	"github.com/Les-El/chexum/internal/console"
+	"github.com/Les-El/chexum/internal/errors"
+	"github.com/Les-El/chexum/internal/hash"
+)
+
+// runPassthroughMode copies stdin to stdout unchanged while hashing it
+// (--passthrough), so chexum can sit in the middle of a pipeline:
+//
+//	curl -s URL | chexum --passthrough --expect HASH | tar x
+//
+// Stdout carries only the forwarded data; the digest is reported on the
+// report stream (stderr, or the --output file). With --expect, a mismatch
+// exits with ExitNoMatches so `set -o pipefail` scripts abort. The data has
+// already been forwarded by then: the exit code is the verdict.
+func runPassthroughMode(ctx context.Context, cfg *config.Config, colorHandler *color.Handler, streams *console.Streams, errHandler *errors.Handler) int {
+	computer, err := newComputer(cfg, cfg.AlgorithmList()...)
+	if err != nil {
+		fmt.Fprintln(streams.Err, errHandler.FormatError(err))
+		return config.ExitInvalidArgs
+	}
+
+	entry, err := computer.ComputeStream(ctx, hash.StdinEntryName, io.TeeReader(streams.Input(), streams.Out))
+	if ctx.Err() != nil {
+		if !cfg.Quiet {
+			fmt.Fprintln(streams.Err, "Interrupted: passthrough stopped before the end of stdin; no digest was produced")
+		}
+		return config.ExitInterrupted
+	}
+	if err != nil {
+		fmt.Fprintln(streams.Err, errHandler.FormatError(fmt.Errorf("passthrough failed: %w", err)))
+		return config.ExitPartialFailure
+	}
+
+	results := &hash.Result{
+		Entries:        []hash.Entry{*entry},
+		Unmatched:      []hash.Entry{*entry},
+		FilesProcessed: 1,
+		BytesProcessed: entry.Size,
+	}
+	matched := cfg.Expect == "" || entry.HasDigest(cfg.Expect)
+	if cfg.Expect != "" && matched {
+		results.PoolMatches = append(results.PoolMatches, hash.PoolMatch{
+			FilePath:     entry.Original,
+			ComputedHash: entry.Hash,
+			ProvidedHash: cfg.Expect,
+			Algorithm:    entry.Algorithm,
+		})
+	}
+
+	if !cfg.Quiet {
+		writeFormatted(streams.ReportWriter(), cfg, results)
+	}
+	saveManifestIfRequested(results, cfg, streams, errHandler)
+
+	if !matched {
+		if !cfg.Quiet {
+			fmt.Fprintf(streams.Err, "%s %s does not match the expected hash\n", colorHandler.Red("✗"), hash.StdinEntryName)
+			fmt.Fprintf(streams.Err, "  Expected: %s\n", cfg.Expect)
+			fmt.Fprintf(streams.Err, "  Computed: %s\n", entry.Hash)
+		}
+		return config.ExitNoMatches
+	}
+	return config.ExitSuccess
+}
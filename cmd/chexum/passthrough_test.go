@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Les-El/chexum/internal/color"
+	"github.com/Les-El/chexum/internal/config"
+	"github.com/Les-El/chexum/internal/console"
+	"github.com/Les-El/chexum/internal/errors"
+)
+
+func TestPassthroughMode(t *testing.T) {
+	// SHA256 of "content1"
+	ref := "d0b425e00e15a0d36b9b361f02bab63563aed6cb4665083905386c55d5b679fa"
+
+	run := func(stdin string, args ...string) (int, string, string) {
+		t.Helper()
+		cfg, _, err := config.ParseArgs(args)
+		if err != nil {
+			t.Fatalf("ParseArgs(%v) error = %v", args, err)
+		}
+		var outBuf, errBuf bytes.Buffer
+		streams := &console.Streams{In: strings.NewReader(stdin), Out: &outBuf, Err: &errBuf, Report: &errBuf}
+		colorHandler := color.NewColorHandler()
+		errHandler := errors.NewErrorHandler(colorHandler)
+		return runPassthroughMode(context.Background(), cfg, colorHandler, streams, errHandler), outBuf.String(), errBuf.String()
+	}
+
+	t.Run("forwards data and reports digest", func(t *testing.T) {
+		code, out, report := run("content1", "--passthrough")
+		if code != config.ExitSuccess || out != "content1" {
+			t.Errorf("got exit %d, stdout %q", code, out)
+		}
+		if !strings.Contains(report, "<stdin>    "+ref) {
+			t.Errorf("expected the digest on the report stream, got %q", report)
+		}
+	})
+
+	t.Run("expected hash matches", func(t *testing.T) {
+		if code, out, _ := run("content1", "--passthrough", "--expect", strings.ToUpper(ref)); code != config.ExitSuccess || out != "content1" {
+			t.Errorf("got exit %d, stdout %q", code, out)
+		}
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		code, out, report := run("tampered", "--passthrough", "--expect", ref)
+		if code != config.ExitNoMatches {
+			t.Errorf("exit = %d, want %d", code, config.ExitNoMatches)
+		}
+		if out != "tampered" {
+			t.Errorf("data should still be forwarded, got %q", out)
+		}
+		if !strings.Contains(report, "does not match") {
+			t.Errorf("expected a mismatch report, got %q", report)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
@@ -50,10 +51,36 @@ func TestPoolMatchingMode_Integration(t *testing.T) {
 	})
 }
 
+func TestPoolMatchingMode_SecondaryDigest(t *testing.T) {
+	colorHandler := color.NewColorHandler()
+	errHandler := errors.NewErrorHandler(colorHandler)
+
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "testfile.txt")
+	if err := os.WriteFile(testFile, []byte("test"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	// MD5 of "test": a reference for the secondary algorithm must still match.
+	md5Hash := "098f6bcd4621d373cade4e832627b4f6"
+	cfg, _, err := config.ParseArgs([]string{"-a", "sha256,md5", testFile, md5Hash})
+	if err != nil {
+		t.Fatalf("ParseArgs failed: %v", err)
+	}
+	if len(cfg.Hashes) != 1 {
+		t.Fatalf("expected md5 argument to be classified as a hash, got hashes=%v unknowns=%v", cfg.Hashes, cfg.Unknowns)
+	}
+
+	out, _ := runPoolTest(t, cfg, colorHandler, errHandler)
+	if !strings.Contains(out, "REFERENCE:    "+md5Hash) || !strings.Contains(out, "md5:"+md5Hash) {
+		t.Errorf("expected md5 reference to join the file's group, got: %s", out)
+	}
+}
+
 func runPoolTest(t *testing.T, cfg *config.Config, ch *color.Handler, eh *errors.Handler) (string, int) {
 	var outBuf, errBuf bytes.Buffer
 	streams := &console.Streams{Out: &outBuf, Err: &errBuf}
-	exitCode := runStandardHashingMode(cfg, ch, streams, eh)
+	exitCode := runStandardHashingMode(context.Background(), cfg, ch, streams, eh)
 	if exitCode != 0 {
 		t.Errorf("Expected exit code 0, got %d. Err: %s", exitCode, errBuf.String())
 	}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Les-El/chexum/internal/color"
+	"github.com/Les-El/chexum/internal/config"
+	"github.com/Les-El/chexum/internal/console"
+	"github.com/Les-El/chexum/internal/errors"
+)
+
+func TestStdinData(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.txt")
+	os.WriteFile(file, []byte("content1"), 0644)
+
+	// SHA256 of "content1"
+	ref := "d0b425e00e15a0d36b9b361f02bab63563aed6cb4665083905386c55d5b679fa"
+
+	run := func(stdin string, args ...string) (int, string) {
+		t.Helper()
+		cfg, _, err := config.ParseArgs(args)
+		if err != nil {
+			t.Fatalf("ParseArgs(%v) error = %v", args, err)
+		}
+		var outBuf, errBuf bytes.Buffer
+		streams := &console.Streams{In: strings.NewReader(stdin), Out: &outBuf, Err: &errBuf}
+		colorHandler := color.NewColorHandler()
+		errHandler := errors.NewErrorHandler(colorHandler)
+		if err := prepareFiles(context.Background(), cfg, errHandler, streams); err != nil {
+			t.Fatalf("prepareFiles() error = %v", err)
+		}
+		return executeMode(context.Background(), cfg, colorHandler, streams, errHandler), outBuf.String()
+	}
+
+	t.Run("hashes piped data", func(t *testing.T) {
+		code, out := run("content1", "-")
+		if code != config.ExitSuccess || !strings.Contains(out, "<stdin>    "+ref) {
+			t.Errorf("got exit %d, output %q", code, out)
+		}
+	})
+
+	t.Run("matches with files", func(t *testing.T) {
+		_, out := run("content1", "-", file)
+		if groups := strings.Split(out, "\n\n"); !strings.Contains(groups[0], "<stdin>") || !strings.Contains(groups[0], file) {
+			t.Errorf("expected stdin and file in one group, got %q", out)
+		}
+	})
+
+	t.Run("reference hash", func(t *testing.T) {
+		if code, out := run("content1", "--bool", "--any-match", "-", ref); code != config.ExitSuccess || out != "true\n" {
+			t.Errorf("got exit %d, output %q", code, out)
+		}
+		if code, _ := run("other", "--bool", "--any-match", "-", ref); code != config.ExitNoMatches {
+			t.Errorf("mismatch exit = %d, want %d", code, config.ExitNoMatches)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		_, out := run("content1", "--json", "-")
+		var doc struct {
+			Unmatched []struct{ File, Hash string }
+		}
+		if err := json.Unmarshal([]byte(out), &doc); err != nil {
+			t.Fatalf("invalid JSON %q: %v", out, err)
+		}
+		if len(doc.Unmatched) != 1 || doc.Unmatched[0].File != "<stdin>" || doc.Unmatched[0].Hash != ref {
+			t.Errorf("expected a <stdin> entry, got %+v", doc)
+		}
+	})
+
+	t.Run("stdin paths", func(t *testing.T) {
+		if _, out := run(file+"\n", "--stdin-paths", "--plain"); out != file+"\t"+ref+"\n" {
+			t.Errorf("got %q", out)
+		}
+	})
+}
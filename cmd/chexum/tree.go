@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/Les-El/chexum/internal/config"
+	"github.com/Les-El/chexum/internal/console"
+	"github.com/Les-El/chexum/internal/errors"
+	"github.com/Les-El/chexum/internal/hash"
+	"github.com/Les-El/chexum/internal/output"
+	"github.com/Les-El/chexum/internal/security"
+)
+
+// runTreeMode prints one Merkle digest per directory argument (--tree).
+// A directory that cannot be digested is reported and skipped; the exit code
+// reflects the first such failure.
+func runTreeMode(ctx context.Context, cfg *config.Config, streams *console.Streams, errHandler *errors.Handler) int {
+	roots, err := expandPathLists(ctx, cfg, streams.Input())
+	if ctx.Err() != nil {
+		return config.ExitInterrupted
+	}
+	if err != nil {
+		fmt.Fprintln(streams.Err, errHandler.FormatError(err))
+		return errors.DetermineDiscoveryExitCode(err)
+	}
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+
+	computer, err := newComputer(cfg, cfg.Algorithm)
+	if err != nil {
+		fmt.Fprintln(streams.Err, errHandler.FormatError(err))
+		return config.ExitInvalidArgs
+	}
+	if len(cfg.Algorithms) > 1 && !cfg.Quiet {
+		fmt.Fprintf(streams.Err, "Notice: --tree uses only the primary algorithm (%s)\n", cfg.Algorithm)
+	}
+	workers, finish := prepareComputer(cfg, computer, streams)
+	defer finish()
+
+	opts := discoveryOptions(cfg)
+	exitCode := config.ExitSuccess
+
+	trees := make([]*hash.TreeDigest, 0, len(roots))
+	for _, root := range roots {
+		if info, err := os.Stat(root); err == nil && !info.IsDir() {
+			fmt.Fprintln(streams.Err, errHandler.FormatError(&errors.Error{
+				Type:       errors.ErrorTypeInvalidInput,
+				Message:    fmt.Sprintf("Not a directory: %s", security.SanitizeOutput(root)),
+				Suggestion: "--tree digests directories; hash individual files without --tree.",
+				Path:       root,
+			}))
+			if exitCode == config.ExitSuccess {
+				exitCode = config.ExitInvalidArgs
+			}
+			continue
+		}
+
+		tree, err := computer.ComputeTree(ctx, root, opts, workers)
+		if ctx.Err() != nil {
+			if !cfg.Quiet {
+				fmt.Fprintln(streams.Err, "Interrupted: no directory digest was produced for", root)
+			}
+			return config.ExitInterrupted
+		}
+		if err != nil {
+			fmt.Fprintln(streams.Err, errHandler.FormatError(err))
+			if exitCode == config.ExitSuccess {
+				exitCode = errors.DetermineDiscoveryExitCode(err)
+			}
+			continue
+		}
+		trees = append(trees, tree)
+	}
+
+	if !cfg.Quiet && len(trees) > 0 {
+		fmt.Fprintln(streams.Out, output.FormatTrees(cfg.OutputFormat, output.EncodeTrees(trees, cfg.Encoding), cfg.TreeBreakdown))
+	}
+	return exitCode
+}
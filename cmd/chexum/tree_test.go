@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Les-El/chexum/internal/color"
+	"github.com/Les-El/chexum/internal/config"
+	"github.com/Les-El/chexum/internal/console"
+	"github.com/Les-El/chexum/internal/errors"
+)
+
+func TestTreeMode(t *testing.T) {
+	makeTree := func(dir string) {
+		os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+		os.WriteFile(filepath.Join(dir, "a.txt"), []byte("alpha"), 0644)
+		os.WriteFile(filepath.Join(dir, "sub", "b.txt"), []byte("bravo"), 0644)
+	}
+	dir1 := filepath.Join(t.TempDir(), "one")
+	dir2 := filepath.Join(t.TempDir(), "two")
+	makeTree(dir1)
+	makeTree(dir2)
+	file := filepath.Join(dir1, "a.txt")
+
+	run := func(args ...string) (int, string, string) {
+		t.Helper()
+		cfg, _, err := config.ParseArgs(args)
+		if err != nil {
+			t.Fatalf("ParseArgs(%v) error = %v", args, err)
+		}
+		var outBuf, errBuf bytes.Buffer
+		streams := &console.Streams{Out: &outBuf, Err: &errBuf}
+		errHandler := errors.NewErrorHandler(color.NewColorHandler())
+		code := runTreeMode(context.Background(), cfg, streams, errHandler)
+		return code, outBuf.String(), errBuf.String()
+	}
+
+	t.Run("identical trees share a root digest", func(t *testing.T) {
+		code, out, _ := run("--tree", "--plain", dir1, dir2)
+		if code != config.ExitSuccess {
+			t.Fatalf("exit code = %d", code)
+		}
+		lines := strings.Split(out, "\n")
+		h1 := strings.Split(lines[0], "\t")[1]
+		h2 := strings.Split(lines[1], "\t")[1]
+		if h1 != h2 {
+			t.Errorf("expected equal digests, got %s and %s", h1, h2)
+		}
+	})
+
+	t.Run("breakdown lists subdirectories", func(t *testing.T) {
+		_, out, _ := run("--tree-breakdown", dir1)
+		if !strings.Contains(out, "  sub/    ") {
+			t.Errorf("expected sub/ in breakdown, got %q", out)
+		}
+	})
+
+	t.Run("file argument is rejected", func(t *testing.T) {
+		code, _, errOut := run("--tree", file)
+		if code != config.ExitInvalidArgs {
+			t.Errorf("exit code = %d, want %d", code, config.ExitInvalidArgs)
+		}
+		if !strings.Contains(errOut, "Not a directory") {
+			t.Errorf("expected not-a-directory error, got %q", errOut)
+		}
+	})
+}
@@ -0,0 +1,494 @@
+// Package cache keeps file digests between runs so that files which have not
+// changed since they were last hashed need not be read again.
+//
+// DESIGN PRINCIPLE: Trust the Inode, Never the Path
+// -------------------------------------------------
+// A cached digest is only as good as the test that says "this is the file
+// we hashed". Paths fail that test: the same tree is reached through
+// different working directories, symlinks and mounts, and a path can be
+// replaced by another file. Records are therefore keyed by device and inode
+// and carry the size, mtime and ctime seen when the file was read (see
+// hash.FileID). Any difference is a miss, and since ctime cannot be set
+// from user space, even "touch -r" after a rewrite is caught.
+//
+// The cache is an optimisation and never a source of truth:
+//
+//  1. Records are only stored for files that kept the same identity while
+//     they were read, and not for files changed so recently that a further
+//     write could land within the same timestamp tick.
+//  2. The file is append-only. Each record carries a checksum, so a torn
+//     write from a crash or a full disk only loses that record.
+//  3. Several chexum processes may share a cache: appends and compaction
+//     take an exclusive lock on a separate lock file, and compaction
+//     replaces the data file atomically with a rename.
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Les-El/chexum/internal/hash"
+)
+
+// File names inside the cache directory.
+const (
+	DataFile = "digests.v1"
+	lockFile = "digests.lock"
+)
+
+// magic starts every data file; a file with another header is discarded.
+const magic = "chexum-cache-v1\n"
+
+// Tuning defaults.
+const (
+	// DefaultMaxEntries bounds the number of files remembered. Compaction
+	// keeps the most recently stored ones.
+	DefaultMaxEntries = 500000
+
+	// DefaultMinAge is how old a file's last change must be before its
+	// digests are cached. Younger files may still be written within the
+	// same timestamp tick without their identity changing.
+	DefaultMinAge = 2 * time.Second
+
+	flushBytes     = 64 * 1024 // Append once this many bytes are pending
+	compactMinimum = 1024      // Never compact a file with fewer records
+	maxRecordSize  = 64 * 1024 // Larger lengths mean the file is corrupt
+)
+
+// ErrNoCacheDir is returned by DefaultDir when no cache location is known.
+var ErrNoCacheDir = errors.New("no cache directory: set XDG_CACHE_HOME or use --cache-dir")
+
+// DefaultDir returns the chexum directory inside the user's cache directory:
+// $XDG_CACHE_HOME/chexum, falling back to ~/.cache/chexum on Unix systems.
+func DefaultDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", ErrNoCacheDir
+	}
+	return filepath.Join(base, "chexum"), nil
+}
+
+// inode is the part of a hash.FileID that names a file regardless of its
+// content; a newer record for the same inode supersedes older ones.
+type inode struct {
+	device, number uint64
+}
+
+// record is the latest known version of one file.
+type record struct {
+	id      hash.FileID
+	digests map[string]string
+	seq     uint64 // Position of the last store, for keeping the newest on compaction
+}
+
+// Cache is a persistent hash.Cache stored in one directory. It is safe for
+// concurrent use; Close must be called to write out pending records.
+type Cache struct {
+	dir        string
+	MinAge     time.Duration // Minimum age of a file's last change before it is cached
+	MaxEntries int           // Files kept by compaction
+
+	mu       sync.Mutex
+	entries  map[inode]*record
+	stored   map[inode]bool // Files whose records were stored by this process
+	seq      uint64
+	records  int    // Records in the data file, including superseded ones
+	pending  []byte // Encoded records not yet appended
+	rewrite  bool   // The data file is damaged or outdated and must be replaced
+	hits     int
+	lookups  int
+	storeErr error
+}
+
+// Stats summarises cache use during one run.
+type Stats struct {
+	Lookups int // Files looked up
+	Hits    int // Lookups answered from the cache
+	Entries int // Files remembered
+}
+
+// Open loads the cache in dir, creating the directory if needed. A missing
+// data file is an empty cache; a damaged one yields whatever records precede
+// the damage and is rewritten on Close.
+func Open(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("cannot create cache directory: %w", err)
+	}
+	c := &Cache{
+		dir:        dir,
+		MinAge:     DefaultMinAge,
+		MaxEntries: DefaultMaxEntries,
+		entries:    make(map[inode]*record),
+		stored:     make(map[inode]bool),
+	}
+
+	unlock, err := lock(filepath.Join(dir, lockFile), false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Dir returns the directory holding the cache.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Lookup implements hash.Cache.
+func (c *Cache) Lookup(id hash.FileID) (map[string]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lookups++
+	rec, ok := c.entries[inode{id.Device, id.Inode}]
+	if !ok || rec.id != id {
+		return nil, false
+	}
+	c.hits++
+	digests := make(map[string]string, len(rec.digests))
+	for alg, digest := range rec.digests {
+		digests[alg] = digest
+	}
+	return digests, true
+}
+
+// Store implements hash.Cache. Digests of files changed within MinAge are
+// not stored, and nor is anything already known.
+func (c *Cache) Store(id hash.FileID, digests map[string]string) {
+	if c.tooRecent(id) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := inode{id.Device, id.Inode}
+	rec, ok := c.entries[key]
+	if !ok || rec.id != id {
+		rec = &record{id: id, digests: make(map[string]string, len(digests))}
+		c.entries[key] = rec
+	}
+	added := make(map[string]string, len(digests))
+	for alg, digest := range digests {
+		if rec.digests[alg] != digest {
+			rec.digests[alg] = digest
+			added[alg] = digest
+		}
+	}
+	if len(added) == 0 {
+		return
+	}
+	c.seq++
+	rec.seq = c.seq
+	c.stored[key] = true
+	c.pending = appendRecord(c.pending, id, added)
+	c.records++
+	if len(c.pending) >= flushBytes {
+		c.storeErr = errors.Join(c.storeErr, c.flush())
+	}
+}
+
+// tooRecent reports whether id changed within MinAge of now.
+func (c *Cache) tooRecent(id hash.FileID) bool {
+	last := id.ModTime
+	if id.ChangeTime > last {
+		last = id.ChangeTime
+	}
+	return time.Since(time.Unix(0, last)) < c.MinAge
+}
+
+// Stats returns how the cache has been used since it was opened.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Stats{Lookups: c.lookups, Hits: c.hits, Entries: len(c.entries)}
+}
+
+// Close appends pending records and compacts the data file when most of it
+// is superseded records, when it holds more than MaxEntries files, or when
+// it was damaged. The cache must not be used afterwards.
+func (c *Cache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	err := c.storeErr
+	if c.rewrite || len(c.entries) > c.MaxEntries ||
+		(c.records >= compactMinimum && c.records > 2*len(c.entries)) {
+		return errors.Join(err, c.compact())
+	}
+	return errors.Join(err, c.flush())
+}
+
+// flush appends pending records to the data file under the exclusive lock.
+// Each append is a single write to a file opened with O_APPEND, so records
+// from concurrent processes never interleave.
+func (c *Cache) flush() error {
+	if len(c.pending) == 0 {
+		return nil
+	}
+	if c.rewrite {
+		return c.compact()
+	}
+	unlock, err := lock(filepath.Join(c.dir, lockFile), true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f, err := os.OpenFile(filepath.Join(c.dir, DataFile), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	data := c.pending
+	if info, err := f.Stat(); err == nil && info.Size() == 0 {
+		data = append([]byte(magic), data...)
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	c.pending = c.pending[:0]
+	return err
+}
+
+// compact rewrites the data file with one record per live file. It first
+// re-reads the file under the exclusive lock, so records appended by other
+// processes since Open are kept, then applies this process's records on top.
+func (c *Cache) compact() error {
+	unlock, err := lock(filepath.Join(c.dir, lockFile), true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	ours := c.entries
+	c.entries = make(map[inode]*record, len(ours))
+	c.seq, c.records, c.rewrite = 0, 0, false
+	if err := c.load(); err != nil {
+		return err
+	}
+	// Records stored by this process are the newest; apply them in the
+	// order they were stored. Records it merely loaded are already on disk
+	// unless another process has since superseded them.
+	mine := make([]*record, 0, len(c.stored))
+	for key := range c.stored {
+		mine = append(mine, ours[key])
+	}
+	sort.Slice(mine, func(i, j int) bool { return mine[i].seq < mine[j].seq })
+	for _, rec := range mine {
+		key := inode{rec.id.Device, rec.id.Inode}
+		if cur, ok := c.entries[key]; ok && cur.id == rec.id {
+			for alg, digest := range rec.digests {
+				cur.digests[alg] = digest
+			}
+			rec = cur
+		}
+		c.seq++
+		rec.seq = c.seq
+		c.entries[key] = rec
+	}
+	c.pending = c.pending[:0]
+
+	live := make([]*record, 0, len(c.entries))
+	for _, rec := range c.entries {
+		live = append(live, rec)
+	}
+	sort.Slice(live, func(i, j int) bool { return live[i].seq < live[j].seq })
+	if len(live) > c.MaxEntries {
+		for _, rec := range live[:len(live)-c.MaxEntries] {
+			delete(c.entries, inode{rec.id.Device, rec.id.Inode})
+		}
+		live = live[len(live)-c.MaxEntries:]
+	}
+
+	data := []byte(magic)
+	for _, rec := range live {
+		data = appendRecord(data, rec.id, rec.digests)
+	}
+	c.records = len(live)
+	return writeAtomic(filepath.Join(c.dir, DataFile), data)
+}
+
+// writeAtomic replaces path with data via a temporary file and a rename, so
+// readers see either the old file or the new one.
+func writeAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// load reads the data file into c.entries. Reading stops at the first
+// damaged record; the cache is then marked for rewriting.
+func (c *Cache) load() error {
+	f, err := os.Open(filepath.Join(c.dir, DataFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot read cache: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	header := make([]byte, len(magic))
+	if _, err := io.ReadFull(r, header); err != nil || string(header) != magic {
+		c.rewrite = true
+		return nil
+	}
+	for {
+		id, digests, err := readRecord(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			c.rewrite = true
+			return nil
+		}
+		c.records++
+		c.seq++
+		key := inode{id.Device, id.Inode}
+		rec, ok := c.entries[key]
+		if !ok || rec.id != id {
+			rec = &record{id: id, digests: digests}
+			c.entries[key] = rec
+		} else {
+			for alg, digest := range digests {
+				rec.digests[alg] = digest
+			}
+		}
+		rec.seq = c.seq
+	}
+}
+
+// Record layout, all integers big-endian:
+//
+//	uint32 length of body
+//	body:  device, inode, size, mtime, ctime (8 bytes each)
+//	       uint8 count, then per digest: uint8 name length, name,
+//	       uint8 digest length, raw digest bytes
+//	uint32 CRC-32C of body
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// appendRecord encodes one record onto buf. Digests that are not hex (there
+// are none today) are skipped rather than stored in a form lookups would
+// return differently.
+func appendRecord(buf []byte, id hash.FileID, digests map[string]string) []byte {
+	algs := make([]string, 0, len(digests))
+	for alg := range digests {
+		algs = append(algs, alg)
+	}
+	sort.Strings(algs)
+
+	var body bytes.Buffer
+	var fixed [40]byte
+	binary.BigEndian.PutUint64(fixed[0:], id.Device)
+	binary.BigEndian.PutUint64(fixed[8:], id.Inode)
+	binary.BigEndian.PutUint64(fixed[16:], uint64(id.Size))
+	binary.BigEndian.PutUint64(fixed[24:], uint64(id.ModTime))
+	binary.BigEndian.PutUint64(fixed[32:], uint64(id.ChangeTime))
+	body.Write(fixed[:])
+
+	var encoded [][2][]byte
+	for _, alg := range algs {
+		raw, err := hex.DecodeString(digests[alg])
+		if err != nil || len(alg) > 255 || len(raw) > 255 {
+			continue
+		}
+		encoded = append(encoded, [2][]byte{[]byte(alg), raw})
+	}
+	body.WriteByte(byte(len(encoded)))
+	for _, e := range encoded {
+		body.WriteByte(byte(len(e[0])))
+		body.Write(e[0])
+		body.WriteByte(byte(len(e[1])))
+		body.Write(e[1])
+	}
+
+	buf = binary.BigEndian.AppendUint32(buf, uint32(body.Len()))
+	buf = append(buf, body.Bytes()...)
+	return binary.BigEndian.AppendUint32(buf, crc32.Checksum(body.Bytes(), crcTable))
+}
+
+// errDamaged reports a record that is truncated or fails its checksum.
+var errDamaged = errors.New("damaged cache record")
+
+// readRecord decodes the next record from r. It returns io.EOF at a clean
+// end of file and errDamaged for anything else that is not a valid record.
+func readRecord(r *bufio.Reader) (hash.FileID, map[string]string, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		if err == io.EOF {
+			return hash.FileID{}, nil, io.EOF
+		}
+		return hash.FileID{}, nil, errDamaged
+	}
+	n := binary.BigEndian.Uint32(length[:])
+	if n < 41 || n > maxRecordSize {
+		return hash.FileID{}, nil, errDamaged
+	}
+	body := make([]byte, n+4)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return hash.FileID{}, nil, errDamaged
+	}
+	body, sum := body[:n], binary.BigEndian.Uint32(body[n:])
+	if crc32.Checksum(body, crcTable) != sum {
+		return hash.FileID{}, nil, errDamaged
+	}
+
+	id := hash.FileID{
+		Device:     binary.BigEndian.Uint64(body[0:]),
+		Inode:      binary.BigEndian.Uint64(body[8:]),
+		Size:       int64(binary.BigEndian.Uint64(body[16:])),
+		ModTime:    int64(binary.BigEndian.Uint64(body[24:])),
+		ChangeTime: int64(binary.BigEndian.Uint64(body[32:])),
+	}
+	count, rest := int(body[40]), body[41:]
+	digests := make(map[string]string, count)
+	for i := 0; i < count; i++ {
+		var alg, raw []byte
+		var ok bool
+		if alg, rest, ok = field(rest); !ok {
+			return hash.FileID{}, nil, errDamaged
+		}
+		if raw, rest, ok = field(rest); !ok {
+			return hash.FileID{}, nil, errDamaged
+		}
+		digests[string(alg)] = hex.EncodeToString(raw)
+	}
+	return id, digests, nil
+}
+
+// field splits a length-prefixed field off the front of b.
+func field(b []byte) (value, rest []byte, ok bool) {
+	if len(b) < 1 || len(b) < 1+int(b[0]) {
+		return nil, nil, false
+	}
+	return b[1 : 1+int(b[0])], b[1+int(b[0]):], true
+}
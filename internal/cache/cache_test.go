@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Les-El/chexum/internal/hash"
+)
+
+// past is a change time well outside DefaultMinAge.
+var past = time.Now().Add(-time.Hour).UnixNano()
+
+// oldID is a file identity whose last change is well in the past.
+func oldID(inode uint64) hash.FileID {
+	return hash.FileID{Device: 1, Inode: inode, Size: 100, ModTime: past, ChangeTime: past}
+}
+
+func TestCache_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	c, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	id := oldID(7)
+	c.Store(id, map[string]string{hash.AlgorithmSHA256: "abcd"})
+	c.Store(id, map[string]string{hash.AlgorithmMD5: "0123"})
+
+	recent := oldID(8)
+	recent.ChangeTime = time.Now().UnixNano()
+	c.Store(recent, map[string]string{hash.AlgorithmSHA256: "abcd"})
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	c, err = Open(dir)
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	defer c.Close()
+	got, ok := c.Lookup(id)
+	if !ok || got[hash.AlgorithmSHA256] != "abcd" || got[hash.AlgorithmMD5] != "0123" {
+		t.Errorf("Lookup() = %v, %v", got, ok)
+	}
+
+	tests := []struct {
+		name   string
+		change func(*hash.FileID)
+	}{
+		{"ctime", func(id *hash.FileID) { id.ChangeTime++ }},
+		{"mtime", func(id *hash.FileID) { id.ModTime++ }},
+		{"size", func(id *hash.FileID) { id.Size++ }},
+		{"device", func(id *hash.FileID) { id.Device++ }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := id
+			tt.change(&changed)
+			if _, ok := c.Lookup(changed); ok {
+				t.Errorf("a changed %s must miss", tt.name)
+			}
+		})
+	}
+	if _, ok := c.Lookup(recent); ok {
+		t.Error("a file changed within MinAge must not be cached")
+	}
+	if s := c.Stats(); s.Hits != 1 || s.Lookups != 6 {
+		t.Errorf("Stats() = %+v", s)
+	}
+}
+
+func TestCache_DamagedTail(t *testing.T) {
+	dir := t.TempDir()
+	c, _ := Open(dir)
+	c.Store(oldID(1), map[string]string{hash.AlgorithmSHA256: "aa"})
+	c.Close()
+
+	path := filepath.Join(dir, DataFile)
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	f.Write([]byte{0, 0, 0, 60, 1, 2, 3}) // A torn record
+	f.Close()
+
+	c, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if _, ok := c.Lookup(oldID(1)); !ok {
+		t.Error("records before the damage should survive")
+	}
+	if !c.rewrite {
+		t.Error("damage should mark the file for rewriting")
+	}
+	c.Close()
+
+	c, _ = Open(dir)
+	defer c.Close()
+	if c.rewrite || c.records != 1 {
+		t.Errorf("Close() should rewrite a clean file: rewrite %v, records %d", c.rewrite, c.records)
+	}
+}
+
+func TestCache_ConcurrentProcesses(t *testing.T) {
+	dir := t.TempDir()
+	a, _ := Open(dir)
+	b, _ := Open(dir)
+	a.Store(oldID(1), map[string]string{hash.AlgorithmSHA256: "aa"})
+	b.Store(oldID(2), map[string]string{hash.AlgorithmSHA256: "bb"})
+	// b compacts while a still has records pending; neither may be lost.
+	b.rewrite = true
+	if err := b.Close(); err != nil {
+		t.Fatalf("b.Close() error = %v", err)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatalf("a.Close() error = %v", err)
+	}
+
+	c, _ := Open(dir)
+	defer c.Close()
+	for _, inode := range []uint64{1, 2} {
+		if _, ok := c.Lookup(oldID(inode)); !ok {
+			t.Errorf("record for inode %d lost", inode)
+		}
+	}
+}
+
+func TestCache_Compaction(t *testing.T) {
+	dir := t.TempDir()
+	c, _ := Open(dir)
+	c.MaxEntries = 2
+	for inode := uint64(1); inode <= 3; inode++ {
+		c.Store(oldID(inode), map[string]string{hash.AlgorithmSHA256: "aa"})
+	}
+	// A new version of inode 3 supersedes the old record.
+	newer := oldID(3)
+	newer.Size++
+	c.Store(newer, map[string]string{hash.AlgorithmSHA256: "bb"})
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	c, _ = Open(dir)
+	defer c.Close()
+	if c.records != 2 {
+		t.Errorf("compacted file has %d records, want 2", c.records)
+	}
+	if _, ok := c.Lookup(oldID(1)); ok {
+		t.Error("the oldest entry should be dropped beyond MaxEntries")
+	}
+	if got, ok := c.Lookup(newer); !ok || got[hash.AlgorithmSHA256] != "bb" {
+		t.Errorf("Lookup(newer) = %v, %v", got, ok)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package cache
+
+// lock is a no-op where flock is unavailable. hash.FileIDOf reports no
+// identities on these platforms, so the cache is never written there.
+func lock(path string, exclusive bool) (func(), error) {
+	return func() {}, nil
+}
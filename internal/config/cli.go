@@ -3,9 +3,12 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/Les-El/chexum/internal/conflict"
+	"github.com/Les-El/chexum/internal/governor"
+	"github.com/Les-El/chexum/internal/hash"
 	"github.com/spf13/pflag"
 )
 
@@ -74,10 +77,23 @@ func ParseArgs(args []string) (*Config, []conflict.Warning, error) {
 	return finalizeConfig(cfg, args, fs)
 }
 
+// flagAliases maps alternative spellings of flags to their names.
+var flagAliases = map[string]string{
+	"xdev": "one-file-system", // find's spelling
+}
+
+// normalizeFlagName resolves flagAliases for the flag set.
+func normalizeFlagName(_ *pflag.FlagSet, name string) pflag.NormalizedName {
+	if alias, ok := flagAliases[name]; ok {
+		name = alias
+	}
+	return pflag.NormalizedName(name)
+}
+
 func defineFlags(flagSet *pflag.FlagSet, cfg *Config) {
 	flagSet.BoolVarP(&cfg.Recursive, "recursive", "r", false, "Process directories recursively")
 	flagSet.BoolVarP(&cfg.Hidden, "hidden", "H", false, "Include hidden files")
-	flagSet.StringVarP(&cfg.Algorithm, "algorithm", "a", "sha256", "Hash algorithm")
+	flagSet.StringVarP(&cfg.Algorithm, "algorithm", "a", "sha256", "Hash algorithm (comma-separated for several)")
 	flagSet.BoolVar(&cfg.DryRun, "dry-run", false, "Preview files without hashing")
 	flagSet.BoolVarP(&cfg.Verbose, "verbose", "v", false, "Enable verbose output")
 	flagSet.BoolVarP(&cfg.Quiet, "quiet", "q", false, "Suppress stdout")
@@ -103,24 +119,100 @@ func defineFlags(flagSet *pflag.FlagSet, cfg *Config) {
 
 	flagSet.StringSliceVarP(&cfg.Include, "include", "i", nil, "Glob patterns to include")
 	flagSet.StringSliceVarP(&cfg.Exclude, "exclude", "e", nil, "Glob patterns to exclude")
+	flagSet.BoolVar(&cfg.NoGitIgnore, "no-gitignore", false, "Do not read .gitignore or .git/info/exclude")
+	flagSet.VarPF(invertedBool{&cfg.NoGitIgnore}, "gitignore", "", "Read .gitignore and .git/info/exclude").NoOptDefVal = "true"
+	_ = flagSet.MarkDeprecated("gitignore", "it is the default; use --no-gitignore to turn it off")
+	flagSet.BoolVar(&cfg.NoIgnore, "no-ignore", false, "Do not read .chexumignore or .gitignore files")
+	flagSet.IntVar(&cfg.MaxDepth, "max-depth", 0, "Descend at most this many levels below each argument (implies -r)")
+	flagSet.BoolVarP(&cfg.OneFileSystem, "one-file-system", "x", false, "Do not descend into other file systems")
+	flagSet.BoolVar(&cfg.FollowSymlinks, "follow-symlinks", false, "Follow symbolic links found while walking")
+	flagSet.SetNormalizeFunc(normalizeFlagName)
 
 	flagSet.StringVar(&cfg.Manifest, "manifest", "", "Baseline manifest for incremental ops")
 	flagSet.BoolVar(&cfg.OnlyChanged, "only-changed", false, "Only process files changed from manifest")
 	flagSet.StringVar(&cfg.OutputManifest, "output-manifest", "", "Save results as a manifest")
 
+	flagSet.BoolVar(&cfg.StdinPaths, "stdin-paths", false, "Read file paths from stdin, one per line")
+	flagSet.StringVar(&cfg.FilesFrom, "files-from", "", "Read file paths from this file (- for stdin)")
+	flagSet.BoolVarP(&cfg.Null, "null", "0", false, "Path lists are NUL-separated, as from find -print0 (implies --stdin-paths)")
+	flagSet.BoolVar(&cfg.Print0, "print0", false, "Plain output with each record ended by NUL, for xargs -0")
+	flagSet.StringVar(&cfg.Encoding, "encoding", hash.EncodingHex, "Digest encoding: "+strings.Join(hash.Encodings, ", "))
+	flagSet.StringVar(&cfg.KeyFile, "key-file", "", "File holding the key for keyed algorithms (hmac-*, blake2b-keyed)")
+	flagSet.StringVar(&cfg.KeyEnv, "key-env", "", "Environment variable holding the key for keyed algorithms")
+	flagSet.BoolVar(&cfg.Passthrough, "passthrough", false, "Copy stdin to stdout while hashing it; report on stderr")
+	flagSet.StringVar(&cfg.Expect, "expect", "", "With --passthrough, the hash the data must match")
+
+	flagSet.BoolVar(&cfg.Archives, "archives", false, "Also hash members of tar, tar.gz and zip archives")
+	flagSet.IntVar(&cfg.ArchiveMaxMembers, "archive-max-members", 10000, "Maximum members hashed per archive")
+	flagSet.String("archive-max-size", "16GB", "Maximum uncompressed bytes read per archive")
+
+	flagSet.BoolVar(&cfg.Tree, "tree", false, "Print a single Merkle digest for each directory")
+	flagSet.BoolVar(&cfg.TreeBreakdown, "tree-breakdown", false, "With --tree, also print every subdirectory digest")
+
+	flagSet.BoolVar(&cfg.Duplicates, "duplicates", false, "Find duplicate files, hashing only files that share a size")
+
+	flagSet.BoolVar(&cfg.Check, "check", false, "Verify the files listed in sha256sum-style or BSD tag checksum files")
+	flagSet.BoolVar(&cfg.Status, "status", false, "With --check, print nothing; report only through the exit status")
+	flagSet.BoolVar(&cfg.Strict, "strict", false, "With --check, fail on improperly formatted checksum lines")
+	flagSet.BoolVar(&cfg.IgnoreMissing, "ignore-missing", false, "With --check, do not fail or report for missing files")
+
+	flagSet.BoolVar(&cfg.NoCache, "no-cache", false, "Do not use or update the persistent hash cache")
+	flagSet.StringVar(&cfg.CacheDir, "cache-dir", "", "Directory for the hash cache (default $XDG_CACHE_HOME/chexum)")
+
+	flagSet.String("max-rate", "", "Cap on bytes read per second by all workers, e.g. 50MB/s")
+	flagSet.IntVar(&cfg.Nice, "nice", 0, "Lower chexum's CPU priority by this much (0-19, Linux)")
+	flagSet.BoolVar(&cfg.IdleIO, "idle-io", false, "Read from disk only when no other process is waiting (Linux)")
+	flagSet.String("buffer-size", "", "Bytes each worker reads from a file at a time, e.g. 1MB")
+
 	// Add placeholders for string-based filters that need parsing
 	flagSet.String("min-size", "0", "Minimum file size")
 	flagSet.String("max-size", "-1", "Maximum file size")
 	flagSet.String("modified-after", "", "Date")
 	flagSet.String("modified-before", "", "Date")
+	flagSet.String("modified-within", "", "Only files modified within this long, e.g. 7d")
+
+	// Discovery predicates, parsed by parsePredicateFlags
+	flagSet.StringArray("regex", nil, "Regular expression the path below each argument must match")
+	flagSet.StringSlice("type", nil, "Content types to select, e.g. image/*, elf, pdf")
+	flagSet.StringSlice("owner", nil, "Only files owned by these users (names or IDs)")
+	flagSet.StringSlice("group", nil, "Only files belonging to these groups (names or IDs)")
+	flagSet.String("perm", "", "Permission bits: 644 exactly, -111 all of, /022 any of")
+	flagSet.String("changed-after", "", "Date")
+	flagSet.String("changed-before", "", "Date")
+	flagSet.String("changed-within", "", "Only files whose inode changed within this long")
+	flagSet.String("accessed-after", "", "Date")
+	flagSet.String("accessed-before", "", "Date")
+	flagSet.String("accessed-within", "", "Only files accessed within this long")
 
 	flagSet.StringVarP(&cfg.ConfigFile, "config", "c", "", "Path to config file")
-	flagSet.IntVarP(&cfg.Jobs, "jobs", "j", 0, "Number of parallel jobs (0 = auto)")
+	flagSet.IntVarP(&cfg.Jobs, "jobs", "j", 0, "Number of parallel jobs (default auto; 0 = adapt to measured throughput)")
 	flagSet.BoolVar(&cfg.Test, "test", false, "Run system diagnostics")
 	flagSet.BoolVarP(&cfg.ShowHelp, "help", "h", false, "Show help")
 	flagSet.BoolVarP(&cfg.ShowVersion, "version", "V", false, "Show version")
 }
 
+// invertedBool is a bool flag that stores the opposite of its value, so a
+// deprecated positive spelling can share the field of its --no- form.
+type invertedBool struct{ target *bool }
+
+func (b invertedBool) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*b.target = !v
+	return nil
+}
+
+func (b invertedBool) String() string {
+	if b.target == nil {
+		return "true"
+	}
+	return strconv.FormatBool(!*b.target)
+}
+
+func (b invertedBool) Type() string { return "bool" }
+
 func parseFlags(fs *pflag.FlagSet, args []string) error {
 	if err := fs.Parse(args); err != nil {
 		errMsg := err.Error()
@@ -147,20 +239,34 @@ func validateBasicFlags(cfg *Config, fs *pflag.FlagSet) error {
 			return fmt.Errorf("invalid --max-size: %w", err)
 		}
 	}
-	if afterStr, _ := fs.GetString("modified-after"); afterStr != "" {
-		if cfg.ModifiedAfter, err = parseDate(afterStr); err != nil {
-			return fmt.Errorf("invalid --modified-after: %w", err)
+	if err := parsePredicateFlags(cfg, fs); err != nil {
+		return err
+	}
+
+	if sizeStr, _ := fs.GetString("archive-max-size"); sizeStr != "" {
+		if cfg.ArchiveMaxSize, err = parseSize(sizeStr); err != nil {
+			return fmt.Errorf("invalid --archive-max-size: %w", err)
+		}
+	}
+
+	if rateStr, _ := fs.GetString("max-rate"); rateStr != "" {
+		if cfg.MaxRate, err = parseRate(rateStr); err != nil {
+			return fmt.Errorf("invalid --max-rate: %w", err)
 		}
 	}
-	if beforeStr, _ := fs.GetString("modified-before"); beforeStr != "" {
-		if cfg.ModifiedBefore, err = parseDate(beforeStr); err != nil {
-			return fmt.Errorf("invalid --modified-before: %w", err)
+
+	if bufStr, _ := fs.GetString("buffer-size"); bufStr != "" {
+		if cfg.BufferSize, err = parseSize(bufStr); err != nil {
+			return fmt.Errorf("invalid --buffer-size: %w", err)
 		}
 	}
 
 	if cfg.Jobs < 0 {
 		return fmt.Errorf("number of jobs cannot be negative")
 	}
+	// Adaptive tuning is opt-in: without --jobs the Neighborhood Policy's
+	// worker count is used as it is.
+	cfg.AdaptiveJobs = fs.Changed("jobs") && cfg.Jobs == 0
 
 	return nil
 }
@@ -172,6 +278,12 @@ func handleArguments(cfg *Config, fs *pflag.FlagSet) error {
 			return &ConfigCommandError{}
 		}
 	}
+	remainingArgs = expandGlobArgs(remainingArgs)
+	if cfg.Check {
+		// The arguments are checksum files, never hashes to compare.
+		cfg.Files = remainingArgs
+		return nil
+	}
 
 	if len(remainingArgs) > 0 && allArgsAreNonExistentFiles(remainingArgs) {
 		hasHashLikeArgs := false
@@ -284,8 +396,41 @@ func applyResolvedState(cfg *Config, state *conflict.RunState) {
 	}
 }
 
+// expandGlobArgs expands arguments containing wildcards, including "**",
+// so a quoted pattern such as 'build/**/*.so' selects the same files
+// whatever the shell does with it. An argument that exists as a path, or
+// that matches nothing, is kept as given and classified as usual.
+func expandGlobArgs(args []string) []string {
+	expanded := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "-" || !hash.HasGlobMeta(arg) {
+			expanded = append(expanded, arg)
+			continue
+		}
+		if _, err := os.Lstat(arg); err == nil {
+			expanded = append(expanded, arg)
+			continue
+		}
+		if matches := hash.ExpandGlob(arg); len(matches) > 0 {
+			expanded = append(expanded, matches...)
+		} else {
+			expanded = append(expanded, arg)
+		}
+	}
+	return expanded
+}
+
 // ClassifyArguments separates arguments into file paths, hash strings, and unknowns.
+//
+// algorithm may be a comma-separated list; a hash string is accepted if it
+// could be a digest of any of the requested algorithms. Hash strings may be
+// written in any encoding hash.DecodeDigest recognises (hex, base64, SRI,
+// ...); they are returned as lowercase hex so matching is encoding-agnostic.
 func ClassifyArguments(args []string, algorithm string) (files []string, hashes []string, unknowns []string, err error) {
+	var requested []string
+	for _, name := range strings.Split(algorithm, ",") {
+		requested = append(requested, hash.CanonicalAlgorithm(strings.TrimSpace(name)))
+	}
 	for _, arg := range args {
 		if arg == "" {
 			continue
@@ -298,21 +443,21 @@ func ClassifyArguments(args []string, algorithm string) (files []string, hashes
 			files = append(files, arg)
 			continue
 		}
-		detectedAlgorithms := detectHashAlgorithm(arg)
-		if len(detectedAlgorithms) == 0 {
+		digest, ok := hash.DecodeDigest(arg)
+		if !ok {
 			// If it's not a file and not a valid hash length, it's an unknown string.
 			unknowns = append(unknowns, arg)
 			continue
 		}
 		currentAlgorithmFound := false
-		for _, detected := range detectedAlgorithms {
-			if detected == algorithm {
+		for _, alg := range requested {
+			if digest.Fits(alg) {
 				currentAlgorithmFound = true
 				break
 			}
 		}
 		if currentAlgorithmFound {
-			hashes = append(hashes, strings.ToLower(arg))
+			hashes = append(hashes, digest.Hex)
 		} else {
 			// If it looks like a hash but doesn't match the current algorithm,
 			// we could treat it as unknown or error.
@@ -327,48 +472,14 @@ func ClassifyArguments(args []string, algorithm string) (files []string, hashes
 }
 
 func detectHashAlgorithm(hashStr string) []string {
-	if !isValidHexString(hashStr) {
-		return []string{}
-	}
-	switch len(hashStr) {
-	case 32:
-		return []string{"md5"}
-	case 40:
-		return []string{"sha1"}
-	case 64:
-		return []string{"sha256"}
-	case 128:
-		return []string{"sha512", "blake2b"}
-	default:
-		return []string{}
-	}
-}
-
-func isValidHexString(s string) bool {
-	if len(s) == 0 {
-		return false
-	}
-	for _, c := range s {
-		if !((c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')) {
-			return false
-		}
-	}
-	return true
+	return hash.DetectHashAlgorithm(hashStr)
 }
 
 func getExpectedLength(algorithm string) int {
-	switch algorithm {
-	case "md5":
-		return 32
-	case "sha1":
-		return 40
-	case "sha256":
-		return 64
-	case "sha512", "blake2b":
-		return 128
-	default:
-		return 64
+	if spec, ok := hash.LookupAlgorithm(algorithm); ok {
+		return spec.HexLength()
 	}
+	return 64
 }
 
 func allArgsAreNonExistentFiles(args []string) bool {
@@ -390,6 +501,10 @@ func looksLikeHashString(s string) bool {
 	if len(s) == 0 {
 		return false
 	}
+	// Encoded digests (base64, SRI) may contain '/', which otherwise marks a path.
+	if _, ok := hash.DecodeDigest(s); ok {
+		return true
+	}
 	if len(s) < 4 || len(s) > 256 {
 		return false
 	}
@@ -405,7 +520,22 @@ func looksLikeHashString(s string) bool {
 	return true
 }
 
+// AlgorithmList returns every requested algorithm, primary first.
+// It falls back to Algorithm for configs that were built without parsing.
+func (c *Config) AlgorithmList() []string {
+	if len(c.Algorithms) > 0 {
+		return c.Algorithms
+	}
+	return []string{c.Algorithm}
+}
+
+// ResourceLimits returns the --max-rate, --nice and --idle-io settings.
+func (c *Config) ResourceLimits() governor.Limits {
+	return governor.Limits{MaxRate: c.MaxRate, Nice: c.Nice, IdleIO: c.IdleIO}
+}
+
 // HasStdinMarker checks if the special "-" argument is present in the file list.
+// "-" means "hash the data on stdin"; --stdin-paths reads a path list instead.
 func (c *Config) HasStdinMarker() bool {
 	for _, file := range c.Files {
 		if file == "-" {
@@ -1,11 +1,15 @@
 package config
 
 import (
+	"io/fs"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"testing/quick"
+	"time"
 
+	"github.com/Les-El/chexum/internal/hash"
 	"github.com/spf13/pflag"
 )
 
@@ -187,6 +191,35 @@ func TestParseArgs_Files(t *testing.T) {
 	}
 }
 
+// TestParseArgs_GlobArguments tests that quoted wildcard arguments are
+// expanded by chexum itself, "**" included.
+func TestParseArgs_GlobArguments(t *testing.T) {
+	dir := t.TempDir()
+	for _, rel := range []string{"a.so", "lib/b.so", "lib/c.txt"} {
+		path := filepath.Join(dir, rel)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("x"), 0644)
+	}
+
+	cfg, _, err := ParseArgs([]string{filepath.Join(dir, "**", "*.so")})
+	if err != nil {
+		t.Fatalf("ParseArgs() error = %v", err)
+	}
+	want := []string{filepath.Join(dir, "a.so"), filepath.Join(dir, "lib", "b.so")}
+	if strings.Join(cfg.Files, ",") != strings.Join(want, ",") {
+		t.Errorf("Files = %v, want %v", cfg.Files, want)
+	}
+
+	// A pattern that matches nothing is reported like any other bad argument.
+	cfg, _, err = ParseArgs([]string{filepath.Join(dir, "*.iso")})
+	if err != nil {
+		t.Fatalf("ParseArgs() error = %v", err)
+	}
+	if len(cfg.Files) != 0 || len(cfg.Unknowns) != 1 {
+		t.Errorf("Files = %v, Unknowns = %v, want the pattern as an unknown", cfg.Files, cfg.Unknowns)
+	}
+}
+
 // TestParseArgs_Algorithm tests algorithm flag parsing.
 func TestParseArgs_Algorithm(t *testing.T) {
 	tests := []struct {
@@ -216,14 +249,15 @@ func TestParseArgs_Algorithm(t *testing.T) {
 // TestParseArgs_Jobs tests the --jobs flag.
 func TestParseArgs_Jobs(t *testing.T) {
 	tests := []struct {
-		name string
-		args []string
-		want int
+		name     string
+		args     []string
+		want     int
+		adaptive bool
 	}{
-		{"default jobs", []string{}, 0},
-		{"short jobs", []string{"-j", "4"}, 4},
-		{"long jobs", []string{"--jobs=8"}, 8},
-		{"zero jobs", []string{"--jobs", "0"}, 0},
+		{"default jobs", []string{}, 0, false},
+		{"short jobs", []string{"-j", "4"}, 4, false},
+		{"long jobs", []string{"--jobs=8"}, 8, false},
+		{"zero jobs", []string{"--jobs", "0"}, 0, true},
 	}
 
 	for _, tt := range tests {
@@ -232,8 +266,8 @@ func TestParseArgs_Jobs(t *testing.T) {
 			if err != nil {
 				t.Fatalf("ParseArgs() error = %v", err)
 			}
-			if cfg.Jobs != tt.want {
-				t.Errorf("Jobs = %d, want %d", cfg.Jobs, tt.want)
+			if cfg.Jobs != tt.want || cfg.AdaptiveJobs != tt.adaptive {
+				t.Errorf("Jobs = %d, AdaptiveJobs = %v; want %d, %v", cfg.Jobs, cfg.AdaptiveJobs, tt.want, tt.adaptive)
 			}
 		})
 	}
@@ -431,6 +465,64 @@ func TestParseArgs_SizeUnits(t *testing.T) {
 	}
 }
 
+func TestParseArgs_ResourceLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    int64
+		wantErr bool
+	}{
+		{"per second", []string{"--max-rate=50MB/s"}, 50 * 1024 * 1024, false},
+		{"lower case suffix", []string{"--max-rate=1.5g/S"}, 3 * 512 * 1024 * 1024, false},
+		{"bare size", []string{"--max-rate=4096"}, 4096, false},
+		{"unlimited", []string{"--max-rate=0"}, 0, false},
+		{"negative", []string{"--max-rate=-1"}, 0, true},
+		{"garbage", []string{"--max-rate=fast"}, 0, true},
+		{"nice too high", []string{"--nice=20"}, 0, true},
+		{"nice negative", []string{"--nice=-5"}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, _, err := ParseArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseArgs(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if err == nil && cfg.MaxRate != tt.want {
+				t.Errorf("MaxRate = %d, want %d", cfg.MaxRate, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseArgs_BufferSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    int64
+		wantErr bool
+	}{
+		{"default", []string{}, 0, false},
+		{"one megabyte", []string{"--buffer-size=1MB"}, 1024 * 1024, false},
+		{"smallest", []string{"--buffer-size=4KB"}, 4096, false},
+		{"too small", []string{"--buffer-size=1KB"}, 0, true},
+		{"too large", []string{"--buffer-size=1GB"}, 0, true},
+		{"garbage", []string{"--buffer-size=big"}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, _, err := ParseArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseArgs(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if err == nil && cfg.BufferSize != tt.want {
+				t.Errorf("BufferSize = %d, want %d", cfg.BufferSize, tt.want)
+			}
+		})
+	}
+}
+
 func TestValidateConfig_Invalid(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -479,6 +571,96 @@ func TestParseArgs_DateParsing(t *testing.T) {
 	}
 }
 
+func TestParseArgs_Predicates(t *testing.T) {
+	cfg, _, err := ParseArgs([]string{
+		"--perm", "-111", "--type", "elf,image", "--regex", `^src/.*\.go$`, "--regex", "a,b",
+		"--owner", "0", "--modified-within", "7d", "--modified-after", "2000-01-01", "--accessed-before", "1d",
+	})
+	if err != nil {
+		t.Fatalf("ParseArgs() error = %v", err)
+	}
+	if cfg.Perm != (hash.PermFilter{Bits: 0111, Match: '-'}) {
+		t.Errorf("Perm = %+v", cfg.Perm)
+	}
+	if strings.Join(cfg.Types, ",") != "application/x-elf,image/*" {
+		t.Errorf("Types = %v", cfg.Types)
+	}
+	if len(cfg.PathRegex) != 2 || cfg.PathRegex[1].String() != "a,b" {
+		t.Errorf("PathRegex = %v", cfg.PathRegex)
+	}
+	if len(cfg.Owners) != 1 || cfg.Owners[0] != 0 {
+		t.Errorf("Owners = %v", cfg.Owners)
+	}
+	// The later of the two lower bounds wins.
+	if age := time.Since(cfg.ModifiedAfter); age < 7*24*time.Hour-time.Minute || age > 7*24*time.Hour+time.Minute {
+		t.Errorf("ModifiedAfter = %v, want 7 days ago", cfg.ModifiedAfter)
+	}
+	if age := time.Since(cfg.AccessedBefore); age < 23*time.Hour || age > 25*time.Hour {
+		t.Errorf("AccessedBefore = %v, want a day ago", cfg.AccessedBefore)
+	}
+
+	for _, args := range [][]string{
+		{"--perm", "9"},
+		{"--type", "spreadsheet"},
+		{"--regex", "("},
+		{"--modified-within", "2024-01-01"},
+		{"--owner", "no-such-user-here"},
+		{"--changed-after", "1d", "--changed-before", "2d"},
+	} {
+		if _, _, err := ParseArgs(args); err == nil {
+			t.Errorf("ParseArgs(%v) should fail", args)
+		}
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"7d", 7 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"1d12h", 36 * time.Hour, false},
+		{"90M", 90 * time.Minute, false},
+		{"30s", 30 * time.Second, false},
+		{"7", 0, true},
+		{"d", 0, true},
+		{"-3d", 0, true},
+		{"3y", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseAge(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseAge(%q) = %v, %v, want %v (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParsePerm(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    hash.PermFilter
+		wantErr bool
+	}{
+		{"644", hash.PermFilter{Bits: 0644, Match: '='}, false},
+		{"-111", hash.PermFilter{Bits: 0111, Match: '-'}, false},
+		{"/022", hash.PermFilter{Bits: 0022, Match: '/'}, false},
+		{"-4000", hash.PermFilter{Bits: fs.ModeSetuid, Match: '-'}, false},
+		{"1777", hash.PermFilter{Bits: fs.ModeSticky | 0777, Match: '='}, false},
+		{"u+x", hash.PermFilter{}, true},
+		{"17777", hash.PermFilter{}, true},
+		{"-", hash.PermFilter{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parsePerm(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parsePerm(%q) = %+v, %v, want %+v (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
 func TestParseArgs(t *testing.T) { TestParseArgs_Bool(t) }
 
 func TestNewCLIParser(t *testing.T) {
@@ -500,3 +682,18 @@ func TestCLIParser_Parse(t *testing.T) {
 		t.Error("expected verbose=true")
 	}
 }
+
+func TestParseArgs_Check(t *testing.T) {
+	hashLike := strings.Repeat("a", 64)
+	cfg, _, err := ParseArgs([]string{"--check", "--strict", "SHA256SUMS", hashLike})
+	if err != nil {
+		t.Fatalf("ParseArgs() error = %v", err)
+	}
+	// Arguments are checksum files, even when they look like hashes.
+	if strings.Join(cfg.Files, ",") != "SHA256SUMS,"+hashLike || len(cfg.Hashes) != 0 {
+		t.Errorf("Files = %v, Hashes = %v", cfg.Files, cfg.Hashes)
+	}
+	if !cfg.Strict || !cfg.NoCache {
+		t.Errorf("Strict = %v, NoCache = %v; want both set", cfg.Strict, cfg.NoCache)
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/spf13/pflag"
@@ -51,6 +52,103 @@ func TestLoadConfigFile(t *testing.T) {
 	}
 }
 
+func TestApplyConfigFile_IOLimits(t *testing.T) {
+	path := "test_config_io.toml"
+	os.WriteFile(path, []byte("[io]\n\"/mnt/archive\" = 1\n\"/\" = 8\n"), 0644)
+	defer os.Remove(path)
+
+	cf, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("LoadConfigFile() error = %v", err)
+	}
+	cfg := DefaultConfig()
+	if err := cf.ApplyConfigFile(cfg, pflag.NewFlagSet("test", pflag.ContinueOnError)); err != nil {
+		t.Fatalf("ApplyConfigFile() error = %v", err)
+	}
+	if cfg.IOLimits["/mnt/archive"] != 1 || cfg.IOLimits["/"] != 8 {
+		t.Errorf("IOLimits = %v", cfg.IOLimits)
+	}
+
+	cf.IO["/mnt/archive"] = 0
+	if err := cf.ApplyConfigFile(DefaultConfig(), pflag.NewFlagSet("test", pflag.ContinueOnError)); err == nil {
+		t.Error("a limit of 0 should be rejected")
+	}
+}
+
+func TestApplyConfigFile_Predicates(t *testing.T) {
+	path := "test_config_predicates.toml"
+	content := "[defaults]\ntype = [\"image\"]\nperm = \"/022\"\nregex = ['^docs/']\nchanged_within = \"2w\"\n"
+	os.WriteFile(path, []byte(content), 0644)
+	defer os.Remove(path)
+
+	cf, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("LoadConfigFile() error = %v", err)
+	}
+
+	// A flag on the command line takes precedence over its config key.
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	defineFlags(fs, &Config{})
+	fs.Parse([]string{"--perm", "644"})
+	cfg := DefaultConfig()
+	if err := cf.ApplyConfigFile(cfg, fs); err != nil {
+		t.Fatalf("ApplyConfigFile() error = %v", err)
+	}
+	if len(cfg.Types) != 1 || cfg.Types[0] != "image/*" {
+		t.Errorf("Types = %v", cfg.Types)
+	}
+	if len(cfg.PathRegex) != 1 || !cfg.PathRegex[0].MatchString("docs/a.md") {
+		t.Errorf("PathRegex = %v", cfg.PathRegex)
+	}
+	if cfg.ChangedAfter.IsZero() {
+		t.Error("changed_within was not applied")
+	}
+	if cfg.Perm.Match != 0 {
+		t.Errorf("Perm = %+v, want it left to the command line", cfg.Perm)
+	}
+
+	cf.Defaults.Perm = ptr("rwx")
+	err = cf.ApplyConfigFile(DefaultConfig(), pflag.NewFlagSet("test", pflag.ContinueOnError))
+	if err == nil || !strings.Contains(err.Error(), "perm") {
+		t.Errorf("invalid perm in config: error = %v", err)
+	}
+}
+
+func TestApplyConfigFile_GitIgnore(t *testing.T) {
+	tests := []struct {
+		name        string
+		gitIgnore   *bool
+		noGitIgnore *bool
+		args        []string
+		want        bool
+	}{
+		{"no keys", nil, nil, nil, false},
+		{"no_gitignore", nil, ptr(true), nil, true},
+		{"deprecated gitignore", ptr(false), nil, nil, true},
+		{"no_gitignore outranks gitignore", ptr(false), ptr(false), nil, false},
+		{"flag outranks keys", nil, ptr(true), []string{"--gitignore"}, false},
+		{"deprecated flag outranks keys", ptr(true), nil, []string{"--gitignore=false"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			defineFlags(fs, cfg)
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatalf("Parse(%v) error = %v", tt.args, err)
+			}
+			cf := &ConfigFile{}
+			cf.Defaults.GitIgnore, cf.Defaults.NoGitIgnore = tt.gitIgnore, tt.noGitIgnore
+			if err := cf.ApplyConfigFile(cfg, fs); err != nil {
+				t.Fatalf("ApplyConfigFile() error = %v", err)
+			}
+			if cfg.NoGitIgnore != tt.want {
+				t.Errorf("NoGitIgnore = %v, want %v", cfg.NoGitIgnore, tt.want)
+			}
+		})
+	}
+}
+
 func ptr[T any](v T) *T {
 	return &v
 }
@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -91,3 +93,202 @@ func TestValidateConfigDates(t *testing.T) {
 		t.Errorf("ValidateConfig() unexpected error for valid dates: %v", err)
 	}
 }
+
+func TestParseAlgorithmList(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{"sha256", []string{"sha256"}, false},
+		{"sha256,md5,sha1", []string{"sha256", "md5", "sha1"}, false},
+		{"sha-256, md5, sha256", []string{"sha256", "md5"}, false},
+		{"sha256,bogus", nil, true},
+		{",", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseAlgorithmList(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAlgorithmList(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ParseAlgorithmList(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateModes_PathLists(t *testing.T) {
+	tests := []struct {
+		name           string
+		null           bool
+		filesFrom      string
+		wantStdinPaths bool
+		wantFilesFrom  string
+	}{
+		{"null alone reads stdin", true, "", true, ""},
+		{"null with a list file", true, "list", false, "list"},
+		{"files from stdin", false, "-", true, ""},
+		{"files from a file", false, "list", false, "list"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Null, cfg.FilesFrom = tt.null, tt.filesFrom
+			if err := validateModes(cfg); err != nil {
+				t.Fatalf("validateModes() error = %v", err)
+			}
+			if cfg.StdinPaths != tt.wantStdinPaths || cfg.FilesFrom != tt.wantFilesFrom {
+				t.Errorf("StdinPaths, FilesFrom = %v, %q, want %v, %q", cfg.StdinPaths, cfg.FilesFrom, tt.wantStdinPaths, tt.wantFilesFrom)
+			}
+		})
+	}
+}
+
+func TestValidateModes(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(*Config)
+		wantErr bool
+	}{
+		{"plain hashing", func(c *Config) {}, false},
+		{"tree", func(c *Config) { c.Tree = true }, false},
+		{"tree with hashes", func(c *Config) { c.Tree = true; c.Hashes = []string{"abc"} }, true},
+		{"tree with bool", func(c *Config) { c.Tree = true; c.Bool = true }, true},
+		{"breakdown implies tree", func(c *Config) { c.TreeBreakdown = true; c.Bool = true }, true},
+		{"stdin data", func(c *Config) { c.Files = []string{"-", "a.txt"} }, false},
+		{"stdin twice", func(c *Config) { c.Files = []string{"-", "-"} }, true},
+		{"stdin data with stdin paths", func(c *Config) { c.Files = []string{"-"}; c.StdinPaths = true }, true},
+		{"tree of stdin", func(c *Config) { c.Tree = true; c.Files = []string{"-"} }, true},
+		{"passthrough", func(c *Config) { c.Passthrough = true; c.Files = []string{"-"} }, false},
+		{"passthrough with expect", func(c *Config) { c.Passthrough = true; c.Expect = strings.Repeat("a", 64) }, false},
+		{"expect wrong length", func(c *Config) { c.Passthrough = true; c.Expect = "abc" }, true},
+		{"expect without passthrough", func(c *Config) { c.Expect = strings.Repeat("a", 64) }, true},
+		{"passthrough with files", func(c *Config) { c.Passthrough = true; c.Files = []string{"a.txt"} }, true},
+		{"passthrough with bool", func(c *Config) { c.Passthrough = true; c.Bool = true }, true},
+		{"duplicates", func(c *Config) { c.Duplicates = true; c.Files = []string{"a.txt", "b.txt"} }, false},
+		{"duplicates with hashes", func(c *Config) { c.Duplicates = true; c.Hashes = []string{"abc"} }, true},
+		{"duplicates of stdin", func(c *Config) { c.Duplicates = true; c.Files = []string{"-"} }, true},
+		{"duplicates with manifest", func(c *Config) { c.Duplicates = true; c.OutputManifest = "out.json" }, true},
+		{"sampled", func(c *Config) { c.Algorithm = "sampled-sha256"; c.OutputManifest = "out.json" }, false},
+		{"sampled stdin", func(c *Config) { c.Algorithms = []string{"sha256", "sampled-xxh64"}; c.Files = []string{"-"} }, true},
+		{"sampled tree", func(c *Config) { c.Algorithm = "sampled-sha256"; c.Tree = true }, true},
+		{"sampled duplicates", func(c *Config) { c.Algorithm = "sampled-sha256"; c.Duplicates = true }, true},
+		{"null list on stdin", func(c *Config) { c.Null = true }, false},
+		{"null list with stdin data", func(c *Config) { c.Null = true; c.Files = []string{"-"} }, true},
+		{"files from stdin with stdin data", func(c *Config) { c.FilesFrom = "-"; c.Files = []string{"-"} }, true},
+		{"print0", func(c *Config) { c.Print0 = true }, false},
+		{"print0 with json", func(c *Config) { c.Print0 = true; c.OutputFormat = "json" }, true},
+		{"print0 with bool", func(c *Config) { c.Print0 = true; c.Bool = true }, true},
+		{"print0 with tree", func(c *Config) { c.Print0 = true; c.Tree = true }, true},
+		{"check", func(c *Config) { c.Check = true; c.Files = []string{"SHA256SUMS", "-"}; c.Strict = true }, false},
+		{"check with json", func(c *Config) { c.Check = true; c.OutputFormat = "json" }, true},
+		{"check with print0", func(c *Config) { c.Check = true; c.Print0 = true }, true},
+		{"check with bool", func(c *Config) { c.Check = true; c.Bool = true }, true},
+		{"check with duplicates", func(c *Config) { c.Check = true; c.Duplicates = true }, true},
+		{"check with stdin paths", func(c *Config) { c.Check = true; c.Null = true }, true},
+		{"check with keyed algorithm", func(c *Config) { c.Check = true; c.Algorithm = "hmac-sha256"; c.KeyEnv = "KEY" }, true},
+		{"status without check", func(c *Config) { c.Status = true }, true},
+		{"ignore missing without check", func(c *Config) { c.IgnoreMissing = true }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.mutate(cfg)
+			if err := validateModes(cfg); (err != nil) != tt.wantErr {
+				t.Errorf("validateModes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateKeySource(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(*Config)
+		wantErr bool
+	}{
+		{"unkeyed", func(c *Config) {}, false},
+		{"keyed with file", func(c *Config) { c.Algorithms = []string{"hmac-sha256"}; c.KeyFile = "key" }, false},
+		{"keyed with env", func(c *Config) { c.Algorithms = []string{"sha256", "blake2b-keyed"}; c.KeyEnv = "KEY" }, false},
+		{"keyed without key", func(c *Config) { c.Algorithms = []string{"hmac-sha512"} }, true},
+		{"both sources", func(c *Config) { c.Algorithms = []string{"hmac-sha256"}; c.KeyFile = "key"; c.KeyEnv = "KEY" }, true},
+		{"unused key", func(c *Config) { c.KeyEnv = "KEY" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.mutate(cfg)
+			if err := validateKeySource(cfg); (err != nil) != tt.wantErr {
+				t.Errorf("validateKeySource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateEncoding(t *testing.T) {
+	tests := []struct {
+		name       string
+		algorithms []string
+		encoding   string
+		wantErr    bool
+	}{
+		{"hex", []string{"md5"}, "hex", false},
+		{"empty defaults to hex", []string{"sha256"}, "", false},
+		{"base64 any algorithm", []string{"crc32c", "sha512"}, "base64", false},
+		{"sri sha256", []string{"sha256"}, "sri", false},
+		{"sri md5", []string{"sha256", "md5"}, "sri", true},
+		{"multihash blake3", []string{"blake3"}, "multihash", false},
+		{"unknown", []string{"sha256"}, "base85", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Algorithms = tt.algorithms
+			cfg.Encoding = tt.encoding
+			if err := validateEncoding(cfg); (err != nil) != tt.wantErr {
+				t.Errorf("validateEncoding() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key")
+	os.WriteFile(keyFile, []byte("s3cret\n"), 0600)
+	t.Setenv("CHEXUM_TEST_KEY", "from-env")
+	t.Setenv("CHEXUM_TEST_EMPTY", "")
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		want    string
+		wantErr bool
+	}{
+		{"file strips newline", Config{KeyFile: keyFile}, "s3cret", false},
+		{"env", Config{KeyEnv: "CHEXUM_TEST_KEY"}, "from-env", false},
+		{"empty env", Config{KeyEnv: "CHEXUM_TEST_EMPTY"}, "", true},
+		{"missing file", Config{KeyFile: keyFile + ".missing"}, "", true},
+		{"no source", Config{}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := LoadKey(&tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(key) != tt.want {
+				t.Errorf("LoadKey() = %q, want %q", key, tt.want)
+			}
+			if err != nil && strings.Contains(err.Error(), "s3cret") {
+				t.Errorf("error leaks the key: %v", err)
+			}
+		})
+	}
+}
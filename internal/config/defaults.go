@@ -1,15 +1,22 @@
 package config
 
+import "github.com/Les-El/chexum/internal/hash"
+
 // DefaultConfig returns a Config with sensible defaults.
 func DefaultConfig() *Config {
 	cfg := &Config{
 		Algorithm:    "sha256",
+		Encoding:     hash.EncodingHex,
 		OutputFormat: "default",
 		MinSize:      0,
 		MaxSize:      -1, // No limit
 		Jobs:         0,  // Auto-detection
 	}
 
+	archiveLimits := hash.DefaultArchiveLimits()
+	cfg.ArchiveMaxMembers = archiveLimits.MaxMembers
+	cfg.ArchiveMaxSize = archiveLimits.MaxTotalSize
+
 	// Initialize structured fields
 	cfg.Input.MinSize = 0
 	cfg.Input.MaxSize = -1
@@ -33,4 +40,6 @@ var ValidOutputFormats = []string{
 	"plain",
 	"csv",
 }
-var ValidAlgorithms = []string{"sha256", "md5", "sha1", "sha512", "blake2b"}
+
+// ValidAlgorithms lists the canonical algorithm names from the hash registry.
+var ValidAlgorithms = hash.AlgorithmNames()
@@ -63,6 +63,7 @@ type ConfigFile struct {
 		AnyMatch      *bool    `toml:"any_match,omitempty"`
 		AllMatch      *bool    `toml:"all_match,omitempty"`
 		OutputFormat  *string  `toml:"output_format,omitempty"`
+		Encoding      *string  `toml:"encoding,omitempty"`
 		OutputFile    *string  `toml:"output_file,omitempty"`
 		Append        *bool    `toml:"append,omitempty"`
 		Force         *bool    `toml:"force,omitempty"`
@@ -70,8 +71,36 @@ type ConfigFile struct {
 		LogJSON       *string  `toml:"log_json,omitempty"`
 		Include       []string `toml:"include,omitempty"`
 		Exclude       []string `toml:"exclude,omitempty"`
+		GitIgnore     *bool    `toml:"gitignore,omitempty"` // Deprecated: use no_gitignore
+		NoGitIgnore   *bool    `toml:"no_gitignore,omitempty"`
+		NoIgnore      *bool    `toml:"no_ignore,omitempty"`
+		MaxDepth      *int     `toml:"max_depth,omitempty"`
+		OneFileSystem *bool    `toml:"one_file_system,omitempty"`
+		FollowLinks   *bool    `toml:"follow_symlinks,omitempty"`
 		MinSize       *string  `toml:"min_size,omitempty"`
 		MaxSize       *string  `toml:"max_size,omitempty"`
+		NoCache       *bool    `toml:"no_cache,omitempty"`
+		CacheDir      *string  `toml:"cache_dir,omitempty"`
+		MaxRate       *string  `toml:"max_rate,omitempty"`
+		Nice          *int     `toml:"nice,omitempty"`
+		IdleIO        *bool    `toml:"idle_io,omitempty"`
+		BufferSize    *string  `toml:"buffer_size,omitempty"`
+
+		// Discovery predicates, in the flags' syntax
+		Regex          []string `toml:"regex,omitempty"`
+		Type           []string `toml:"type,omitempty"`
+		Owner          []string `toml:"owner,omitempty"`
+		Group          []string `toml:"group,omitempty"`
+		Perm           *string  `toml:"perm,omitempty"`
+		ModifiedAfter  *string  `toml:"modified_after,omitempty"`
+		ModifiedBefore *string  `toml:"modified_before,omitempty"`
+		ModifiedWithin *string  `toml:"modified_within,omitempty"`
+		ChangedAfter   *string  `toml:"changed_after,omitempty"`
+		ChangedBefore  *string  `toml:"changed_before,omitempty"`
+		ChangedWithin  *string  `toml:"changed_within,omitempty"`
+		AccessedAfter  *string  `toml:"accessed_after,omitempty"`
+		AccessedBefore *string  `toml:"accessed_before,omitempty"`
+		AccessedWithin *string  `toml:"accessed_within,omitempty"`
 	} `toml:"defaults"`
 	Security struct {
 		BlacklistFiles []string `toml:"blacklist_files,omitempty"`
@@ -79,7 +108,9 @@ type ConfigFile struct {
 		WhitelistFiles []string `toml:"whitelist_files,omitempty"`
 		WhitelistDirs  []string `toml:"whitelist_dirs,omitempty"`
 	} `toml:"security"`
-	Files []string `toml:"files,omitempty"`
+	// IO maps mount points to the number of files read from them at once.
+	IO    map[string]int `toml:"io,omitempty"`
+	Files []string       `toml:"files,omitempty"`
 }
 
 // LoadConfigFile reads and parses the configuration file at the given path.
@@ -135,7 +166,13 @@ func (cf *ConfigFile) ApplyConfigFile(cfg *Config, flagSet *pflag.FlagSet) error
 	}
 
 	cf.applyListDefaults(cfg, flagSet)
+	if err := cf.applyPredicateDefaults(cfg, flagSet); err != nil {
+		return err
+	}
 	cf.applySecurityDefaults(cfg)
+	if err := cf.applyIOLimits(cfg); err != nil {
+		return err
+	}
 
 	if len(cf.Files) > 0 && len(cfg.Files) == 0 {
 		cfg.Files = cf.Files
@@ -162,6 +199,11 @@ func (cf *ConfigFile) applyBoolDefaults(cfg *Config, flagSet *pflag.FlagSet) {
 		{d.AllMatch, "all-match", &cfg.AllMatch},
 		{d.Append, "append", &cfg.Append},
 		{d.Force, "force", &cfg.Force},
+		{d.NoCache, "no-cache", &cfg.NoCache},
+		{d.IdleIO, "idle-io", &cfg.IdleIO},
+		{d.NoIgnore, "no-ignore", &cfg.NoIgnore},
+		{d.OneFileSystem, "one-file-system", &cfg.OneFileSystem},
+		{d.FollowLinks, "follow-symlinks", &cfg.FollowSymlinks},
 	}
 
 	for _, f := range boolFlags {
@@ -169,6 +211,17 @@ func (cf *ConfigFile) applyBoolDefaults(cfg *Config, flagSet *pflag.FlagSet) {
 			*f.ptr = *f.val
 		}
 	}
+
+	// gitignore is the deprecated inverse of no_gitignore; either flag
+	// on the command line outranks both keys.
+	if !flagSet.Changed("no-gitignore") && !flagSet.Changed("gitignore") {
+		switch {
+		case d.NoGitIgnore != nil:
+			cfg.NoGitIgnore = *d.NoGitIgnore
+		case d.GitIgnore != nil:
+			cfg.NoGitIgnore = !*d.GitIgnore
+		}
+	}
 }
 
 func (cf *ConfigFile) applyStringDefaults(cfg *Config, flagSet *pflag.FlagSet) {
@@ -180,9 +233,11 @@ func (cf *ConfigFile) applyStringDefaults(cfg *Config, flagSet *pflag.FlagSet) {
 	}{
 		{d.Algorithm, "algorithm", &cfg.Algorithm},
 		{d.OutputFormat, "format", &cfg.OutputFormat},
+		{d.Encoding, "encoding", &cfg.Encoding},
 		{d.OutputFile, "output", &cfg.OutputFile},
 		{d.LogFile, "log-file", &cfg.LogFile},
 		{d.LogJSON, "log-json", &cfg.LogJSON},
+		{d.CacheDir, "cache-dir", &cfg.CacheDir},
 	}
 
 	for _, f := range stringFlags {
@@ -208,6 +263,26 @@ func (cf *ConfigFile) applySizeDefaults(cfg *Config, flagSet *pflag.FlagSet) err
 		}
 		cfg.MaxSize = size
 	}
+	if d.MaxRate != nil && !flagSet.Changed("max-rate") {
+		rate, err := parseRate(*d.MaxRate)
+		if err != nil {
+			return fmt.Errorf("invalid max_rate in config: %w", err)
+		}
+		cfg.MaxRate = rate
+	}
+	if d.BufferSize != nil && !flagSet.Changed("buffer-size") {
+		size, err := parseSize(*d.BufferSize)
+		if err != nil {
+			return fmt.Errorf("invalid buffer_size in config: %w", err)
+		}
+		cfg.BufferSize = size
+	}
+	if d.Nice != nil && !flagSet.Changed("nice") {
+		cfg.Nice = *d.Nice
+	}
+	if d.MaxDepth != nil && !flagSet.Changed("max-depth") {
+		cfg.MaxDepth = *d.MaxDepth
+	}
 	return nil
 }
 
@@ -221,6 +296,43 @@ func (cf *ConfigFile) applyListDefaults(cfg *Config, flagSet *pflag.FlagSet) {
 	}
 }
 
+// applyPredicateDefaults applies the discovery predicates the config file
+// sets and the command line does not.
+func (cf *ConfigFile) applyPredicateDefaults(cfg *Config, flagSet *pflag.FlagSet) error {
+	d := cf.Defaults
+	one := func(s *string) []string {
+		if s == nil {
+			return nil
+		}
+		return []string{*s}
+	}
+	values := map[string][]string{
+		"regex":           d.Regex,
+		"type":            d.Type,
+		"owner":           d.Owner,
+		"group":           d.Group,
+		"perm":            one(d.Perm),
+		"modified-after":  one(d.ModifiedAfter),
+		"modified-before": one(d.ModifiedBefore),
+		"modified-within": one(d.ModifiedWithin),
+		"changed-after":   one(d.ChangedAfter),
+		"changed-before":  one(d.ChangedBefore),
+		"changed-within":  one(d.ChangedWithin),
+		"accessed-after":  one(d.AccessedAfter),
+		"accessed-before": one(d.AccessedBefore),
+		"accessed-within": one(d.AccessedWithin),
+	}
+	for name, v := range values {
+		if len(v) == 0 || flagSet.Changed(name) {
+			continue
+		}
+		if err := predicateParsers[name](cfg, v); err != nil {
+			return fmt.Errorf("invalid %s in config: %w", strings.ReplaceAll(name, "-", "_"), err)
+		}
+	}
+	return nil
+}
+
 func (cf *ConfigFile) applySecurityDefaults(cfg *Config) {
 	s := cf.Security
 	cfg.BlacklistFiles = append(cfg.BlacklistFiles, s.BlacklistFiles...)
@@ -229,6 +341,20 @@ func (cf *ConfigFile) applySecurityDefaults(cfg *Config) {
 	cfg.WhitelistDirs = append(cfg.WhitelistDirs, s.WhitelistDirs...)
 }
 
+// applyIOLimits copies the [io] table, rejecting limits below one reader.
+func (cf *ConfigFile) applyIOLimits(cfg *Config) error {
+	for mount, limit := range cf.IO {
+		if limit < 1 {
+			return fmt.Errorf("invalid [io] limit for %q: %d (must be at least 1)", mount, limit)
+		}
+		if cfg.IOLimits == nil {
+			cfg.IOLimits = make(map[string]int, len(cf.IO))
+		}
+		cfg.IOLimits[mount] = limit
+	}
+	return nil
+}
+
 func parseSize(s string) (int64, error) {
 	s = strings.TrimSpace(strings.ToUpper(s))
 	if s == "" || s == "-1" {
@@ -268,11 +394,33 @@ func parseSize(s string) (int64, error) {
 	return num, nil
 }
 
+// parseRate parses a bandwidth such as "50MB/s" or "1.5G". The "/s" suffix
+// is optional; the rest is a size as accepted by parseSize.
+func parseRate(s string) (int64, error) {
+	trimmed := strings.TrimSpace(s)
+	if len(trimmed) > 2 && strings.EqualFold(trimmed[len(trimmed)-2:], "/s") {
+		trimmed = trimmed[:len(trimmed)-2]
+	}
+	rate, err := parseSize(trimmed)
+	if err != nil {
+		return 0, err
+	}
+	if rate < 0 {
+		return 0, fmt.Errorf("invalid rate %q: must not be negative", s)
+	}
+	return rate, nil
+}
+
 func parseDate(s string) (time.Time, error) {
 	if s == "" {
 		return time.Time{}, nil
 	}
 
+	// An age such as "7d" means that long before now.
+	if age, err := parseAge(s); err == nil {
+		return time.Now().Add(-age), nil
+	}
+
 	formats := []string{
 		"2006-01-02",
 		"2006-01-02T15:04:05",
@@ -286,7 +434,7 @@ func parseDate(s string) (time.Time, error) {
 		}
 	}
 
-	return time.Time{}, fmt.Errorf("invalid date %q: use format YYYY-MM-DD or YYYY-MM-DDTHH:MM:SS", s)
+	return time.Time{}, fmt.Errorf("invalid date %q: use format YYYY-MM-DD or YYYY-MM-DDTHH:MM:SS, or an age such as 7d", s)
 }
 
 // FindConfigFile searches standard locations for a configuration file.
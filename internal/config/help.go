@@ -27,7 +27,10 @@ EXAMPLES
   chexum -r /path/to/dir          Recursively hash directory
   chexum --json *.txt             Output results as JSON
   chexum --csv *.txt              Output results as CSV
-  chexum -                        Read file list from stdin
+  curl -s URL | chexum - HASH     Hash piped data and check it against HASH
+  chexum --stdin-paths < list.txt Read file list from stdin
+  find . -print0 | chexum -0      Read a NUL-separated file list from stdin
+  chexum --check SHA256SUMS       Verify files against a sha256sum checksum file
 `
 
 const helpUsage = `
@@ -43,8 +46,25 @@ FLAGS
   -r, --recursive           Process directories recursively
   -H, --hidden              Include hidden files
       --dry-run             Preview files without hashing
-  -a, --algorithm string    Hash algorithm: sha256, md5, sha1, sha512, blake2b (default: sha256)
-  -j, --jobs int            Number of parallel jobs (0 = auto)
+      --stdin-paths         Read file paths from stdin, one per line
+      --files-from PATH     Read file paths from PATH, one per line (- for stdin)
+  -0, --null                Path lists are NUL-separated, as from find -print0;
+                            names are taken verbatim. Alone, reads stdin
+      --print0              Plain output with every record ended by NUL and
+                            names printed verbatim, for xargs -0
+  -a, --algorithm string    Hash algorithm (default: sha256). One of:
+                              sha256, md5, sha1, sha512, blake2b, sha3-256, sha3-512,
+                              blake2s-256, blake3, xxh64*, crc32c*,
+                              sampled-sha256***, sampled-xxh64***,
+                              hmac-sha256**, hmac-sha512**, blake2b-keyed**
+                              (* non-cryptographic checksums, ** keyed: need a key,
+                              *** sampled: size plus 16 x 64 KB blocks, not a full
+                              digest; for quick change triage of huge files)
+                            Give several, comma-separated, to compute them all in
+                            one pass (e.g. -a sha256,md5). The first is used for
+                            grouping; output lists every digest.
+  -j, --jobs int            Number of parallel jobs (default auto; 0 = adapt to
+                            measured throughput)
       --test                Run system diagnostics for troubleshooting
       --preserve-order      Keep input order instead of grouping by hash
 `
@@ -71,6 +91,8 @@ OUTPUT FORMATS
       --jsonl               Shorthand for --format=jsonl
       --plain               Shorthand for --format=plain
       --csv                 Shorthand for --format=csv
+      --encoding string     Digest encoding: hex, base64, base32, nix32, sri, multihash
+                            (default: hex; reference hashes are accepted in any)
   -o, --output string       Write output to file
       --append              Append to output file
       --force               Overwrite without prompting
@@ -85,16 +107,100 @@ EXIT CODE CONTROL
 
 FILTERING
   -i, --include strings     Glob patterns to include
-  -e, --exclude strings     Glob patterns to exclude
+  -e, --exclude strings     Glob patterns to exclude; a matching directory is
+                            not descended into. Patterns with a "/" match the
+                            path below the argument, "**" spans directories
+                            e.g. -i 'src/**/*.go' -e 'vendor/**'
+      --no-gitignore        Do not skip what .gitignore files and
+                            .git/info/exclude ignore; by default they are
+                            honoured, as .chexumignore files always are
+      --no-ignore           Read no ignore files, not even .chexumignore
+      --max-depth int       Descend at most this many levels below each
+                            argument; 1 is the argument's own files (implies -r)
+  -x, --one-file-system     Do not descend into other file systems (alias --xdev)
+      --follow-symlinks     Follow symbolic links found while walking; links
+                            given as arguments are always followed. Without it,
+                            --verbose lists the links passed over
       --min-size string     Minimum file size (e.g., 10KB, 1MB, 1GB)
       --max-size string     Maximum file size (-1 for no limit)
-      --modified-after      Only files modified after date (YYYY-MM-DD)
-      --modified-before     Only files modified before date (YYYY-MM-DD)
+      --modified-after      Only files modified after date (YYYY-MM-DD) or age (7d)
+      --modified-before     Only files modified before date (YYYY-MM-DD) or age (7d)
+      --modified-within     Only files modified within an age, e.g. 12h, 7d, 2w
+      --changed-after, --changed-before, --changed-within
+                            The same for the inode change time (ctime, Unix)
+      --accessed-after, --accessed-before, --accessed-within
+                            The same for the access time (atime, Unix)
+      --regex string        Regular expression the path below the argument must
+                            match (repeatable: any one must match)
+      --type strings        Content types sniffed from the first 512 bytes, e.g.
+                            image/*, text/plain, or elf, exe, pdf, zip, image, text
+      --owner strings       Only files owned by these users (names or IDs, Unix)
+      --group strings       Only files of these groups (names or IDs, Unix)
+      --perm string         Permission bits as find -perm: 644 exactly,
+                            -111 all of these, /022 any of these
+                            Filters combine: a file must pass every one given
 
 INCREMENTAL OPERATIONS
       --manifest string     Path to baseline manifest file
       --only-changed        Process only new or modified files
       --output-manifest string  Path to save result as a manifest
+
+ARCHIVES
+      --archives            Also hash each member of .tar, .tar.gz/.tgz and .zip
+                            files, reported as archive.tar.gz!/path/in/archive
+      --archive-max-members int  Stop after this many members per archive (default 10000)
+      --archive-max-size string  Stop after this many uncompressed bytes per archive (default 16GB)
+
+KEYED HASHING
+      --key-file string     File holding the key for hmac-sha256, hmac-sha512 and
+                            blake2b-keyed (one trailing newline is ignored)
+      --key-env string      Environment variable holding the key instead
+                            Keys are never accepted on the command line, and never
+                            appear in output, logs or manifests.
+
+PASSTHROUGH
+      --passthrough         Copy stdin to stdout unchanged while hashing it; the
+                            digest goes to stderr (or the --output file)
+      --expect string       Hash the data must match; exit 1 on mismatch
+                            e.g. curl -s URL | chexum --passthrough --expect HASH | tar x
+
+DIRECTORY DIGESTS
+      --tree                Print one Merkle digest per directory argument,
+                            covering relative paths, file modes and contents
+      --tree-breakdown      Also print the digest of every subdirectory
+
+DUPLICATE FILES
+      --duplicates          Group identical files and show the space they waste.
+                            Only files that share a size are read, and only
+                            those whose first and last 4 KB match are fully hashed
+                            e.g. chexum -r --duplicates /mnt/media
+
+CHECKSUM FILES
+      --check               Verify the files listed in checksum files written by
+                            sha256sum, md5sum and the like, or by their --tag
+                            option (stdin if none given); prints name: OK,
+                            FAILED or MISSING and exits 1 if any file fails
+                            With -q, OK lines are left out
+      --status              Print nothing; report only through the exit status
+      --strict              Also fail on improperly formatted lines
+      --ignore-missing      Do not fail or report for files that do not exist
+                            e.g. chexum --check --ignore-missing SHA256SUMS
+
+HASH CACHE
+      --no-cache            Read every file, and leave the hash cache untouched
+      --cache-dir string    Directory of the hash cache
+                            (default $XDG_CACHE_HOME/chexum or ~/.cache/chexum)
+                            Files whose device, inode, size, mtime and ctime are
+                            unchanged since they were last hashed are not read
+
+RESOURCE LIMITS
+      --max-rate string     Cap on bytes read per second by all workers together
+                            e.g. --max-rate 50MB/s
+      --nice int            Lower chexum's CPU priority by 1-19 (Linux)
+      --idle-io             Use the idle I/O class: read only when the disk
+                            is otherwise unused (Linux)
+      --buffer-size string  Bytes each worker reads from a file at a time
+                            (4KB-64MB, default 256KB)
 `
 
 const helpConfiguration = `
@@ -112,7 +218,7 @@ const helpEnvironment = `
 ENVIRONMENT VARIABLES
   CHEXUM_* Variables (override config file settings):
     CHEXUM_CONFIG            Default config file path
-    CHEXUM_ALGORITHM         Hash algorithm (sha256, md5, sha1, sha512, blake2b)
+    CHEXUM_ALGORITHM         Hash algorithm (any name accepted by --algorithm)
     CHEXUM_OUTPUT_FORMAT     Output format (default, verbose, json, plain, csv)
     CHEXUM_RECURSIVE         Process directories recursively (true/false)
 `
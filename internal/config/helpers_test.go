@@ -20,6 +20,10 @@ func TestClassifyArguments(t *testing.T) {
 		{"invalid hash length for algo", []string{"d41d8cd98f00b204e9800998ecf8427e"}, "sha256", 0, 0, 1},
 		{"invalid hex", []string{"not-a-hash-but-looks-like-one-if-it-had-hex-only-0123456789abcdefg"}, "sha256", 0, 0, 1},
 		{"hash like but unknown length", []string{"abcde"}, "sha256", 0, 0, 1},
+		{"sri", []string{"sha256-LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ="}, "sha256", 0, 1, 0},
+		{"sri for another algorithm", []string{"sha256-LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ="}, "blake3", 0, 0, 1},
+		{"base64", []string{"LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ="}, "sha256", 0, 1, 0},
+		{"multihash", []string{"QmRN6wdp1S2A5EtjW9A3M1vKSBuQQGcgvuhoMUoEz4iiT5"}, "sha256", 0, 1, 0},
 	}
 
 	for _, tt := range tests {
@@ -42,6 +46,14 @@ func TestClassifyArguments(t *testing.T) {
 	}
 }
 
+func TestClassifyArguments_NormalizesEncoding(t *testing.T) {
+	_, hashes, _, _ := ClassifyArguments([]string{"sha256-LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ="}, "sha256")
+	want := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	if len(hashes) != 1 || hashes[0] != want {
+		t.Errorf("got %v, want [%s]", hashes, want)
+	}
+}
+
 func TestDetectHashAlgorithm(t *testing.T) {
 	tests := []struct {
 		hash string
@@ -49,8 +61,8 @@ func TestDetectHashAlgorithm(t *testing.T) {
 	}{
 		{"d41d8cd98f00b204e9800998ecf8427e", []string{"md5"}},
 		{"da39a3ee5e6b4b0d3255bfef95601890afd80709", []string{"sha1"}},
-		{"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", []string{"sha256"}},
-		{"cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e", []string{"sha512", "blake2b"}},
+		{"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", []string{"sha256", "sha3-256", "blake2s-256", "blake3"}},
+		{"cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e", []string{"sha512", "blake2b", "sha3-512"}},
 		{"too-short", []string{}},
 		{"nothex", []string{}},
 	}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+)
+
+// LoadKey reads the secret for keyed algorithms from --key-file or the
+// environment variable named by --key-env.
+//
+// DESIGN PRINCIPLE: Keys Never Touch the Command Line
+// ---------------------------------------------------
+// Arguments are visible to every user via ps(1) and end up in shell history,
+// so there is deliberately no flag that takes the key itself. The key is
+// returned to the caller for hashing only; it is not stored in Config, and
+// errors name the source, never the contents.
+//
+// A single trailing newline is stripped from key files so that a key written
+// with `echo secret > key` works as expected.
+func LoadKey(cfg *Config) ([]byte, error) {
+	var key []byte
+	switch {
+	case cfg.KeyFile != "":
+		data, err := os.ReadFile(cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read key file: %w", err)
+		}
+		key = bytes.TrimSuffix(bytes.TrimSuffix(data, []byte("\n")), []byte("\r"))
+	case cfg.KeyEnv != "":
+		key = []byte(os.Getenv(cfg.KeyEnv))
+	default:
+		return nil, nil
+	}
+	if len(key) == 0 {
+		return nil, fmt.Errorf("the key from %s is empty", keySource(cfg))
+	}
+	return key, nil
+}
+
+// keySource describes where the key comes from, for messages.
+func keySource(cfg *Config) string {
+	if cfg.KeyFile != "" {
+		return "--key-file " + cfg.KeyFile
+	}
+	return "environment variable " + cfg.KeyEnv
+}
@@ -0,0 +1,199 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"os/user"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Les-El/chexum/internal/hash"
+	"github.com/spf13/pflag"
+)
+
+// predicateParsers turn the textual form of a discovery predicate, given by
+// flag or config file, into cfg. Predicates combine: every one set must
+// pass, so a second lower time bound can only narrow the window.
+var predicateParsers = map[string]func(cfg *Config, values []string) error{
+	"regex": func(cfg *Config, values []string) error {
+		for _, v := range values {
+			re, err := regexp.Compile(v)
+			if err != nil {
+				return err
+			}
+			cfg.PathRegex = append(cfg.PathRegex, re)
+		}
+		return nil
+	},
+	"type": func(cfg *Config, values []string) error {
+		for _, v := range values {
+			pattern, err := hash.ResolveType(v)
+			if err != nil {
+				return err
+			}
+			cfg.Types = append(cfg.Types, pattern)
+		}
+		return nil
+	},
+	"owner": func(cfg *Config, values []string) error {
+		ids, err := lookupIDs(values, "user", func(name string) (string, error) {
+			u, err := user.Lookup(name)
+			if err != nil {
+				return "", err
+			}
+			return u.Uid, nil
+		})
+		cfg.Owners = append(cfg.Owners, ids...)
+		return err
+	},
+	"group": func(cfg *Config, values []string) error {
+		ids, err := lookupIDs(values, "group", func(name string) (string, error) {
+			g, err := user.LookupGroup(name)
+			if err != nil {
+				return "", err
+			}
+			return g.Gid, nil
+		})
+		cfg.Groups = append(cfg.Groups, ids...)
+		return err
+	},
+	"perm": func(cfg *Config, values []string) (err error) {
+		cfg.Perm, err = parsePerm(values[len(values)-1])
+		return err
+	},
+	"modified-after":  dateBound(func(c *Config) *time.Time { return &c.ModifiedAfter }, true, false),
+	"modified-before": dateBound(func(c *Config) *time.Time { return &c.ModifiedBefore }, false, false),
+	"modified-within": dateBound(func(c *Config) *time.Time { return &c.ModifiedAfter }, true, true),
+	"changed-after":   dateBound(func(c *Config) *time.Time { return &c.ChangedAfter }, true, false),
+	"changed-before":  dateBound(func(c *Config) *time.Time { return &c.ChangedBefore }, false, false),
+	"changed-within":  dateBound(func(c *Config) *time.Time { return &c.ChangedAfter }, true, true),
+	"accessed-after":  dateBound(func(c *Config) *time.Time { return &c.AccessedAfter }, true, false),
+	"accessed-before": dateBound(func(c *Config) *time.Time { return &c.AccessedBefore }, false, false),
+	"accessed-within": dateBound(func(c *Config) *time.Time { return &c.AccessedAfter }, true, true),
+}
+
+// dateBound returns a parser for one end of a time window. Values are dates
+// or ages (see parseDate); a "-within" value must be an age. A lower bound
+// only ever moves later and an upper bound earlier.
+func dateBound(field func(*Config) *time.Time, lower, ageOnly bool) func(*Config, []string) error {
+	return func(cfg *Config, values []string) error {
+		for _, v := range values {
+			var t time.Time
+			if ageOnly {
+				age, err := parseAge(v)
+				if err != nil {
+					return err
+				}
+				t = time.Now().Add(-age)
+			} else {
+				var err error
+				if t, err = parseDate(v); err != nil {
+					return err
+				}
+			}
+			bound := field(cfg)
+			if bound.IsZero() || (lower && t.After(*bound)) || (!lower && t.Before(*bound)) {
+				*bound = t
+			}
+		}
+		return nil
+	}
+}
+
+// parsePredicateFlags applies the predicate flags given on the command line.
+func parsePredicateFlags(cfg *Config, flagSet *pflag.FlagSet) error {
+	for name, parse := range predicateParsers {
+		flag := flagSet.Lookup(name)
+		if flag == nil || !flag.Changed {
+			continue
+		}
+		values := []string{flag.Value.String()}
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			values = slice.GetSlice()
+		}
+		if len(values) == 0 {
+			continue
+		}
+		if err := parse(cfg, values); err != nil {
+			return fmt.Errorf("invalid --%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// parseAge parses a duration that may use days and weeks as well as hours,
+// minutes and seconds: "7d", "2w", "36h", "1d12h", "90m".
+func parseAge(s string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"w": 7 * 24 * time.Hour,
+		"d": 24 * time.Hour,
+		"h": time.Hour,
+		"m": time.Minute,
+		"s": time.Second,
+	}
+	rest := strings.ToLower(strings.TrimSpace(s))
+	if rest == "" {
+		return 0, fmt.Errorf("empty age")
+	}
+	var total time.Duration
+	for rest != "" {
+		i := 0
+		for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+			i++
+		}
+		j := i
+		for j < len(rest) && (rest[j] < '0' || rest[j] > '9') {
+			j++
+		}
+		n, err := strconv.Atoi(rest[:i])
+		unit, ok := units[rest[i:j]]
+		if err != nil || !ok {
+			return 0, fmt.Errorf("invalid age %q: use a number and unit such as 30m, 12h, 7d or 2w", s)
+		}
+		total += time.Duration(n) * unit
+		rest = rest[j:]
+	}
+	return total, nil
+}
+
+// parsePerm parses a permission filter in find's -perm notation: "644"
+// requires exactly those bits, "-111" all of them, "/022" any of them.
+func parsePerm(s string) (hash.PermFilter, error) {
+	filter := hash.PermFilter{Match: '='}
+	if s != "" && (s[0] == '-' || s[0] == '/') {
+		filter.Match, s = s[0], s[1:]
+	}
+	v, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || v > 07777 {
+		return hash.PermFilter{}, fmt.Errorf("invalid mode %q: use octal bits such as 644, -111 or /022", s)
+	}
+	filter.Bits = fs.FileMode(v & 0777)
+	for bit, mode := range map[uint64]fs.FileMode{04000: fs.ModeSetuid, 02000: fs.ModeSetgid, 01000: fs.ModeSticky} {
+		if v&bit != 0 {
+			filter.Bits |= mode
+		}
+	}
+	return filter, nil
+}
+
+// lookupIDs resolves user or group names to numeric IDs; numbers are taken
+// as IDs directly.
+func lookupIDs(names []string, kind string, lookup func(string) (string, error)) ([]uint32, error) {
+	var ids []uint32
+	for _, name := range names {
+		id := name
+		if _, err := strconv.ParseUint(name, 10, 32); err != nil {
+			if id, err = lookup(name); err != nil {
+				return ids, fmt.Errorf("unknown %s %q", kind, name)
+			}
+		}
+		n, err := strconv.ParseUint(id, 10, 32)
+		if err != nil {
+			return ids, fmt.Errorf("%s %q has no numeric ID on this platform", kind, name)
+		}
+		ids = append(ids, uint32(n))
+	}
+	return ids, nil
+}
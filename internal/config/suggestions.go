@@ -25,11 +25,55 @@ var KnownFlags = []string{
 	"log-json",
 	"include",
 	"exclude",
+	"gitignore",
+	"no-gitignore",
+	"no-ignore",
 	"min-size",
 	"max-size",
 	"modified-after",
 	"modified-before",
+	"modified-within",
+	"max-depth",
+	"one-file-system",
+	"xdev",
+	"follow-symlinks",
+	"regex",
+	"type",
+	"owner",
+	"group",
+	"perm",
+	"changed-after",
+	"changed-before",
+	"changed-within",
+	"accessed-after",
+	"accessed-before",
+	"accessed-within",
 	"config",
+	"stdin-paths",
+	"files-from",
+	"null",
+	"print0",
+	"encoding",
+	"key-file",
+	"key-env",
+	"passthrough",
+	"expect",
+	"archives",
+	"archive-max-members",
+	"archive-max-size",
+	"tree",
+	"tree-breakdown",
+	"duplicates",
+	"check",
+	"status",
+	"strict",
+	"ignore-missing",
+	"no-cache",
+	"cache-dir",
+	"max-rate",
+	"nice",
+	"idle-io",
+	"buffer-size",
 	"h",
 	"V",
 	"v",
@@ -1,13 +1,16 @@
 package config
 
 import (
+	"regexp"
 	"time"
+
+	"github.com/Les-El/chexum/internal/hash"
 )
 
 // Exit codes for scripting support
 const (
 	ExitSuccess        = 0   // All files processed successfully
-	ExitNoMatches      = 1   // No matches found (with --any-match or --all-match)
+	ExitNoMatches      = 1   // No matches found (with --any-match or --all-match), or --check failed
 	ExitPartialFailure = 2   // Some files failed to process
 	ExitInvalidArgs    = 3   // Invalid arguments or flags
 	ExitFileNotFound   = 4   // One or more files not found
@@ -100,13 +103,15 @@ type Config struct {
 
 	Recursive     bool
 	Hidden        bool
-	Algorithm     string
+	Algorithm     string   // Primary algorithm (first entry of --algorithm)
+	Algorithms    []string // Every algorithm requested via --algorithm, primary first
 	DryRun        bool
 	Verbose       bool
 	Quiet         bool
 	Bool          bool
 	PreserveOrder bool
 	Jobs          int
+	AdaptiveJobs  bool // --jobs 0 was given: tune the worker count while hashing
 	Test          bool
 
 	MatchRequired bool
@@ -132,17 +137,75 @@ type Config struct {
 	MaxSize        int64
 	ModifiedAfter  time.Time
 	ModifiedBefore time.Time
+	NoGitIgnore    bool // Honour .chexumignore files only, not .gitignore or .git/info/exclude
+	NoIgnore       bool // Honour no ignore files at all, not even .chexumignore
+	MaxDepth       int  // Levels below each argument to descend; 0 means no limit
+	OneFileSystem  bool // Stay on the device of each argument
+	FollowSymlinks bool // Follow symbolic links found while walking
+
+	// Discovery predicates (see predicateParsers)
+	PathRegex      []*regexp.Regexp // --regex: the path below the argument must match one
+	Types          []string         // --type: MIME patterns, one of which the content must match
+	Owners         []uint32         // --owner: user IDs
+	Groups         []uint32         // --group: group IDs
+	Perm           hash.PermFilter  // --perm
+	ChangedAfter   time.Time        // --changed-after / --changed-within (ctime)
+	ChangedBefore  time.Time
+	AccessedAfter  time.Time // --accessed-after / --accessed-within (atime)
+	AccessedBefore time.Time
 
 	Manifest       string
 	OnlyChanged    bool
 	OutputManifest string
 
+	StdinPaths bool   // Read file paths from stdin instead of hashing stdin data
+	FilesFrom  string // Read file paths from this file as well
+	Null       bool   // Path lists are NUL-separated (-0)
+	Print0     bool   // End plain output records with NUL and print names verbatim
+
+	Encoding string // Digest encoding for output and manifests (see hash.Encodings)
+
+	KeyFile string // File holding the secret for keyed algorithms
+	KeyEnv  string // Environment variable holding the secret for keyed algorithms
+
+	Passthrough bool   // Copy stdin to stdout unchanged while hashing it
+	Expect      string // Hash the passthrough data must match
+
+	Archives          bool  // Also hash the members of tar, tar.gz and zip archives
+	ArchiveMaxMembers int   // Maximum members hashed per archive
+	ArchiveMaxSize    int64 // Maximum uncompressed bytes read per archive
+
+	Tree          bool // Print one Merkle digest per directory argument
+	TreeBreakdown bool // With Tree, also print the digest of every subdirectory
+
+	Duplicates bool // Find duplicate files by size, then partial hash, then full hash
+
+	Check         bool // Verify the files listed in the checksum files given as arguments
+	Status        bool // With Check, print nothing; the exit status is the only result
+	Strict        bool // With Check, fail on improperly formatted lines
+	IgnoreMissing bool // With Check, pass over listed files that do not exist
+
+	NoCache  bool   // Neither read nor update the persistent hash cache
+	CacheDir string // Directory of the hash cache; empty means the user cache directory
+
+	IOLimits map[string]int // Concurrent readers per mount point, from the [io] config table
+
+	MaxRate int64 // Bytes per second read from disk by all workers together; 0 means unlimited
+	Nice    int   // CPU niceness chexum adds to itself; 0 leaves it alone
+	IdleIO  bool  // Put chexum in the idle I/O scheduling class (Linux)
+
+	BufferSize int64 // Bytes read from a file at a time by each worker; 0 means hash.DefaultBufferSize
+
 	BlacklistFiles []string
 	BlacklistDirs  []string
 	WhitelistFiles []string
 	WhitelistDirs  []string
 
 	Unknowns []string
+
+	// DiscoveryErrors holds the paths discovery could not read. They are
+	// skipped, reported with the results, and make the run a partial failure.
+	DiscoveryErrors []error
 }
 
 // InputConfig holds file discovery and filtering options.
@@ -189,6 +252,9 @@ type IncrementalConfig struct {
 	Manifest       string
 	OnlyChanged    bool
 	OutputManifest string
+
+	Tree          bool // Print one Merkle digest per directory argument
+	TreeBreakdown bool // With Tree, also print the digest of every subdirectory
 }
 
 // SecurityConfig holds security policy overrides.
@@ -3,8 +3,10 @@ package config
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Les-El/chexum/internal/conflict"
+	"github.com/Les-El/chexum/internal/hash"
 	"github.com/Les-El/chexum/internal/security"
 )
 
@@ -18,12 +20,11 @@ func ValidateOutputFormat(format string) error {
 	return fmt.Errorf("invalid output format %q: must be one of %s", format, strings.Join(ValidOutputFormats, ", "))
 }
 
-// ValidateAlgorithm checks if the provided algorithm string is supported.
+// ValidateAlgorithm checks if the provided algorithm string (or one of its
+// aliases) is supported.
 func ValidateAlgorithm(algorithm string) error {
-	for _, valid := range ValidAlgorithms {
-		if algorithm == valid {
-			return nil
-		}
+	if _, ok := hash.LookupAlgorithm(algorithm); ok {
+		return nil
 	}
 	return fmt.Errorf("invalid algorithm %q: must be one of %s", algorithm, strings.Join(ValidAlgorithms, ", "))
 }
@@ -49,6 +50,19 @@ func ValidateConfig(cfg *Config) ([]conflict.Warning, error) {
 	if err := falgValidate(cfg); err != nil {
 		return warnings, err
 	}
+	for _, name := range cfg.Algorithms {
+		if spec, _ := hash.LookupAlgorithm(name); spec.Sampled {
+			if !cfg.Quiet {
+				warnings = append(warnings, conflict.Warning{
+					Message: fmt.Sprintf("%s is a sampled fingerprint, not a full digest: it reads only %d blocks of each file, so changes between them go unnoticed", spec.Name, spec.SampleBlocks),
+				})
+			}
+		} else if cfg.Verbose && !spec.Cryptographic {
+			warnings = append(warnings, conflict.Warning{
+				Message: fmt.Sprintf("%s is a non-cryptographic checksum; it detects accidental corruption but not tampering", spec.Name),
+			})
+		}
+	}
 
 	// 3. Size and date validation
 	if err := validateConstraints(cfg); err != nil {
@@ -60,14 +74,302 @@ func ValidateConfig(cfg *Config) ([]conflict.Warning, error) {
 		return warnings, err
 	}
 
+	// 5. Mode combinations
+	if err := validateModes(cfg); err != nil {
+		return warnings, err
+	}
+
+	// 6. Key source for keyed algorithms
+	if err := validateKeySource(cfg); err != nil {
+		return warnings, err
+	}
+
 	return warnings, nil
 }
 
+// validateModes rejects flag combinations that select incompatible modes.
+func validateModes(cfg *Config) error {
+	// -0 on its own reads the list from stdin, as xargs -0 does, and
+	// "--files-from -" is another way of saying --stdin-paths.
+	if cfg.FilesFrom == "-" {
+		cfg.FilesFrom, cfg.StdinPaths = "", true
+	}
+	if cfg.Null && cfg.FilesFrom == "" {
+		cfg.StdinPaths = true
+	}
+	if err := validatePrint0(cfg); err != nil {
+		return err
+	}
+
+	stdinMarkers := 0
+	for _, f := range cfg.Files {
+		if f == "-" {
+			stdinMarkers++
+		}
+	}
+	if stdinMarkers > 1 {
+		return fmt.Errorf("stdin (-) can only be hashed once")
+	}
+	if stdinMarkers > 0 && cfg.StdinPaths {
+		return fmt.Errorf("cannot hash stdin (-) while also reading paths from it (--stdin-paths)")
+	}
+
+	if err := validateCheck(cfg); err != nil {
+		return err
+	}
+	if err := validatePassthrough(cfg, stdinMarkers); err != nil {
+		return err
+	}
+
+	if cfg.TreeBreakdown {
+		cfg.Tree = true
+	}
+	if cfg.Tree {
+		if len(cfg.Hashes) > 0 {
+			return fmt.Errorf("--tree cannot be combined with hash arguments")
+		}
+		if cfg.Bool {
+			return fmt.Errorf("--tree cannot be combined with --bool")
+		}
+		if stdinMarkers > 0 {
+			return fmt.Errorf("--tree needs directories; stdin (-) cannot be digested as a tree")
+		}
+	}
+	if err := validateDuplicates(cfg, stdinMarkers); err != nil {
+		return err
+	}
+	return validateSampled(cfg, stdinMarkers)
+}
+
+// validateSampled rejects modes a sampled algorithm cannot serve: streams
+// cannot be sampled, and results that imply every byte was compared would
+// be misleading.
+func validateSampled(cfg *Config, stdinMarkers int) error {
+	var sampled string
+	for _, name := range cfg.AlgorithmList() {
+		if hash.IsSampled(name) {
+			sampled = hash.CanonicalAlgorithm(name)
+			break
+		}
+	}
+	switch {
+	case sampled == "":
+		return nil
+	case stdinMarkers > 0 || cfg.Passthrough:
+		return fmt.Errorf("%s needs files; stdin cannot be sampled", sampled)
+	case cfg.Archives:
+		return fmt.Errorf("%s cannot sample archive members; remove --archives", sampled)
+	case cfg.Tree:
+		return fmt.Errorf("%s cannot be used with --tree; a directory digest must cover every byte", sampled)
+	case cfg.Duplicates:
+		return fmt.Errorf("%s cannot be used with --duplicates; duplicates must be confirmed by a full digest", sampled)
+	}
+	return nil
+}
+
+// validateDuplicates checks --duplicates. Duplicate finding skips files with
+// a unique size entirely, so anything that needs every file's digest is
+// rejected rather than silently given a partial answer.
+func validateDuplicates(cfg *Config, stdinMarkers int) error {
+	if !cfg.Duplicates {
+		return nil
+	}
+	switch {
+	case len(cfg.Hashes) > 0:
+		return fmt.Errorf("--duplicates cannot be combined with hash arguments")
+	case stdinMarkers > 0:
+		return fmt.Errorf("--duplicates compares files; stdin (-) cannot be included")
+	case cfg.Bool:
+		return fmt.Errorf("--duplicates cannot be combined with --bool")
+	case cfg.Tree:
+		return fmt.Errorf("--duplicates cannot be combined with --tree")
+	case cfg.Passthrough:
+		return fmt.Errorf("--duplicates cannot be combined with --passthrough")
+	case cfg.Archives:
+		return fmt.Errorf("--duplicates cannot be combined with --archives")
+	case cfg.OutputManifest != "":
+		return fmt.Errorf("--duplicates cannot write a manifest: files with a unique size are never hashed")
+	}
+	return nil
+}
+
+// validateCheck checks --check and the flags that only it uses. A check
+// reports on the files its checksum files list, so other modes and output
+// formats have nothing to act on. It always reads the files: a cached digest
+// would repeat what was on disk when it was stored, not what is there now.
+func validateCheck(cfg *Config) error {
+	if !cfg.Check {
+		switch {
+		case cfg.Status:
+			return fmt.Errorf("--status requires --check")
+		case cfg.Strict:
+			return fmt.Errorf("--strict requires --check")
+		case cfg.IgnoreMissing:
+			return fmt.Errorf("--ignore-missing requires --check")
+		}
+		return nil
+	}
+	for _, name := range cfg.AlgorithmList() {
+		if hash.IsKeyed(name) || hash.IsSampled(name) {
+			return fmt.Errorf("--check cannot verify %s digests", hash.CanonicalAlgorithm(name))
+		}
+	}
+	switch {
+	case cfg.Passthrough:
+		return fmt.Errorf("--check cannot be combined with --passthrough")
+	case cfg.Tree || cfg.TreeBreakdown:
+		return fmt.Errorf("--check cannot be combined with --tree")
+	case cfg.Duplicates:
+		return fmt.Errorf("--check cannot be combined with --duplicates")
+	case cfg.DryRun:
+		return fmt.Errorf("--check cannot be combined with --dry-run")
+	case cfg.Bool:
+		return fmt.Errorf("--check reports through its exit status; use --status instead of --bool")
+	case cfg.StdinPaths || cfg.FilesFrom != "":
+		return fmt.Errorf("--check takes checksum files as arguments, not a path list")
+	case cfg.OutputFormat != "default":
+		return fmt.Errorf("--check prints one line per file; it cannot be combined with other output formats")
+	case cfg.OutputManifest != "" || cfg.OnlyChanged:
+		return fmt.Errorf("--check cannot be combined with manifests")
+	case cfg.Archives:
+		return fmt.Errorf("--check cannot be combined with --archives")
+	}
+	cfg.NoCache = true
+	return nil
+}
+
+// validatePassthrough checks --passthrough and --expect. Stdout carries the
+// piped data in this mode, so anything else that would write results there
+// (or read stdin for another purpose) is rejected.
+func validatePassthrough(cfg *Config, stdinMarkers int) error {
+	if !cfg.Passthrough {
+		if cfg.Expect != "" {
+			return fmt.Errorf("--expect requires --passthrough")
+		}
+		return nil
+	}
+	if len(cfg.Files) > stdinMarkers {
+		return fmt.Errorf("--passthrough only reads stdin; remove the file arguments")
+	}
+	if len(cfg.Hashes) > 0 {
+		return fmt.Errorf("--passthrough takes the expected hash via --expect")
+	}
+	switch {
+	case cfg.StdinPaths:
+		return fmt.Errorf("--passthrough cannot be combined with --stdin-paths")
+	case cfg.Bool:
+		return fmt.Errorf("--passthrough cannot be combined with --bool")
+	case cfg.Tree || cfg.TreeBreakdown:
+		return fmt.Errorf("--passthrough cannot be combined with --tree")
+	case cfg.DryRun:
+		return fmt.Errorf("--passthrough cannot be combined with --dry-run")
+	}
+
+	if cfg.Expect != "" {
+		// Accept the expected hash in any encoding; compare it as hex.
+		if d, ok := hash.DecodeDigest(cfg.Expect); ok {
+			for _, alg := range cfg.AlgorithmList() {
+				if d.Fits(alg) {
+					cfg.Expect = d.Hex
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("--expect %q is not a valid %s hash", cfg.Expect, strings.Join(cfg.AlgorithmList(), "/"))
+	}
+	return nil
+}
+
+// validatePrint0 checks that --print0 has plain output to apply to, and
+// selects it.
+func validatePrint0(cfg *Config) error {
+	if !cfg.Print0 {
+		return nil
+	}
+	switch {
+	case cfg.OutputFormat != "default" && cfg.OutputFormat != "plain":
+		return fmt.Errorf("--print0 applies to plain output; it cannot be combined with --format %s", cfg.OutputFormat)
+	case cfg.Bool:
+		return fmt.Errorf("--print0 cannot be combined with --bool")
+	case cfg.Tree || cfg.TreeBreakdown:
+		return fmt.Errorf("--print0 cannot be combined with --tree")
+	}
+	cfg.OutputFormat = "plain"
+	return nil
+}
+
+// validateKeySource requires exactly one key source when a keyed algorithm is
+// requested, and rejects key sources that nothing would use.
+func validateKeySource(cfg *Config) error {
+	var keyed []string
+	for _, name := range cfg.AlgorithmList() {
+		if hash.IsKeyed(name) {
+			keyed = append(keyed, hash.CanonicalAlgorithm(name))
+		}
+	}
+	switch {
+	case cfg.KeyFile != "" && cfg.KeyEnv != "":
+		return fmt.Errorf("--key-file and --key-env are mutually exclusive")
+	case len(keyed) > 0 && cfg.KeyFile == "" && cfg.KeyEnv == "":
+		return fmt.Errorf("%s needs a key: use --key-file or --key-env (keys are never accepted on the command line)", strings.Join(keyed, ", "))
+	case len(keyed) == 0 && (cfg.KeyFile != "" || cfg.KeyEnv != ""):
+		return fmt.Errorf("a key was given but no keyed algorithm (hmac-sha256, hmac-sha512, blake2b-keyed) was requested")
+	}
+	return nil
+}
+
 func falgValidate(cfg *Config) error {
 	if err := ValidateOutputFormat(cfg.OutputFormat); err != nil {
 		return err
 	}
-	return ValidateAlgorithm(cfg.Algorithm)
+	algorithms, err := ParseAlgorithmList(cfg.Algorithm)
+	if err != nil {
+		return err
+	}
+	cfg.Algorithms = algorithms
+	cfg.Algorithm = algorithms[0]
+	return validateEncoding(cfg)
+}
+
+// validateEncoding checks that every requested algorithm can be written in
+// the chosen --encoding, so output never silently falls back to hex.
+func validateEncoding(cfg *Config) error {
+	if cfg.Encoding == "" {
+		cfg.Encoding = hash.EncodingHex
+	}
+	for _, alg := range cfg.AlgorithmList() {
+		if err := hash.CheckEncoding(alg, cfg.Encoding); err != nil {
+			return fmt.Errorf("--encoding %s: %w", cfg.Encoding, err)
+		}
+	}
+	return nil
+}
+
+// ParseAlgorithmList splits a comma-separated --algorithm value, validates
+// each entry and returns canonical names with duplicates removed.
+// The first entry is the primary algorithm.
+func ParseAlgorithmList(value string) ([]string, error) {
+	var algorithms []string
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if err := ValidateAlgorithm(part); err != nil {
+			return nil, err
+		}
+		// Normalize aliases so that everything downstream sees one spelling.
+		name := hash.CanonicalAlgorithm(part)
+		if !seen[name] {
+			seen[name] = true
+			algorithms = append(algorithms, name)
+		}
+	}
+	if len(algorithms) == 0 {
+		return nil, ValidateAlgorithm(value)
+	}
+	return algorithms, nil
 }
 
 func validateConstraints(cfg *Config) error {
@@ -81,10 +383,38 @@ func validateConstraints(cfg *Config) error {
 		return fmt.Errorf("min-size (%d) cannot be greater than max-size (%d)", cfg.MinSize, cfg.MaxSize)
 	}
 
-	if !cfg.ModifiedAfter.IsZero() && !cfg.ModifiedBefore.IsZero() {
-		if cfg.ModifiedAfter.After(cfg.ModifiedBefore) {
-			return fmt.Errorf("modified-after (%s) cannot be later than modified-before (%s)",
-				cfg.ModifiedAfter.Format("2006-01-02"), cfg.ModifiedBefore.Format("2006-01-02"))
+	if cfg.MaxDepth < 0 {
+		return fmt.Errorf("max-depth must be non-negative, got %d", cfg.MaxDepth)
+	}
+
+	if cfg.Archives {
+		if cfg.ArchiveMaxMembers < 1 {
+			return fmt.Errorf("archive-max-members must be at least 1, got %d", cfg.ArchiveMaxMembers)
+		}
+		if cfg.ArchiveMaxSize <= 0 {
+			return fmt.Errorf("archive-max-size must be positive")
+		}
+	}
+
+	if cfg.Nice < 0 || cfg.Nice > 19 {
+		return fmt.Errorf("nice must be between 0 and 19, got %d", cfg.Nice)
+	}
+	if cfg.BufferSize != 0 && (cfg.BufferSize < hash.MinBufferSize || cfg.BufferSize > hash.MaxBufferSize) {
+		return fmt.Errorf("buffer-size must be between 4KB and 64MB, got %d bytes", cfg.BufferSize)
+	}
+
+	windows := []struct {
+		name          string
+		after, before time.Time
+	}{
+		{"modified", cfg.ModifiedAfter, cfg.ModifiedBefore},
+		{"changed", cfg.ChangedAfter, cfg.ChangedBefore},
+		{"accessed", cfg.AccessedAfter, cfg.AccessedBefore},
+	}
+	for _, w := range windows {
+		if !w.after.IsZero() && !w.before.IsZero() && w.after.After(w.before) {
+			return fmt.Errorf("%s-after (%s) cannot be later than %s-before (%s)",
+				w.name, w.after.Format("2006-01-02"), w.name, w.before.Format("2006-01-02"))
 		}
 	}
 	return nil
@@ -289,6 +289,25 @@ func TestInitStreams_Coverage(t *testing.T) {
 		streams.Out.Write([]byte("hello"))
 	})
 
+	t.Run("PassthroughReport", func(t *testing.T) {
+		tmpFile, _ := os.CreateTemp("", "report")
+		defer os.Remove(tmpFile.Name())
+		tmpFile.Close()
+
+		cfg := &config.Config{OutputFile: tmpFile.Name(), Force: true, Passthrough: true}
+		streams, cleanup, err := InitStreams(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		streams.ReportWriter().Write([]byte("digest"))
+		cleanup()
+
+		data, _ := os.ReadFile(tmpFile.Name())
+		if string(data) != "digest" {
+			t.Errorf("expected the report in the output file, got %q", data)
+		}
+	})
+
 	t.Run("WithLogFile", func(t *testing.T) {
 		tmpFile, _ := os.CreateTemp("", "log")
 		defer os.Remove(tmpFile.Name())
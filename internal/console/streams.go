@@ -13,8 +13,30 @@ import (
 
 // Streams holds the configured output streams.
 type Streams struct {
+	In  io.Reader // INPUT: Data or path lists piped to chexum (stdin)
 	Out io.Writer // DATA: Result output (stdout + output file)
 	Err io.Writer // CONTEXT: Logs, progress, errors (stderr + log file)
+
+	// REPORT: Results when Out carries raw data (--passthrough).
+	// Nil means results are written to Out as usual.
+	Report io.Writer
+}
+
+// ReportWriter returns where results should be written: Report when set,
+// otherwise Out.
+func (s *Streams) ReportWriter() io.Writer {
+	if s.Report != nil {
+		return s.Report
+	}
+	return s.Out
+}
+
+// Input returns the input stream, defaulting to os.Stdin when none was set.
+func (s *Streams) Input() io.Reader {
+	if s.In == nil {
+		return os.Stdin
+	}
+	return s.In
 }
 
 // InitStreams initializes the application streams based on the configuration.
@@ -27,6 +49,7 @@ type Streams struct {
 func InitStreams(cfg *config.Config) (*Streams, func(), error) {
 	var outWriters []io.Writer
 	var errWriters []io.Writer
+	var reportWriters []io.Writer
 	var filesToClose []io.Closer
 
 	// 1. Standard Streams (Always active as the base)
@@ -36,13 +59,19 @@ func InitStreams(cfg *config.Config) (*Streams, func(), error) {
 	manager := NewOutputManager(cfg, os.Stdin)
 
 	// 2. Output File (Tee stdout)
+	// In passthrough mode stdout is the piped data itself, so the output
+	// file receives the report instead of a copy of stdout.
 	if cfg.OutputFile != "" {
 		f, err := manager.OpenOutputFile(cfg.OutputFile, cfg.Append, cfg.Force)
 		if err != nil {
 			return nil, nil, err
 		}
 		if f != nil {
-			outWriters = append(outWriters, f)
+			if cfg.Passthrough {
+				reportWriters = append(reportWriters, f)
+			} else {
+				outWriters = append(outWriters, f)
+			}
 			filesToClose = append(filesToClose, f)
 		}
 	}
@@ -61,9 +90,16 @@ func InitStreams(cfg *config.Config) (*Streams, func(), error) {
 	}
 
 	streams := &Streams{
+		In:  os.Stdin,
 		Out: io.MultiWriter(outWriters...),
 		Err: io.MultiWriter(errWriters...),
 	}
+	if cfg.Passthrough {
+		streams.Report = streams.Err
+		if len(reportWriters) > 0 {
+			streams.Report = io.MultiWriter(reportWriters...)
+		}
+	}
 
 	cleanup := func() {
 		for _, f := range filesToClose {
@@ -11,6 +11,7 @@ import (
 	"github.com/Les-El/chexum/internal/color"
 	"github.com/Les-El/chexum/internal/config"
 	"github.com/Les-El/chexum/internal/console"
+	"github.com/Les-El/chexum/internal/governor"
 	"github.com/Les-El/chexum/internal/hash"
 	"github.com/Les-El/chexum/internal/security"
 )
@@ -36,6 +37,7 @@ func RunDiagnostics(cfg *config.Config, streams *console.Streams) int {
 	wd, _ := os.Getwd()
 	fmt.Fprintf(streams.Out, "  Working Dir:  %s\n", wd)
 	fmt.Fprintf(streams.Out, "  Version:      %s\n", config.VersionText())
+	fmt.Fprintf(streams.Out, "  Limits:       %s\n", describeLimits(cfg))
 	fmt.Fprintf(streams.Out, "\n")
 
 	// 2. Algorithm Check
@@ -64,21 +66,48 @@ func RunDiagnostics(cfg *config.Config, streams *console.Streams) int {
 	return config.ExitSuccess
 }
 
+// describeLimits reports the configured resource limits, noting those this
+// platform cannot apply.
+func describeLimits(cfg *config.Config) string {
+	limits := cfg.ResourceLimits()
+	desc := governor.Describe(limits)
+	if (limits.Nice > 0 || limits.IdleIO) && !governor.Supported() {
+		desc += " (nice and I/O class " + governor.ErrUnsupported.Error() + ")"
+	}
+	return desc
+}
+
 func checkAlgorithm(algo string, c *color.Handler, streams *console.Streams) bool {
-	computer, err := hash.NewComputer(algo)
+	// Keyed algorithms are exercised with a throwaway key so the real one is
+	// never loaded into a diagnostic run.
+	var computer *hash.Computer
+	var err error
+	if hash.IsKeyed(algo) {
+		computer, err = hash.NewKeyedComputer([]byte("chexum-diagnostics"), algo)
+	} else {
+		computer, err = hash.NewComputer(algo)
+	}
 	if err != nil {
 		fmt.Fprintf(streams.Out, "  Error initializing %s: %v\n", algo, err)
 		return false
 	}
-	// Basic computation check
-	hashStr, err := computer.ComputeReader(strings.NewReader("chexum"))
-	if err != nil {
+	// Basic computation check. Sampled algorithms cannot read a stream, so
+	// they sample an in-memory buffer instead.
+	var hashStr string
+	if hash.IsSampled(algo) {
+		hashStr = computer.ComputeBytes([]byte("chexum"))
+	} else if hashStr, err = computer.ComputeReader(strings.NewReader("chexum")); err != nil {
 		fmt.Fprintf(streams.Out, "  Computation failed: %v\n", err)
 		return false
 	}
 	if len(hashStr) == 0 {
 		return false
 	}
+	if spec, ok := hash.LookupAlgorithm(computer.Algorithm()); ok && spec.Sampled {
+		fmt.Fprintf(streams.Out, "  Note: %s is a sampled fingerprint, not a full digest.\n", spec.Name)
+	} else if ok && !spec.Cryptographic {
+		fmt.Fprintf(streams.Out, "  Note: %s is a non-cryptographic checksum.\n", spec.Name)
+	}
 	return true
 }
 
@@ -29,6 +29,7 @@ func TestRunDiagnostics(t *testing.T) {
 		contains []string
 	}{
 		{"Basic info", &config.Config{Algorithm: "sha256"}, []string{"System Information", "Algorithm 'sha256' sanity check passed"}},
+		{"Resource limits", &config.Config{Algorithm: "sha256", MaxRate: 50 * 1024 * 1024, Nice: 10}, []string{"Limits:       max rate 50.0 MB/s, nice 10"}},
 		{"File inspection", &config.Config{Algorithm: "sha256", Files: []string{testFile}}, []string{"Inspecting 1 input arguments", "Checking '" + testFile + "'", "Exists: YES", "Size: 5 bytes", "Readable: YES"}},
 		{"Missing file", &config.Config{Algorithm: "sha256", Files: []string{filepath.Join(tmpDir, "missing.txt")}}, []string{"Exists: NO", "Parent directory exists: YES"}},
 		{"Hash inspection", &config.Config{Algorithm: "sha256", Hashes: []string{"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"}}, []string{"Inspecting 1 hash arguments", "Valid format: YES", "Possible algorithms: sha256"}},
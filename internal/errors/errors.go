@@ -8,11 +8,13 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/Les-El/chexum/internal/color"
+	"github.com/Les-El/chexum/internal/hash"
 	"github.com/Les-El/chexum/internal/security"
 )
 
@@ -136,12 +138,17 @@ func (h *Handler) formatStandardError(err error) string {
 
 // classifyError determines the type of a standard error.
 func classifyError(err error) ErrorType {
-	if os.IsNotExist(err) {
+	// errors.Is rather than os.IsNotExist, so wrapped errors such as
+	// hash.DiscoveryError are classified by their cause.
+	if errors.Is(err, fs.ErrNotExist) {
 		return ErrorTypeFileNotFound
 	}
-	if os.IsPermission(err) {
+	if errors.Is(err, fs.ErrPermission) {
 		return ErrorTypePermission
 	}
+	if errors.Is(err, hash.ErrArchiveLimit) {
+		return ErrorTypeInvalidInput
+	}
 	return ErrorTypeUnknown
 }
 
@@ -161,6 +168,12 @@ func (h *Handler) createMessage(err error, errType ErrorType) (message, suggesti
 		message = fmt.Sprintf("Cannot read file: %s", sanitizePath(path))
 		suggestion = "Check file permissions, or try running with elevated privileges."
 
+	case ErrorTypeInvalidInput:
+		message = sanitizeErrorMessage(errStr)
+		if errors.Is(err, hash.ErrArchiveLimit) {
+			suggestion = "If the archive is trusted, raise --archive-max-members or --archive-max-size."
+		}
+
 	default:
 		// Sanitize the error message
 		message = sanitizeErrorMessage(errStr)
@@ -10,6 +10,7 @@ import (
 	"testing/quick"
 
 	"github.com/Les-El/chexum/internal/color"
+	"github.com/Les-El/chexum/internal/hash"
 )
 
 // Feature: cli-guidelines-review, Property 4: Error messages are human-readable
@@ -489,6 +490,11 @@ func TestClassifyError(t *testing.T) {
 			err:      os.ErrPermission,
 			wantType: ErrorTypePermission,
 		},
+		{
+			name:     "archive limit",
+			err:      fmt.Errorf("a.zip!/b: %w", hash.ErrArchiveLimit),
+			wantType: ErrorTypeInvalidInput,
+		},
 		{
 			name:     "unknown error",
 			err:      errors.New("unknown"),
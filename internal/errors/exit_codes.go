@@ -2,7 +2,8 @@
 package errors
 
 import (
-	"os"
+	"errors"
+	"io/fs"
 
 	"github.com/Les-El/chexum/internal/config"
 	"github.com/Les-El/chexum/internal/hash"
@@ -13,17 +14,21 @@ import (
 
 // DetermineDiscoveryExitCode determines the exit code for errors during file discovery.
 func DetermineDiscoveryExitCode(err error) int {
-	if os.IsNotExist(err) {
+	if errors.Is(err, fs.ErrNotExist) {
 		return config.ExitFileNotFound
 	}
-	if os.IsPermission(err) {
+	if errors.Is(err, fs.ErrPermission) {
 		return config.ExitPermissionErr
 	}
 	return config.ExitPartialFailure
 }
 
 // DetermineExitCode determines the final exit code based on the operation results and configuration.
+// An interrupted run always reports ExitInterrupted, whatever the partial results say.
 func DetermineExitCode(cfg *config.Config, result *hash.Result) int {
+	if result.Incomplete {
+		return config.ExitInterrupted
+	}
 	if code := handleFailureExitCode(result); code != config.ExitSuccess {
 		return code
 	}
@@ -35,8 +40,10 @@ func handleFailureExitCode(result *hash.Result) int {
 		return config.ExitSuccess
 	}
 
-	// If all files failed, return the most specific error if possible
-	if len(result.Errors) == len(result.Entries) && len(result.Entries) > 0 {
+	// If nothing could be hashed, return the most specific error if possible.
+	// Errors can outnumber entries: paths skipped during discovery have an
+	// error but no entry.
+	if result.FilesProcessed == 0 {
 		groups := GroupErrors(result.Errors)
 		if _, ok := groups[ErrorTypePermission]; ok {
 			return config.ExitPermissionErr
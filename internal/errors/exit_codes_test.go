@@ -35,6 +35,25 @@ func TestDetermineExitCode(t *testing.T) {
 			t.Errorf("got %d", c)
 		}
 	})
+
+	t.Run("Skipped Paths", func(t *testing.T) {
+		skipped := &hash.DiscoveryError{Path: "locked", Err: os.ErrPermission}
+		res := &hash.Result{Entries: []hash.Entry{{Hash: "a"}}, FilesProcessed: 1, Errors: []error{skipped}}
+		if c := DetermineExitCode(cfg, res); c != config.ExitPartialFailure {
+			t.Errorf("with files hashed: got %d", c)
+		}
+		res = &hash.Result{Errors: []error{skipped}}
+		if c := DetermineExitCode(cfg, res); c != config.ExitPermissionErr {
+			t.Errorf("with nothing hashed: got %d", c)
+		}
+	})
+
+	t.Run("Interrupted", func(t *testing.T) {
+		res := &hash.Result{Incomplete: true, Errors: []error{fmt.Errorf("err")}}
+		if c := DetermineExitCode(cfg, res); c != config.ExitInterrupted {
+			t.Errorf("got %d", c)
+		}
+	})
 }
 
 func TestDetermineDiscoveryExitCode(t *testing.T) {
@@ -0,0 +1,72 @@
+// Package governor lowers chexum's own claim on a shared machine.
+//
+// DESIGN PRINCIPLE: Be a Friendly Neighbor
+// ----------------------------------------
+// --jobs bounds how many files are read at once, but on a shared build host
+// that is not enough: even one reader can saturate a disk that others need.
+// The governor adds three voluntary limits, all applied by chexum to itself
+// and none requiring privileges:
+//
+//  1. A bandwidth cap (--max-rate), enforced by a token bucket shared by
+//     every hashing worker (see hash.Computer.SetMaxRate).
+//  2. CPU niceness (--nice), so other processes win the scheduler.
+//  3. The idle I/O scheduling class (--idle-io), so the disk serves chexum
+//     only when nobody else is waiting for it.
+//
+// Niceness and the I/O class are Linux features; elsewhere they are
+// reported as unsupported rather than silently ignored.
+package governor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Les-El/chexum/internal/output"
+)
+
+// ErrUnsupported is returned when a limit cannot be applied on this platform.
+var ErrUnsupported = errors.New("not supported on this platform")
+
+// Limits are the resource limits chexum applies to itself.
+type Limits struct {
+	MaxRate int64 // Bytes per second read from disk; 0 means unlimited
+	Nice    int   // CPU niceness to add; 0 leaves the priority alone
+	IdleIO  bool  // Use the idle I/O scheduling class
+}
+
+// Apply lowers the CPU and I/O priority of the running process as l asks.
+// MaxRate is not applied here; it belongs to the hash.Computer. Each limit
+// is attempted even if another fails, and every failure is returned.
+func Apply(l Limits) error {
+	var errs []error
+	if l.Nice > 0 {
+		if err := setNice(l.Nice); err != nil {
+			errs = append(errs, fmt.Errorf("cannot set nice %d: %w", l.Nice, err))
+		}
+	}
+	if l.IdleIO {
+		if err := setIdleIO(); err != nil {
+			errs = append(errs, fmt.Errorf("cannot use the idle I/O class: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Describe summarises l for --verbose and --test output.
+func Describe(l Limits) string {
+	var parts []string
+	if l.MaxRate > 0 {
+		parts = append(parts, "max rate "+output.FormatBytes(l.MaxRate)+"/s")
+	}
+	if l.Nice > 0 {
+		parts = append(parts, fmt.Sprintf("nice %d", l.Nice))
+	}
+	if l.IdleIO {
+		parts = append(parts, "idle I/O class")
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
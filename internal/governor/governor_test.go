@@ -0,0 +1,30 @@
+package governor
+
+import "testing"
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits Limits
+		want   string
+	}{
+		{"none", Limits{}, "none"},
+		{"rate", Limits{MaxRate: 50 * 1024 * 1024}, "max rate 50.0 MB/s"},
+		{"small rate", Limits{MaxRate: 512}, "max rate 512 B/s"},
+		{"all", Limits{MaxRate: 1536, Nice: 10, IdleIO: true}, "max rate 1.5 KB/s, nice 10, idle I/O class"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Describe(tt.limits); got != tt.want {
+				t.Errorf("Describe() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply_NoLimits(t *testing.T) {
+	// A rate cap alone changes nothing about the process.
+	if err := Apply(Limits{MaxRate: 1024}); err != nil {
+		t.Errorf("Apply() error = %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+//go:build linux
+
+package governor
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadAverage returns the 1-minute load average from /proc/loadavg.
+func LoadAverage() (float64, bool) {
+	data, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		return 0, false
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, false
+	}
+	load, err := strconv.ParseFloat(fields[0], 64)
+	return load, err == nil
+}
+
+// MemoryPressure returns the fraction (0-1) of the last ten seconds in which
+// some task stalled waiting for memory, from /proc/pressure/memory. Kernels
+// without pressure stall information fall back to /proc/meminfo and report
+// full pressure when less than a tenth of memory is available, none otherwise.
+func MemoryPressure() (float64, bool) {
+	if data, err := os.ReadFile("/proc/pressure/memory"); err == nil {
+		// some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) < 2 || fields[0] != "some" {
+				continue
+			}
+			if v, ok := strings.CutPrefix(fields[1], "avg10="); ok {
+				if pct, err := strconv.ParseFloat(v, 64); err == nil {
+					return pct / 100, true
+				}
+			}
+		}
+	}
+	return memInfoPressure()
+}
+
+// memInfoPressure is MemoryPressure for kernels without /proc/pressure.
+func memInfoPressure() (float64, bool) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+	var total, available float64
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		value, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "MemTotal:":
+			total = value
+		case "MemAvailable:":
+			available = value
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	if available/total < 0.1 {
+		return 1, true
+	}
+	return 0, true
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package governor
+
+// LoadAverage reports false: the load average is only read on Linux.
+func LoadAverage() (float64, bool) {
+	return 0, false
+}
+
+// MemoryPressure reports false: memory pressure is only read on Linux.
+func MemoryPressure() (float64, bool) {
+	return 0, false
+}
@@ -0,0 +1,70 @@
+//go:build linux
+
+package governor
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// I/O priority encoding from linux/ioprio.h.
+const (
+	ioprioWhoProcess = 1
+	ioprioClassIdle  = 3
+	ioprioClassShift = 13
+)
+
+// Supported reports whether Nice and IdleIO can be applied on this platform.
+func Supported() bool {
+	return true
+}
+
+// setNice raises the niceness of every thread by nice. On Linux both the
+// nice value and the I/O priority belong to individual threads; threads
+// created later inherit them, so the Go runtime's existing threads are
+// updated one by one.
+func setNice(nice int) error {
+	return eachThread(func(tid int) error {
+		// The raw syscall returns 20-nice so that it is never negative.
+		raw, err := unix.Getpriority(unix.PRIO_PROCESS, tid)
+		if err != nil {
+			return err
+		}
+		return syscall.Setpriority(syscall.PRIO_PROCESS, tid, 20-raw+nice)
+	})
+}
+
+// setIdleIO moves every thread into the idle I/O scheduling class.
+func setIdleIO() error {
+	return eachThread(func(tid int) error {
+		_, _, errno := unix.Syscall(unix.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid), ioprioClassIdle<<ioprioClassShift)
+		if errno != 0 {
+			return errno
+		}
+		return nil
+	})
+}
+
+// eachThread calls fn for every thread of this process, returning the first
+// error. Without /proc it falls back to the calling thread.
+func eachThread(fn func(tid int) error) error {
+	entries, err := os.ReadDir("/proc/self/task")
+	if err != nil {
+		return fn(0)
+	}
+	var first error
+	for _, e := range entries {
+		tid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		// A thread may exit between listing and update; that is not an error.
+		if err := fn(tid); err != nil && err != syscall.ESRCH && first == nil {
+			first = err
+		}
+	}
+	return first
+}
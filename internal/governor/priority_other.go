@@ -0,0 +1,16 @@
+//go:build !linux
+
+package governor
+
+// Supported reports whether Nice and IdleIO can be applied on this platform.
+func Supported() bool {
+	return false
+}
+
+func setNice(nice int) error {
+	return ErrUnsupported
+}
+
+func setIdleIO() error {
+	return ErrUnsupported
+}
@@ -0,0 +1,220 @@
+package hash
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DESIGN PRINCIPLE: Measure, Don't Guess
+// --------------------------------------
+// No formula over the CPU count knows whether a batch lives on one spinning
+// disk, a RAID of SSDs or a network share. The adaptive controller finds out
+// instead: it starts with two active workers, measures how many bytes per
+// second the batch reads, and keeps adding workers while that improves. When
+// throughput drops it turns around, and when it stops changing it holds,
+// trying one more worker now and then in case conditions changed.
+//
+// Being a good neighbor outranks speed: while the machine's load (beyond
+// chexum's own workers) or memory pressure is too high, the controller
+// sheds a worker every interval regardless of throughput.
+
+// Adaptive controller tuning.
+const (
+	DefaultAdaptiveInterval = 500 * time.Millisecond
+
+	adaptiveStart   = 2    // Active workers when a batch starts
+	adaptiveGain    = 0.05 // Relative throughput change treated as real
+	adaptiveExplore = 10   // Intervals on a plateau before probing upwards
+)
+
+// AdaptiveOptions configures an Adaptive controller.
+type AdaptiveOptions struct {
+	Max      int           // Most workers ever active at once
+	Interval time.Duration // Time between adjustments; 0 means DefaultAdaptiveInterval
+
+	// Load returns the 1-minute load average. The controller backs off while
+	// the load minus its own active workers exceeds MaxLoad. Either being
+	// zero disables the check.
+	Load    func() (float64, bool)
+	MaxLoad float64
+
+	// MemoryPressure returns the fraction of recent time (0-1) that tasks
+	// stalled waiting for memory. The controller backs off while it exceeds
+	// MaxMemoryPressure. Either being zero disables the check.
+	MemoryPressure    func() (float64, bool)
+	MaxMemoryPressure float64
+}
+
+// AdaptiveStats describes the controller's decisions during a batch.
+type AdaptiveStats struct {
+	Active   int     // Workers active when the batch ended
+	Peak     int     // Most workers active at once
+	Max      int     // Ceiling the controller could grow to
+	BestRate float64 // Highest throughput measured, in bytes per second
+	Backoffs int     // Intervals spent shedding workers for load or memory
+}
+
+// Adaptive limits how many of ComputeBatch's workers hash at once, tuning
+// that number from measured throughput and system pressure. Attach it with
+// Computer.SetAdaptive and size the batch's pools to Max.
+type Adaptive struct {
+	opts AdaptiveOptions
+	read atomic.Int64 // Bytes read since the last interval
+
+	mu     sync.Mutex
+	ready  *sync.Cond
+	active int // Workers allowed to hash at once
+	busy   int // Workers hashing now
+
+	// Hill-climbing state, touched only by the controller goroutine.
+	dir     int     // +1 while growing, -1 while shrinking
+	prev    float64 // Throughput in the previous interval
+	plateau int     // Consecutive intervals without a real change
+
+	stats AdaptiveStats
+}
+
+// NewAdaptive returns a controller for opts.
+func NewAdaptive(opts AdaptiveOptions) *Adaptive {
+	if opts.Max < 1 {
+		opts.Max = 1
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultAdaptiveInterval
+	}
+	a := &Adaptive{opts: opts, dir: 1}
+	a.ready = sync.NewCond(&a.mu)
+	a.setActive(min(adaptiveStart, opts.Max))
+	a.stats.Max = opts.Max
+	return a
+}
+
+// SetAdaptive makes ComputeBatch run at most as many workers at once as a
+// decides. Pools should be sized to a's Max; idle workers simply wait.
+func (c *Computer) SetAdaptive(a *Adaptive) {
+	c.adaptive = a
+}
+
+// Stats returns what the controller has done so far.
+func (a *Adaptive) Stats() AdaptiveStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	stats := a.stats
+	stats.Active = a.active
+	return stats
+}
+
+// enter blocks until a worker may start hashing a file.
+func (a *Adaptive) enter() {
+	a.mu.Lock()
+	for a.busy >= a.active {
+		a.ready.Wait()
+	}
+	a.busy++
+	a.mu.Unlock()
+}
+
+// leave marks a worker as done with its file.
+func (a *Adaptive) leave() {
+	a.mu.Lock()
+	a.busy--
+	a.mu.Unlock()
+	a.ready.Signal()
+}
+
+// setActive changes the number of workers allowed to hash at once. Workers
+// already hashing finish their file when the number shrinks.
+func (a *Adaptive) setActive(n int) {
+	a.mu.Lock()
+	a.active = max(1, min(n, a.opts.Max))
+	a.stats.Peak = max(a.stats.Peak, a.active)
+	a.mu.Unlock()
+	a.ready.Broadcast()
+}
+
+// add records n bytes read.
+func (a *Adaptive) add(n int64) {
+	a.read.Add(n)
+}
+
+// run adjusts the active workers every interval until done is closed. On
+// cancellation it lifts the limit so waiting workers can drain their queues.
+func (a *Adaptive) run(ctx context.Context, done <-chan struct{}) {
+	ticker := time.NewTicker(a.opts.Interval)
+	defer ticker.Stop()
+	last := time.Now()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			a.setActive(a.opts.Max)
+			return
+		case now := <-ticker.C:
+			rate := float64(a.read.Swap(0)) / now.Sub(last).Seconds()
+			last = now
+			a.step(rate, a.pressured())
+		}
+	}
+}
+
+// pressured reports whether load or memory pressure calls for backing off.
+func (a *Adaptive) pressured() bool {
+	if a.opts.Load != nil && a.opts.MaxLoad > 0 {
+		a.mu.Lock()
+		own := float64(a.busy)
+		a.mu.Unlock()
+		if load, ok := a.opts.Load(); ok && load-own > a.opts.MaxLoad {
+			return true
+		}
+	}
+	if a.opts.MemoryPressure != nil && a.opts.MaxMemoryPressure > 0 {
+		if p, ok := a.opts.MemoryPressure(); ok && p > a.opts.MaxMemoryPressure {
+			return true
+		}
+	}
+	return false
+}
+
+// step makes one hill-climbing decision from the throughput of the last
+// interval.
+func (a *Adaptive) step(rate float64, pressured bool) {
+	a.mu.Lock()
+	active := a.active
+	if rate > a.stats.BestRate {
+		a.stats.BestRate = rate
+	}
+	if pressured {
+		a.stats.Backoffs++
+	}
+	a.mu.Unlock()
+
+	if pressured {
+		// Start climbing afresh once the pressure is gone.
+		a.dir, a.prev, a.plateau = 1, 0, 0
+		a.setActive(active - 1)
+		return
+	}
+	if rate == 0 {
+		return // Nothing was read: between files, or all served from cache
+	}
+
+	switch {
+	case a.prev == 0 || rate > a.prev*(1+adaptiveGain):
+		a.plateau = 0 // Improving (or first measurement): keep going
+	case rate < a.prev*(1-adaptiveGain):
+		a.plateau = 0
+		a.dir = -a.dir // The last step hurt: turn around
+	default:
+		a.plateau++
+		if a.plateau < adaptiveExplore {
+			a.prev = rate
+			return // Plateau: hold
+		}
+		a.plateau, a.dir = 0, 1 // Probe whether more workers help now
+	}
+	a.prev = rate
+	a.setActive(active + a.dir)
+}
@@ -0,0 +1,107 @@
+package hash
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAdaptive_Step(t *testing.T) {
+	a := NewAdaptive(AdaptiveOptions{Max: 5})
+	steps := []struct {
+		rate      float64
+		pressured bool
+		want      int
+	}{
+		{100, false, 3}, // First measurement: grow
+		{200, false, 4}, // Improving: keep growing
+		{300, false, 5}, // Improving, now at the ceiling
+		{400, false, 5}, // Cannot grow further
+		{300, false, 4}, // Throughput fell: turn around
+		{301, false, 4}, // Plateau: hold
+		{0, false, 4},   // Nothing read: no decision
+		{301, true, 3},  // Pressure: back off whatever the throughput
+		{301, true, 2},
+		{301, true, 1},
+		{301, true, 1}, // Never below one worker
+	}
+	for i, s := range steps {
+		a.step(s.rate, s.pressured)
+		if got := a.Stats().Active; got != s.want {
+			t.Fatalf("step %d (rate %v, pressured %v): active = %d, want %d", i, s.rate, s.pressured, got, s.want)
+		}
+	}
+	stats := a.Stats()
+	if stats.Peak != 5 || stats.Backoffs != 4 || stats.BestRate != 400 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
+
+func TestAdaptive_ExploresAfterPlateau(t *testing.T) {
+	a := NewAdaptive(AdaptiveOptions{Max: 8})
+	a.step(100, false) // 3
+	for i := 0; i < adaptiveExplore; i++ {
+		a.step(100, false)
+	}
+	if got := a.Stats().Active; got != 4 {
+		t.Errorf("after a long plateau active = %d, want a probe to 4", got)
+	}
+}
+
+func TestAdaptive_Pressure(t *testing.T) {
+	tests := []struct {
+		name string
+		opts AdaptiveOptions
+		want bool
+	}{
+		{"calm", AdaptiveOptions{Load: func() (float64, bool) { return 1, true }, MaxLoad: 4}, false},
+		{"busy machine", AdaptiveOptions{Load: func() (float64, bool) { return 9, true }, MaxLoad: 4}, true},
+		{"load unknown", AdaptiveOptions{Load: func() (float64, bool) { return 0, false }, MaxLoad: 4}, false},
+		{"memory", AdaptiveOptions{MemoryPressure: func() (float64, bool) { return 0.3, true }, MaxMemoryPressure: 0.1}, true},
+		{"checks disabled", AdaptiveOptions{Load: func() (float64, bool) { return 99, true }}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Max = 4
+			if got := NewAdaptive(tt.opts).pressured(); got != tt.want {
+				t.Errorf("pressured() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeBatch_Adaptive(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+	for i := 0; i < 20; i++ {
+		p := filepath.Join(dir, fmt.Sprintf("f%02d", i))
+		os.WriteFile(p, make([]byte, 64*1024), 0644)
+		files = append(files, p)
+	}
+
+	c, _ := NewComputer(AlgorithmSHA256)
+	a := NewAdaptive(AdaptiveOptions{Max: 4, Interval: time.Millisecond})
+	c.SetAdaptive(a)
+	n := 0
+	for entry := range c.ComputeBatch(context.Background(), files, 4) {
+		if entry.Error != nil {
+			t.Fatalf("ComputeBatch() error = %v", entry.Error)
+		}
+		n++
+	}
+	if n != len(files) {
+		t.Errorf("got %d entries, want %d", n, len(files))
+	}
+	if a.Stats().Peak > 4 {
+		t.Errorf("Peak = %d exceeds the ceiling", a.Stats().Peak)
+	}
+
+	// Cancelling must not leave workers waiting for a turn.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for range c.ComputeBatch(ctx, files, 4) {
+	}
+}
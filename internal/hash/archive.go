@@ -0,0 +1,251 @@
+package hash
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// ArchiveSeparator joins an archive path and a member name in entry paths,
+// e.g. "bundle.tar.gz!/bin/tool".
+const ArchiveSeparator = "!/"
+
+// ErrArchiveLimit is returned when an archive exceeds one of its ArchiveLimits.
+var ErrArchiveLimit = errors.New("archive limit exceeded")
+
+// ArchiveLimits bounds how much work a single archive may cause.
+//
+// DESIGN PRINCIPLE: Distrust Declared Sizes
+// -----------------------------------------
+// Archive headers describe sizes, but a hostile archive (a "zip bomb") can
+// lie about them or legitimately expand a few kilobytes into terabytes.
+// The limits below are therefore enforced on the bytes actually produced by
+// decompression, not on what the headers claim. Nested archives are hashed
+// as opaque members and never opened, so recursion cannot multiply the cost.
+type ArchiveLimits struct {
+	MaxMembers    int   // Maximum number of members hashed per archive
+	MaxMemberSize int64 // Maximum uncompressed size of one member
+	MaxTotalSize  int64 // Maximum uncompressed bytes read from one archive
+}
+
+// DefaultArchiveLimits returns conservative limits suitable for artifact stores.
+func DefaultArchiveLimits() ArchiveLimits {
+	return ArchiveLimits{
+		MaxMembers:    10000,
+		MaxMemberSize: 4 << 30,  // 4 GiB
+		MaxTotalSize:  16 << 30, // 16 GiB
+	}
+}
+
+// IsArchive reports whether path names an archive chexum can open,
+// judged by its extension: .tar, .tar.gz, .tgz or .zip.
+func IsArchive(path string) bool {
+	return archiveKind(path) != ""
+}
+
+// ArchiveMemberPath returns the entry path for a member inside an archive.
+func ArchiveMemberPath(archive, member string) string {
+	return archive + ArchiveSeparator + member
+}
+
+// SplitArchivePath splits an entry path produced by ArchiveMemberPath back
+// into the archive path and member name. ok is false for ordinary paths.
+func SplitArchivePath(p string) (archive, member string, ok bool) {
+	i := strings.Index(p, ArchiveSeparator)
+	if i < 0 {
+		return p, "", false
+	}
+	return p[:i], p[i+len(ArchiveSeparator):], true
+}
+
+// EnableArchives makes ComputeBatch also hash the members of every archive
+// it encounters, within the given limits.
+func (c *Computer) EnableArchives(limits ArchiveLimits) {
+	c.archives = &limits
+}
+
+func archiveKind(p string) string {
+	lower := strings.ToLower(p)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tar.gz"
+	case strings.HasSuffix(lower, ".tar"):
+		return "tar"
+	case strings.HasSuffix(lower, ".zip"):
+		return "zip"
+	}
+	return ""
+}
+
+// ComputeArchive hashes every regular file inside the archive at archivePath,
+// calling emit once per member in archive order. Member failures (including
+// limit violations) are emitted as entries carrying an Error; an error is
+// returned only when the archive itself cannot be read.
+func (c *Computer) ComputeArchive(ctx context.Context, archivePath string, limits ArchiveLimits, emit func(Entry)) error {
+	budget := &archiveBudget{limits: limits}
+	switch archiveKind(archivePath) {
+	case "zip":
+		return c.computeZip(ctx, archivePath, budget, emit)
+	case "tar", "tar.gz":
+		return c.computeTar(ctx, archivePath, budget, emit)
+	}
+	return fmt.Errorf("%s is not a supported archive", archivePath)
+}
+
+// archiveBudget tracks how much of an archive's limits have been used.
+type archiveBudget struct {
+	limits  ArchiveLimits
+	members int
+	total   int64
+}
+
+// admit records one more member, failing once MaxMembers is reached.
+func (b *archiveBudget) admit() error {
+	if b.limits.MaxMembers > 0 && b.members >= b.limits.MaxMembers {
+		return fmt.Errorf("%w: more than %d members", ErrArchiveLimit, b.limits.MaxMembers)
+	}
+	b.members++
+	return nil
+}
+
+// reader caps r at whichever of the per-member and remaining total limits is
+// smaller, plus one byte so that overflow can be detected.
+func (b *archiveBudget) reader(r io.Reader) (io.Reader, int64) {
+	limit := int64(-1)
+	if b.limits.MaxMemberSize > 0 {
+		limit = b.limits.MaxMemberSize
+	}
+	if b.limits.MaxTotalSize > 0 {
+		if remaining := b.limits.MaxTotalSize - b.total; limit < 0 || remaining < limit {
+			limit = remaining
+		}
+	}
+	if limit < 0 {
+		return r, -1
+	}
+	return io.LimitReader(r, limit+1), limit
+}
+
+func (c *Computer) computeTar(ctx context.Context, archivePath string, budget *archiveBudget, emit func(Entry)) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := c.diskReader(ctx, f)
+	if archiveKind(archivePath) == "tar.gz" {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		entry := Entry{
+			Original: ArchiveMemberPath(archivePath, cleanMemberName(hdr.Name)),
+			IsFile:   true,
+			ModTime:  hdr.ModTime,
+		}
+		ok := c.hashMember(tr, budget, &entry)
+		emit(entry)
+		if !ok && (errors.Is(entry.Error, ErrArchiveLimit) || ctx.Err() != nil) {
+			return nil
+		}
+	}
+}
+
+func (c *Computer) computeZip(ctx context.Context, archivePath string, budget *archiveBudget, emit func(Entry)) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	zr, err := zip.NewReader(c.diskReaderAt(ctx, f), info.Size())
+	if err != nil {
+		return err
+	}
+
+	for _, zf := range zr.File {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if !zf.Mode().IsRegular() {
+			continue
+		}
+		entry := Entry{
+			Original: ArchiveMemberPath(archivePath, cleanMemberName(zf.Name)),
+			IsFile:   true,
+			ModTime:  zf.Modified,
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			entry.Error = err
+			emit(entry)
+			continue
+		}
+		ok := c.hashMember(&contextReader{ctx: ctx, r: rc}, budget, &entry)
+		rc.Close()
+		emit(entry)
+		if !ok && errors.Is(entry.Error, ErrArchiveLimit) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// hashMember streams one member through the configured hashers, filling in
+// entry. It reports false if the member could not be hashed.
+func (c *Computer) hashMember(r io.Reader, budget *archiveBudget, entry *Entry) bool {
+	if err := budget.admit(); err != nil {
+		entry.Error = fmt.Errorf("%s: %w", entry.Original, err)
+		return false
+	}
+	limited, limit := budget.reader(r)
+	digests, size, err := c.digestStream(limited)
+	budget.total += size
+	if err != nil {
+		entry.Error = err
+		return false
+	}
+	if limit >= 0 && size > limit {
+		entry.Error = fmt.Errorf("%s: %w: expands beyond %d bytes", entry.Original, ErrArchiveLimit, limit)
+		return false
+	}
+	entry.Hash = digests[c.algorithm]
+	entry.Hashes = digests
+	entry.Size = size
+	entry.Algorithm = c.algorithm
+	return true
+}
+
+// cleanMemberName normalises a member name to a relative, slash-separated path
+// so entries cannot masquerade as absolute paths or escape with "..".
+func cleanMemberName(name string) string {
+	name = path.Clean("/" + strings.ReplaceAll(name, "\\", "/"))
+	return strings.TrimPrefix(name, "/")
+}
@@ -0,0 +1,165 @@
+package hash
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type archiveMember struct {
+	name string
+	data []byte
+}
+
+func writeTarGz(t *testing.T, path string, members []archiveMember) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, m := range members {
+		tw.WriteHeader(&tar.Header{Name: m.name, Mode: 0644, Size: int64(len(m.data)), Typeflag: tar.TypeReg})
+		tw.Write(m.data)
+	}
+	tw.Close()
+	gz.Close()
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeZip(t *testing.T, path string, members []archiveMember) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, m := range members {
+		w, _ := zw.Create(m.name)
+		w.Write(m.data)
+	}
+	zw.Close()
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func collectArchive(t *testing.T, c *Computer, path string, limits ArchiveLimits) []Entry {
+	t.Helper()
+	var entries []Entry
+	if err := c.ComputeArchive(context.Background(), path, limits, func(e Entry) {
+		entries = append(entries, e)
+	}); err != nil {
+		t.Fatalf("ComputeArchive(%s) error = %v", path, err)
+	}
+	return entries
+}
+
+func TestComputeArchive(t *testing.T) {
+	dir := t.TempDir()
+	members := []archiveMember{
+		{"bin/tool", []byte("tool binary")},
+		{"../../etc/passwd", []byte("sneaky")},
+	}
+	c, _ := NewComputer(AlgorithmSHA256)
+	want := c.ComputeBytes([]byte("tool binary"))
+
+	for _, name := range []string{"bundle.tar.gz", "bundle.zip"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if name == "bundle.zip" {
+				writeZip(t, path, members)
+			} else {
+				writeTarGz(t, path, members)
+			}
+
+			entries := collectArchive(t, c, path, DefaultArchiveLimits())
+			if len(entries) != 2 {
+				t.Fatalf("got %d entries, want 2", len(entries))
+			}
+			if entries[0].Original != path+"!/bin/tool" || entries[0].Hash != want {
+				t.Errorf("unexpected member entry %+v", entries[0])
+			}
+			if entries[1].Original != path+"!/etc/passwd" {
+				t.Errorf("member name not confined to the archive: %s", entries[1].Original)
+			}
+		})
+	}
+}
+
+func TestComputeArchive_Limits(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bomb.zip")
+	writeZip(t, path, []archiveMember{
+		{"a", bytes.Repeat([]byte{0}, 1<<20)},
+		{"b", []byte("small")},
+		{"c", []byte("small")},
+	})
+	c, _ := NewComputer(AlgorithmSHA256)
+
+	t.Run("member size", func(t *testing.T) {
+		entries := collectArchive(t, c, path, ArchiveLimits{MaxMemberSize: 1024})
+		if len(entries) != 1 || !errors.Is(entries[0].Error, ErrArchiveLimit) {
+			t.Errorf("expected a single limit error, got %+v", entries)
+		}
+	})
+
+	t.Run("member count", func(t *testing.T) {
+		entries := collectArchive(t, c, path, ArchiveLimits{MaxMembers: 2})
+		if len(entries) != 3 || !errors.Is(entries[2].Error, ErrArchiveLimit) {
+			t.Errorf("expected the third member to hit the limit, got %+v", entries)
+		}
+	})
+
+	t.Run("total size", func(t *testing.T) {
+		entries := collectArchive(t, c, path, ArchiveLimits{MaxTotalSize: 1<<20 + 2})
+		if len(entries) != 2 || entries[0].Error != nil || !errors.Is(entries[1].Error, ErrArchiveLimit) {
+			t.Errorf("expected the second member to exhaust the total, got %+v", entries)
+		}
+	})
+}
+
+func TestComputeBatch_Archives(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bundle.tar")
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	tw.WriteHeader(&tar.Header{Name: "x", Mode: 0644, Size: 1, Typeflag: tar.TypeReg})
+	tw.Write([]byte("x"))
+	tw.Close()
+	os.WriteFile(path, buf.Bytes(), 0644)
+
+	c, _ := NewComputer(AlgorithmSHA256)
+	seen := map[string]bool{}
+	for e := range c.ComputeBatch(context.Background(), []string{path}, 1) {
+		seen[e.Original] = true
+	}
+	if seen[path+"!/x"] {
+		t.Error("archive members should not be hashed unless enabled")
+	}
+
+	c.EnableArchives(DefaultArchiveLimits())
+	seen = map[string]bool{}
+	for e := range c.ComputeBatch(context.Background(), []string{path}, 1) {
+		if e.Error != nil {
+			t.Errorf("unexpected error: %v", e.Error)
+		}
+		seen[e.Original] = true
+	}
+	if !seen[path] || !seen[path+"!/x"] {
+		t.Errorf("expected archive and member entries, got %v", seen)
+	}
+}
+
+func TestSplitArchivePath(t *testing.T) {
+	archive, member, ok := SplitArchivePath("dist/bundle.tar.gz!/bin/tool")
+	if !ok || archive != "dist/bundle.tar.gz" || member != "bin/tool" {
+		t.Errorf("got %q %q %v", archive, member, ok)
+	}
+	if _, _, ok := SplitArchivePath("plain/file"); ok {
+		t.Error("plain paths are not archive members")
+	}
+}
@@ -1,6 +1,13 @@
 package hash
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -30,3 +37,82 @@ func BenchmarkComputeBytesMD5_1KB(b *testing.B) {
 		computer.ComputeBytes(data)
 	}
 }
+
+// benchmarkFile writes a file of size bytes for a benchmark to hash.
+func benchmarkFile(b *testing.B, size int) string {
+	b.Helper()
+	path := filepath.Join(b.TempDir(), "data")
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		b.Fatal(err)
+	}
+	return path
+}
+
+// digestWith hashes path the way ComputeFile reads a small file, opening
+// and stat'ing it and streaming it into SHA-256, but copying with copy.
+func digestWith(path string, copy func(io.Writer, io.Reader) (int64, error)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Stat(); err != nil {
+		return err
+	}
+	h := sha256.New()
+	if _, err := copy(h, &contextReader{ctx: context.Background(), r: f}); err != nil {
+		return err
+	}
+	hex.EncodeToString(h.Sum(nil))
+	return nil
+}
+
+// BenchmarkComputeFile_Small shows the allocations saved by pooling buffers
+// when a tree holds many small files. Both variants do the same system
+// calls; unpooled copies with io.Copy, as ComputeFile once did, which
+// allocates a 32 KB buffer per file.
+func BenchmarkComputeFile_Small(b *testing.B) {
+	path := benchmarkFile(b, 4*1024)
+	computer, _ := NewComputer(AlgorithmSHA256)
+
+	b.Run("unpooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := digestWith(path, io.Copy); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := digestWith(path, computer.copyBuffered); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkComputeFile_Large compares buffer sizes on a file larger than
+// any of them. Larger buffers mean fewer read system calls per byte.
+func BenchmarkComputeFile_Large(b *testing.B) {
+	const size = 64 * 1024 * 1024
+	path := benchmarkFile(b, size)
+	ctx := context.Background()
+
+	for _, bufSize := range []int{32 * 1024, DefaultBufferSize, 1024 * 1024, 4 * 1024 * 1024} {
+		b.Run(fmt.Sprintf("buffer=%dKB", bufSize/1024), func(b *testing.B) {
+			computer, _ := NewComputer(AlgorithmXXH64)
+			if err := computer.SetBufferSize(bufSize); err != nil {
+				b.Fatal(err)
+			}
+			b.SetBytes(size)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := computer.ComputeFile(ctx, path); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
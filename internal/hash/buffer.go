@@ -0,0 +1,75 @@
+package hash
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Read buffer sizes. io.Copy would allocate a fresh 32 KB buffer for every
+// file; a Computer instead reuses larger buffers from a pool, so hashing a
+// million small files allocates a handful of buffers rather than a million,
+// and a multi-gigabyte file is read with a fraction of the system calls.
+const (
+	DefaultBufferSize = 256 * 1024
+	MinBufferSize     = 4 * 1024
+	MaxBufferSize     = 64 * 1024 * 1024
+)
+
+// bufferPool hands out read buffers of one size.
+type bufferPool struct {
+	size int
+	pool sync.Pool
+}
+
+func newBufferPool(size int) *bufferPool {
+	p := &bufferPool{size: size}
+	// The pool holds pointers so that Put does not allocate a slice header.
+	p.pool.New = func() any {
+		buf := make([]byte, p.size)
+		return &buf
+	}
+	return p
+}
+
+func (p *bufferPool) get() *[]byte  { return p.pool.Get().(*[]byte) }
+func (p *bufferPool) put(b *[]byte) { p.pool.Put(b) }
+
+// defaultBuffers is shared by every Computer that keeps the default size.
+var defaultBuffers = newBufferPool(DefaultBufferSize)
+
+// SetBufferSize sets the size of the buffers used to read files and streams.
+// Zero restores DefaultBufferSize; other sizes must lie between
+// MinBufferSize and MaxBufferSize. Buffers are pooled per size, so every
+// worker of a batch shares them. Call it before hashing starts.
+func (c *Computer) SetBufferSize(size int) error {
+	switch {
+	case size == 0 || size == DefaultBufferSize:
+		c.buffers = defaultBuffers
+	case size < MinBufferSize || size > MaxBufferSize:
+		return fmt.Errorf("buffer size must be between %d and %d bytes, got %d", MinBufferSize, MaxBufferSize, size)
+	default:
+		c.buffers = newBufferPool(size)
+	}
+	return nil
+}
+
+// BufferSize returns the size of the buffers used to read files.
+func (c *Computer) BufferSize() int {
+	return c.bufferPool().size
+}
+
+func (c *Computer) bufferPool() *bufferPool {
+	if c.buffers == nil {
+		return defaultBuffers
+	}
+	return c.buffers
+}
+
+// copyBuffered is io.Copy with a pooled buffer.
+func (c *Computer) copyBuffered(w io.Writer, r io.Reader) (int64, error) {
+	pool := c.bufferPool()
+	buf := pool.get()
+	defer pool.put(buf)
+	return io.CopyBuffer(w, r, *buf)
+}
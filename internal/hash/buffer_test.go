@@ -0,0 +1,65 @@
+package hash
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetBufferSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		want    int
+		wantErr bool
+	}{
+		{"default", 0, DefaultBufferSize, false},
+		{"smallest", MinBufferSize, MinBufferSize, false},
+		{"one megabyte", 1024 * 1024, 1024 * 1024, false},
+		{"too small", 512, DefaultBufferSize, true},
+		{"too large", MaxBufferSize + 1, DefaultBufferSize, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := NewComputer(AlgorithmSHA256)
+			err := c.SetBufferSize(tt.size)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetBufferSize(%d) error = %v, wantErr %v", tt.size, err, tt.wantErr)
+			}
+			if got := c.BufferSize(); got != tt.want {
+				t.Errorf("BufferSize() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBufferSizeDoesNotChangeDigest(t *testing.T) {
+	// Larger than every buffer tried, and not a multiple of any of them.
+	data := bytes.Repeat([]byte("chexum"), 100000)
+	path := filepath.Join(t.TempDir(), "data")
+	os.WriteFile(path, data, 0644)
+
+	want, _ := NewComputer(AlgorithmSHA256, AlgorithmMD5)
+	wantDigests, _, _ := want.ComputeReaderAll(bytes.NewReader(data))
+
+	for _, size := range []int{MinBufferSize, 64 * 1024, 1024 * 1024} {
+		c, _ := NewComputer(AlgorithmSHA256, AlgorithmMD5)
+		if err := c.SetBufferSize(size); err != nil {
+			t.Fatal(err)
+		}
+		entry, err := c.ComputeFile(context.Background(), path)
+		if err != nil {
+			t.Fatalf("ComputeFile() error = %v", err)
+		}
+		for name, digest := range wantDigests {
+			if entry.Hashes[name] != digest {
+				t.Errorf("buffer %d: %s = %s, want %s", size, name, entry.Hashes[name], digest)
+			}
+		}
+		if entry.Size != int64(len(data)) {
+			t.Errorf("buffer %d: Size = %d, want %d", size, entry.Size, len(data))
+		}
+	}
+}
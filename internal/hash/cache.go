@@ -0,0 +1,94 @@
+package hash
+
+import (
+	"os"
+	"time"
+)
+
+// Cache remembers the digests of files that have not changed since they were
+// last hashed. A Computer with a cache consults it before reading a file and
+// records the fresh digests afterwards; internal/cache provides the
+// persistent implementation used by the CLI.
+//
+// Implementations must be safe for concurrent use by ComputeBatch's workers.
+type Cache interface {
+	// Lookup returns the digests recorded for exactly this version of a file.
+	Lookup(id FileID) (map[string]string, bool)
+	// Store records digests for a version of a file, adding to any digests
+	// of other algorithms already recorded for it.
+	Store(id FileID, digests map[string]string)
+}
+
+// FileID identifies one version of a file: the same inode on the same device
+// with the same size and timestamps. ChangeTime (ctime) cannot be set from
+// user space, so it also catches rewrites that restore the old mtime.
+type FileID struct {
+	Device     uint64
+	Inode      uint64
+	Size       int64
+	ModTime    int64 // Nanoseconds since the Unix epoch
+	ChangeTime int64 // Nanoseconds since the Unix epoch
+}
+
+// FileIDOf returns the identity of the file described by info. It reports
+// false for anything but regular files, and on platforms that do not expose
+// inode numbers and change times.
+func FileIDOf(info os.FileInfo) (FileID, bool) {
+	if !info.Mode().IsRegular() {
+		return FileID{}, false
+	}
+	return fileID(info)
+}
+
+// SetCache makes ComputeFile (and therefore ComputeBatch) look up unchanged
+// files in cache instead of reading them, and store what it does read.
+// Keyed algorithms bypass the cache: their digests depend on a secret the
+// cache does not know, so a computer with any keyed algorithm ignores it.
+func (c *Computer) SetCache(cache Cache) {
+	c.cache = cache
+}
+
+// cacheID returns the cache identity of an open file, or false when the
+// cache does not apply to it.
+func (c *Computer) cacheID(info os.FileInfo) (FileID, bool) {
+	if c.cache == nil || c.Keyed() {
+		return FileID{}, false
+	}
+	return FileIDOf(info)
+}
+
+// cachedDigests returns the cached digests for id when every configured
+// algorithm is present, restricted to those algorithms.
+func (c *Computer) cachedDigests(id FileID) (map[string]string, bool) {
+	cached, ok := c.cache.Lookup(id)
+	if !ok {
+		return nil, false
+	}
+	digests := make(map[string]string, len(c.specs))
+	for _, spec := range c.specs {
+		digest, ok := cached[spec.Name]
+		if !ok {
+			return nil, false
+		}
+		digests[spec.Name] = digest
+	}
+	return digests, true
+}
+
+// remember stores digests computed from file under id, provided the file
+// still has that identity. A file that changed while it was being read
+// produced digests of no single version, so they are not cached.
+func (c *Computer) remember(file *os.File, id FileID, digests map[string]string) {
+	info, err := file.Stat()
+	if err != nil {
+		return
+	}
+	if now, ok := FileIDOf(info); ok && now == id {
+		c.cache.Store(id, digests)
+	}
+}
+
+// unixNano converts seconds and nanoseconds to nanoseconds since the epoch.
+func unixNano(sec, nsec int64) int64 {
+	return time.Unix(sec, nsec).UnixNano()
+}
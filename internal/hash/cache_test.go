@@ -0,0 +1,77 @@
+package hash
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// memCache is an in-memory Cache for tests.
+type memCache struct {
+	mu      sync.Mutex
+	digests map[FileID]map[string]string
+}
+
+func (m *memCache) Lookup(id FileID) (map[string]string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d, ok := m.digests[id]
+	return d, ok
+}
+
+func (m *memCache) Store(id FileID, digests map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.digests[id] = digests
+}
+
+func TestComputer_Cache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	os.WriteFile(path, []byte("hello"), 0644)
+	info, _ := os.Stat(path)
+	id, ok := FileIDOf(info)
+	if !ok {
+		t.Skip("file identities are not available on this platform")
+	}
+
+	cache := &memCache{digests: map[FileID]map[string]string{}}
+	c, _ := NewComputer(AlgorithmSHA256)
+	c.SetCache(cache)
+	entry, err := c.ComputeFile(context.Background(), path)
+	if err != nil || entry.Cached {
+		t.Fatalf("first ComputeFile() = %+v, %v", entry, err)
+	}
+	if cache.digests[id][AlgorithmSHA256] != entry.Hash {
+		t.Fatalf("digest not stored: %v", cache.digests)
+	}
+
+	// A cached file is not read: a planted digest comes back unchanged.
+	cache.digests[id] = map[string]string{AlgorithmSHA256: "planted"}
+	var read int64
+	c.SetProgressFunc(func(n int64) { read += n })
+	for entry := range c.ComputeBatch(context.Background(), []string{path}, 1) {
+		if !entry.Cached || entry.Hash != "planted" {
+			t.Errorf("ComputeBatch() = %+v, want the cached digest", entry)
+		}
+	}
+	if read != 5 {
+		t.Errorf("progress = %d bytes, want the file's size", read)
+	}
+
+	// A cache hit must cover every algorithm.
+	both, _ := NewComputer(AlgorithmSHA256, AlgorithmMD5)
+	both.SetCache(cache)
+	if entry, _ := both.ComputeFile(context.Background(), path); entry.Cached {
+		t.Error("a hit without every algorithm should hash the file")
+	}
+
+	// Keyed digests depend on the key, so they bypass the cache.
+	keyed, _ := NewKeyedComputer([]byte("secret"), AlgorithmHMACSHA256)
+	keyed.SetCache(cache)
+	before := len(cache.digests[id])
+	if entry, _ := keyed.ComputeFile(context.Background(), path); entry.Cached || len(cache.digests[id]) != before {
+		t.Error("keyed computers must not use the cache")
+	}
+}
@@ -0,0 +1,181 @@
+package hash
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// ChecksumLine is one entry of a checksum file, as written by sha256sum,
+// md5sum and their relatives or by their --tag (BSD) option.
+//
+// DESIGN PRINCIPLE: Read What the Other Tools Write
+// -------------------------------------------------
+// ParseChecksums accepts the lines GNU coreutils accepts for --check:
+//
+//	<hex>  <name>              text mode
+//	<hex> *<name>              binary mode (the same bytes on every OS chexum runs on)
+//	<hex> <name>               a single space, as from BSD md5 -r
+//	<TAG> (<name>) = <digest>  BSD style, as from --tag
+//
+// A line starting with a backslash has its name escaped: "\\" stands for a
+// backslash, "\n" for a newline and "\r" for a carriage return. Leading
+// spaces and tabs are skipped, but the digest and name of an untagged line
+// must be separated by a space. A tag names
+// the algorithm; otherwise it is inferred from the digest's length, so a
+// file may mix algorithms.
+type ChecksumLine struct {
+	Line      int    // Line number within the checksum file, from 1
+	Path      string // Name of the file to check, unescaped
+	Hash      string // Expected digest as lowercase hex
+	Algorithm string // Canonical algorithm name
+	Binary    bool   // The line used the '*' binary-mode marker
+}
+
+// ParseChecksums reads a checksum file. Blank lines and lines starting with
+// '#' are ignored; any other line that cannot be read is counted in
+// invalid rather than reported, as coreutils does.
+//
+// An untagged digest is taken to be from the first algorithm in prefer
+// whose digests have its length, otherwise from the first candidate
+// DetectHashAlgorithm returns. Keyed and sampled algorithms are never
+// accepted: the standard tools cannot produce their digests.
+func ParseChecksums(r io.Reader, prefer []string) (lines []ChecksumLine, invalid int, err error) {
+	br := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		text, err := br.ReadString('\n')
+		if text != "" {
+			text = strings.TrimSuffix(strings.TrimSuffix(text, "\n"), "\r")
+			if line, ok := parseChecksumLine(text, prefer); ok {
+				line.Line = n
+				lines = append(lines, line)
+			} else if strings.TrimSpace(text) != "" && !strings.HasPrefix(text, "#") {
+				invalid++
+			}
+		}
+		if err == io.EOF {
+			return lines, invalid, nil
+		}
+		if err != nil {
+			return lines, invalid, err
+		}
+	}
+}
+
+// parseChecksumLine parses one line of a checksum file, without its line
+// ending.
+func parseChecksumLine(s string, prefer []string) (ChecksumLine, bool) {
+	s = strings.TrimLeft(s, " \t")
+	escaped := strings.HasPrefix(s, `\`)
+	if escaped {
+		s = s[1:]
+	}
+
+	line, ok := ChecksumLine{}, false
+	if tag, rest, found := strings.Cut(s, " ("); found && !strings.ContainsAny(tag, " \t") {
+		line, ok = parseTaggedLine(tag, rest)
+	}
+	if !ok {
+		line, ok = parseUntaggedLine(s, prefer)
+	}
+	if ok && escaped {
+		line.Path, ok = unescapeChecksumPath(line.Path)
+	}
+	return line, ok && line.Path != ""
+}
+
+// parseTaggedLine parses the part of a BSD-style line after "TAG (". The
+// name may itself contain ") = ", so the last one ends it. The digest may
+// be in any encoding DecodeDigest reads, as cksum --base64 writes.
+func parseTaggedLine(tag, rest string) (ChecksumLine, bool) {
+	i := strings.LastIndex(rest, ") = ")
+	if i < 0 {
+		return ChecksumLine{}, false
+	}
+	spec, ok := LookupAlgorithm(strings.ToLower(tag))
+	if !ok || spec.Keyed || spec.Sampled {
+		return ChecksumLine{}, false
+	}
+	d, ok := DecodeDigest(strings.TrimRight(rest[i+len(") = "):], " \t"))
+	if !ok || !d.Fits(spec.Name) {
+		return ChecksumLine{}, false
+	}
+	return ChecksumLine{Path: rest[:i], Hash: d.Hex, Algorithm: spec.Name}, true
+}
+
+// parseUntaggedLine parses a "<hex>  <name>" line. The name is everything
+// after the mode marker, spaces included. As with coreutils, only a space
+// separates the digest from the name; a tab makes the line invalid.
+func parseUntaggedLine(s string, prefer []string) (ChecksumLine, bool) {
+	i := strings.IndexByte(s, ' ')
+	if i < 0 {
+		return ChecksumLine{}, false
+	}
+	digest, name := s[:i], s[i+1:]
+	algorithm := checksumAlgorithm(digest, prefer)
+	if algorithm == "" {
+		return ChecksumLine{}, false
+	}
+	line := ChecksumLine{Hash: strings.ToLower(digest), Algorithm: algorithm}
+	if strings.HasPrefix(name, " ") || strings.HasPrefix(name, "*") {
+		line.Binary = name[0] == '*'
+		name = name[1:]
+	}
+	line.Path = name
+	return line, true
+}
+
+// checksumAlgorithm picks the algorithm of an untagged hex digest; see
+// ParseChecksums.
+func checksumAlgorithm(digest string, prefer []string) string {
+	candidates := DetectHashAlgorithm(digest)
+	if len(candidates) == 0 {
+		return ""
+	}
+	for _, name := range prefer {
+		if spec, ok := LookupAlgorithm(name); ok && !spec.Keyed && !spec.Sampled && spec.HexLength() == len(digest) {
+			return spec.Name
+		}
+	}
+	return candidates[0]
+}
+
+// unescapeChecksumPath undoes the escaping of a name on a line that starts
+// with a backslash. ok is false for an escape coreutils never writes.
+func unescapeChecksumPath(s string) (string, bool) {
+	if !strings.Contains(s, `\`) {
+		return s, true
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			sb.WriteByte(s[i])
+			continue
+		}
+		if i++; i == len(s) {
+			return "", false
+		}
+		switch s[i] {
+		case '\\':
+			sb.WriteByte('\\')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		default:
+			return "", false
+		}
+	}
+	return sb.String(), true
+}
+
+// EscapeChecksumPath renders name as sha256sum -c does on a result line.
+// A name containing a backslash, newline or carriage return is escaped;
+// escaped reports whether the line must then start with a backslash.
+func EscapeChecksumPath(name string) (s string, escaped bool) {
+	if !strings.ContainsAny(name, `\`+"\n\r") {
+		return name, false
+	}
+	r := strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`)
+	return r.Replace(name), true
+}
@@ -0,0 +1,107 @@
+package hash
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseChecksums(t *testing.T) {
+	const (
+		sha256Empty = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+		md5Empty    = "d41d8cd98f00b204e9800998ecf8427e"
+		sha512Abc   = "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f"
+	)
+	tests := []struct {
+		name   string
+		line   string
+		prefer []string
+		want   ChecksumLine
+		ok     bool
+	}{
+		{"text mode", sha256Empty + "  a file", nil, ChecksumLine{Path: "a file", Hash: sha256Empty, Algorithm: "sha256"}, true},
+		{"leading whitespace", " \t" + md5Empty + "  a", nil, ChecksumLine{Path: "a", Hash: md5Empty, Algorithm: "md5"}, true},
+		{"binary mode", md5Empty + " *a", nil, ChecksumLine{Path: "a", Hash: md5Empty, Algorithm: "md5", Binary: true}, true},
+		{"single space", md5Empty + " a", nil, ChecksumLine{Path: "a", Hash: md5Empty, Algorithm: "md5"}, true},
+		{"leading spaces kept in the name", md5Empty + "   a", nil, ChecksumLine{Path: " a", Hash: md5Empty, Algorithm: "md5"}, true},
+		{"uppercase hex", strings.ToUpper(md5Empty) + "  a", nil, ChecksumLine{Path: "a", Hash: md5Empty, Algorithm: "md5"}, true},
+		{"length picks the algorithm", sha512Abc + "  a", nil, ChecksumLine{Path: "a", Hash: sha512Abc, Algorithm: "sha512"}, true},
+		{"preferred algorithm", sha256Empty + "  a", []string{"blake3"}, ChecksumLine{Path: "a", Hash: sha256Empty, Algorithm: "blake3"}, true},
+		{"preference of another length", sha256Empty + "  a", []string{"md5"}, ChecksumLine{Path: "a", Hash: sha256Empty, Algorithm: "sha256"}, true},
+		{"keyed preference ignored", sha256Empty + "  a", []string{"hmac-sha256"}, ChecksumLine{Path: "a", Hash: sha256Empty, Algorithm: "sha256"}, true},
+		{"escaped name", `\` + md5Empty + `  a\\b\nc`, nil, ChecksumLine{Path: "a\\b\nc", Hash: md5Empty, Algorithm: "md5"}, true},
+		{"tag", "SHA256 (a (1)) = " + sha256Empty, nil, ChecksumLine{Path: "a (1)", Hash: sha256Empty, Algorithm: "sha256"}, true},
+		{"tag with a name containing the separator", "MD5 (a) = b) = " + md5Empty, nil, ChecksumLine{Path: "a) = b", Hash: md5Empty, Algorithm: "md5"}, true},
+		{"tag overrides length detection", "BLAKE3 (a) = " + sha256Empty, nil, ChecksumLine{Path: "a", Hash: sha256Empty, Algorithm: "blake3"}, true},
+		{"tag alias", "BLAKE2b-512 (a) = " + sha512Abc, nil, ChecksumLine{Path: "a", Hash: sha512Abc, Algorithm: "blake2b"}, true},
+		{"escaped tag", `\MD5 (a\nb) = ` + md5Empty, nil, ChecksumLine{Path: "a\nb", Hash: md5Empty, Algorithm: "md5"}, true},
+		{"base64 tag", "SHA256 (a) = 47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=", nil, ChecksumLine{Path: "a", Hash: sha256Empty, Algorithm: "sha256"}, true},
+
+		{"no name", md5Empty + "  ", nil, ChecksumLine{}, false},
+		{"no separator", md5Empty, nil, ChecksumLine{}, false},
+		{"not hex", strings.Repeat("g", 32) + "  a", nil, ChecksumLine{}, false},
+		{"unknown length", "abc  a", nil, ChecksumLine{}, false},
+		{"tag of the wrong length", "SHA256 (a) = " + md5Empty, nil, ChecksumLine{}, false},
+		{"unknown tag", "FOO (a) = " + md5Empty, nil, ChecksumLine{}, false},
+		{"keyed tag", "HMAC-SHA256 (a) = " + sha256Empty, nil, ChecksumLine{}, false},
+		{"bad escape", `\` + md5Empty + `  a\tb`, nil, ChecksumLine{}, false},
+		{"tab separator", md5Empty + "\ta", nil, ChecksumLine{}, false},
+		{"tab before the name", md5Empty + "\t a", nil, ChecksumLine{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, invalid, err := ParseChecksums(strings.NewReader(tt.line+"\n"), tt.prefer)
+			if err != nil {
+				t.Fatalf("ParseChecksums() error = %v", err)
+			}
+			if !tt.ok {
+				if len(lines) != 0 || invalid != 1 {
+					t.Errorf("ParseChecksums(%q) = %+v, %d invalid; want 1 invalid", tt.line, lines, invalid)
+				}
+				return
+			}
+			tt.want.Line = 1
+			if invalid != 0 || len(lines) != 1 || lines[0] != tt.want {
+				t.Errorf("ParseChecksums(%q) = %+v, %d invalid; want %+v", tt.line, lines, invalid, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseChecksums_File(t *testing.T) {
+	const md5Empty = "d41d8cd98f00b204e9800998ecf8427e"
+	input := "# made by md5sum\r\n" +
+		md5Empty + "  a\r\n" +
+		"\n" +
+		"garbage\n" +
+		md5Empty + "  b" // no final newline
+	lines, invalid, err := ParseChecksums(strings.NewReader(input), nil)
+	if err != nil {
+		t.Fatalf("ParseChecksums() error = %v", err)
+	}
+	if invalid != 1 {
+		t.Errorf("invalid = %d, want 1", invalid)
+	}
+	if len(lines) != 2 || lines[0].Path != "a" || lines[0].Line != 2 || lines[1].Path != "b" || lines[1].Line != 5 {
+		t.Errorf("lines = %+v, want a on line 2 and b on line 5", lines)
+	}
+}
+
+func TestEscapeChecksumPath(t *testing.T) {
+	tests := []struct {
+		name, want string
+		escaped    bool
+	}{
+		{"plain name", "plain name", false},
+		{`a\b`, `a\\b`, true},
+		{"a\\b\nc\r", `a\\b\nc\r`, true},
+	}
+	for _, tt := range tests {
+		got, escaped := EscapeChecksumPath(tt.name)
+		if got != tt.want || escaped != tt.escaped {
+			t.Errorf("EscapeChecksumPath(%q) = %q, %v; want %q, %v", tt.name, got, escaped, tt.want, tt.escaped)
+		}
+		if back, ok := unescapeChecksumPath(got); escaped && (!ok || back != tt.name) {
+			t.Errorf("unescapeChecksumPath(%q) = %q, %v; want %q", got, back, ok, tt.name)
+		}
+	}
+}
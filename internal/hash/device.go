@@ -0,0 +1,131 @@
+package hash
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// DESIGN PRINCIPLE: One Pool per Device
+// -------------------------------------
+// The right number of concurrent readers depends on the disk, not the CPU.
+// A spinning disk seeks itself to a crawl under thirty readers, while an
+// NVMe drive only reaches full speed with a deep queue. ComputeBatch
+// therefore routes every file to a worker pool for the device it lives on
+// (st_dev), and each pool is sized independently: a slow USB disk with one
+// reader does not hold back the SSD next to it. Pools nobody sized share
+// the batch's worker count, so a tree spanning many devices does not
+// multiply the number of readers.
+
+// DeviceWorkers returns how many files ComputeBatch may read at once from
+// the given device. Zero or less means the device shares the batch's
+// default worker count with the other devices that have no limit.
+type DeviceWorkers func(device uint64) int
+
+// SetDeviceWorkers sizes ComputeBatch's per-device worker pools with fn.
+// Without it every device shares the worker count passed to ComputeBatch.
+func (c *Computer) SetDeviceWorkers(fn DeviceWorkers) {
+	c.deviceWorkers = fn
+}
+
+// DeviceOf returns the device holding path, following symlinks. It reports
+// false when path cannot be stat'ed or the platform has no device numbers.
+func DeviceOf(path string) (uint64, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, false
+	}
+	id, ok := fileID(info)
+	return id.Device, ok
+}
+
+// MountDevice returns the device of the filesystem mounted at path. It
+// reports false when path is not a mount point, i.e. its parent lives on
+// the same device, so a limit for an unmounted drive's empty mount
+// directory never applies to the filesystem beneath it.
+func MountDevice(path string) (uint64, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return 0, false
+	}
+	device, ok := DeviceOf(abs)
+	if !ok {
+		return 0, false
+	}
+	parent := filepath.Dir(abs)
+	if parent == abs {
+		return device, true // The root directory
+	}
+	if parentDevice, ok := DeviceOf(parent); ok && parentDevice == device {
+		return 0, false
+	}
+	return device, true
+}
+
+// deviceKey groups files by device; files whose device is unknown share
+// one pool of their own.
+type deviceKey struct {
+	device uint64
+	known  bool
+}
+
+// workersFor returns the size configured for key's pool, or zero when the
+// pool shares the default worker count.
+func (c *Computer) workersFor(key deviceKey) int {
+	if c.deviceWorkers != nil && key.known {
+		return c.deviceWorkers(key.device)
+	}
+	return 0
+}
+
+// queue is an unbounded FIFO shared by a pool of workers: the paths for one
+// device's hashing workers, or the directories still to be read during
+// discovery. Pushing never blocks, so the dispatcher never waits on a slow
+// device while a fast one sits idle, and a walker can queue subdirectories
+// without waiting for another walker to take them.
+type queue[T any] struct {
+	mu     sync.Mutex
+	ready  *sync.Cond
+	items  []T
+	closed bool
+}
+
+func newQueue[T any]() *queue[T] {
+	q := &queue[T]{}
+	q.ready = sync.NewCond(&q.mu)
+	return q
+}
+
+// push adds item to the queue.
+func (q *queue[T]) push(item T) {
+	q.mu.Lock()
+	q.items = append(q.items, item)
+	q.mu.Unlock()
+	q.ready.Signal()
+}
+
+// close tells the workers that no more items will arrive.
+func (q *queue[T]) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.ready.Broadcast()
+}
+
+// pop waits for the next item. It reports false once the queue is closed
+// and empty.
+func (q *queue[T]) pop() (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.items) == 0 && !q.closed {
+		q.ready.Wait()
+	}
+	var zero T
+	if len(q.items) == 0 {
+		return zero, false
+	}
+	item := q.items[0]
+	q.items[0] = zero
+	q.items = q.items[1:]
+	return item, true
+}
@@ -0,0 +1,135 @@
+package hash
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestQueue(t *testing.T) {
+	q := newQueue[string]()
+	q.push("a")
+	q.push("b")
+	q.close()
+	for _, want := range []string{"a", "b"} {
+		if got, ok := q.pop(); !ok || got != want {
+			t.Errorf("pop() = %q, %v, want %q", got, ok, want)
+		}
+	}
+	if _, ok := q.pop(); ok {
+		t.Error("pop() on a closed, empty queue should report false")
+	}
+}
+
+func TestComputeBatch_DeviceWorkers(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+	for _, name := range []string{"a", "b", "c"} {
+		p := filepath.Join(dir, name)
+		os.WriteFile(p, []byte(name), 0644)
+		files = append(files, p)
+	}
+	files = append(files, filepath.Join(dir, "missing"))
+	device, ok := DeviceOf(dir)
+	if !ok {
+		t.Skip("device numbers are not available on this platform")
+	}
+
+	var mu sync.Mutex
+	asked := map[uint64]int{}
+	c, _ := NewComputer(AlgorithmSHA256)
+	c.SetDeviceWorkers(func(d uint64) int {
+		mu.Lock()
+		defer mu.Unlock()
+		asked[d]++
+		return 1
+	})
+
+	var hashed, failed int
+	for entry := range c.ComputeBatch(context.Background(), files, 4) {
+		if entry.Error != nil {
+			failed++
+		} else {
+			hashed++
+		}
+	}
+	if hashed != 3 || failed != 1 {
+		t.Errorf("hashed %d, failed %d; want 3 and 1", hashed, failed)
+	}
+	// One pool per device: the limit is asked for once, and never for the
+	// missing file, whose device is unknown.
+	if len(asked) != 1 || asked[device] != 1 {
+		t.Errorf("DeviceWorkers calls = %v, want one for device %d", asked, device)
+	}
+}
+
+func TestComputeFiles_SharedWorkers(t *testing.T) {
+	dir := t.TempDir()
+	files := make(chan Found, 12)
+	for i := 0; i < cap(files); i++ {
+		p := filepath.Join(dir, string(rune('a'+i)))
+		os.WriteFile(p, []byte("data"), 0644)
+		// Three devices nobody sized: together they get the two workers.
+		files <- Found{Path: p, device: deviceKey{device: uint64(i % 3), known: true}}
+	}
+	close(files)
+
+	var inFlight, most atomic.Int32
+	c, _ := NewComputer(AlgorithmSHA256)
+	c.SetProgressFunc(func(int64) {
+		n := inFlight.Add(1)
+		for m := most.Load(); n > m && !most.CompareAndSwap(m, n); m = most.Load() {
+		}
+		time.Sleep(5 * time.Millisecond)
+		inFlight.Add(-1)
+	})
+	var hashed int
+	for entry := range c.ComputeFiles(context.Background(), files, 2) {
+		if entry.Error != nil {
+			t.Errorf("%s: %v", entry.Original, entry.Error)
+		}
+		hashed++
+	}
+	if hashed != 12 {
+		t.Errorf("hashed %d files, want 12", hashed)
+	}
+	if m := most.Load(); m > 2 {
+		t.Errorf("%d files were read at once, want at most 2", m)
+	}
+}
+
+func TestDiscover_Device(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a"), []byte("a"), 0644)
+	device, ok := DeviceOf(dir)
+	if !ok {
+		t.Skip("device numbers are not available on this platform")
+	}
+	// ComputeFiles routes discovered files without stat'ing them again.
+	for found := range Discover(context.Background(), []string{dir}, DiscoveryOptions{}).Files {
+		if found.device != (deviceKey{device: device, known: true}) {
+			t.Errorf("%s: device = %+v, want %d", found.Path, found.device, device)
+		}
+	}
+}
+
+func TestMountDevice(t *testing.T) {
+	if _, ok := DeviceOf("/"); !ok {
+		t.Skip("device numbers are not available on this platform")
+	}
+	if _, ok := MountDevice("/"); !ok {
+		t.Error("the root directory is always a mount point")
+	}
+	dir := filepath.Join(t.TempDir(), "sub")
+	os.Mkdir(dir, 0755)
+	if _, ok := MountDevice(dir); ok {
+		t.Error("a plain subdirectory is not a mount point")
+	}
+	if _, ok := MountDevice(filepath.Join(dir, "missing")); ok {
+		t.Error("a missing path is not a mount point")
+	}
+}
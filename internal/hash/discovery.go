@@ -3,73 +3,418 @@
 // DESIGN PRINCIPLE: Smart Traversal
 // ---------------------------------
 // Scanning large directory trees can be slow and resource-intensive.
-// This package uses a depth-first traversal (filepath.Walk) combined
-// with early-exit pruning for hidden directories and non-recursive
-// requests. This ensures we only "stat" the files that actually
-// matter to the user.
+// Discovery therefore streams: several walkers read directories in parallel
+// with os.ReadDir and report each file the moment it qualifies, so hashing
+// starts while the rest of the tree is still being walked. Directory entries
+// already carry their type, so only files that might be hashed are stat'ed;
+// hidden, ignored and excluded directories and, without recursion,
+// subdirectories are pruned before they are ever opened.
+//
+// Ignore files (.chexumignore, and optionally .gitignore and
+// .git/info/exclude) are read as each directory is listed and apply to
+// everything below it, so `chexum -r .` in a repository hashes exactly what
+// the repository ships.
+//
+// A path that cannot be read (permission denied, removed mid-walk, an I/O
+// error) is recorded and skipped; the rest of the tree is still walked. One
+// unreadable subdirectory must not cost the user every other result.
+//
+// Symbolic links given as arguments are followed; those found while walking
+// are not, unless FollowSymlinks says so, and are noted as skipped rather
+// than dropped silently. A followed link that leads back to a directory
+// above it is caught by device and inode, so a link loop cannot make the
+// walk endless.
 package hash
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
+// DefaultWalkers is how many directories Discover reads at once unless
+// DiscoveryOptions.Walkers says otherwise.
+const DefaultWalkers = 8
+
 // DiscoveryOptions defines criteria for file discovery.
 // It combines behavior flags (Recursive, Hidden) with filtering
-// rules (Include, Exclude, Size, Date).
+// rules (Include, Exclude, Size, Date and the predicates below). A file
+// must pass every rule that is set; within a list, one match is enough.
 type DiscoveryOptions struct {
 	Recursive      bool
 	Hidden         bool
+	IgnoreFiles    bool // Honour .chexumignore files
+	GitIgnore      bool // Honour .gitignore files and .git/info/exclude
 	Include        []string
 	Exclude        []string
 	MinSize        int64
 	MaxSize        int64
 	ModifiedAfter  time.Time
 	ModifiedBefore time.Time
+	Walkers        int  // Directories read in parallel; 0 means DefaultWalkers
+	MaxDepth       int  // Levels below a root to report files from; 0 means no limit
+	OneFileSystem  bool // Do not descend into directories on other devices than their root
+	FollowSymlinks bool // Follow symbolic links found while walking
+
+	PathRegex      []*regexp.Regexp // Matched against the slash-separated path relative to the root
+	Types          []string         // MIME patterns matched against SniffType (see ResolveType)
+	Owners         []uint32         // User IDs (Unix only)
+	Groups         []uint32         // Group IDs (Unix only)
+	Perm           PermFilter
+	ChangedAfter   time.Time // Inode change time (ctime) window (Unix only)
+	ChangedBefore  time.Time
+	AccessedAfter  time.Time // Access time (atime) window (Unix only)
+	AccessedBefore time.Time
+}
+
+// Found is a file reported by Discover.
+type Found struct {
+	Path string // As it should be opened: the root joined with the relative path
+	Size int64  // Size when discovered; 0 for the "-" stdin marker
+	Root int    // Index of the root argument the file was found under
+
+	device deviceKey // From the stat that qualified the file, for ComputeFiles
+}
+
+// Discovery is a walk in progress, started by Discover.
+type Discovery struct {
+	// Files receives every qualifying file in no particular order. It is
+	// closed once the walk completes or its context is cancelled.
+	Files <-chan Found
+
+	mu      sync.Mutex
+	errs    []error
+	skipped []SkippedPath
+}
+
+// Errors returns one error for each path the walk could not read, sorted
+// by message. It is only complete once Files has been closed.
+func (d *Discovery) Errors() []error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	errs := append([]error(nil), d.errs...)
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errs
+}
+
+// record notes that path could not be read.
+func (d *Discovery) record(path string, err error) {
+	d.mu.Lock()
+	d.errs = append(d.errs, &DiscoveryError{Path: path, Err: err})
+	d.mu.Unlock()
+}
+
+// DiscoveryError is a path that discovery skipped because it could not be
+// read. Err is usually an *fs.PathError, whose message names the path.
+type DiscoveryError struct {
+	Path string
+	Err  error
+}
+
+// Error implements error.
+func (e *DiscoveryError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error, so errors.Is(err, fs.ErrPermission)
+// and similar checks see through it.
+func (e *DiscoveryError) Unwrap() error {
+	return e.Err
+}
+
+// DiscoveryErrors lists the paths a walk could not read. DiscoverFiles
+// returns it alongside every file it could discover.
+type DiscoveryErrors []error
+
+// Error implements error, summarising the list by its first entry.
+func (e DiscoveryErrors) Error() string {
+	switch len(e) {
+	case 0:
+		return "no walk errors"
+	case 1:
+		return e[0].Error()
+	}
+	return fmt.Sprintf("%v (and %d more unreadable paths)", e[0], len(e)-1)
+}
+
+// Unwrap returns the individual errors for errors.Is and errors.As.
+func (e DiscoveryErrors) Unwrap() []error {
+	return e
+}
+
+// dirJob is a directory waiting to be read.
+type dirJob struct {
+	path      string
+	root      string
+	index     int
+	ignore    *ignoreList // Rules from the directories above
+	depth     int         // Levels below the root; files in it are one deeper
+	device    uint64      // The root's device, for OneFileSystem
+	ancestors *dirChain   // This directory and those above it, for FollowSymlinks
 }
 
-// DiscoverFiles finds all files in the given paths based on options.
+// Discover starts walking paths in the background and returns at once.
 //
 // PROCESS:
-// 1. If no paths provided, default to current directory (".").
-// 2. Iterate through each root path.
-// 3. Handle the special "-" stdin marker by passing it through.
-// 4. Perform a recursive walk starting at each root.
-// 5. Apply early-pruning and filters via handlePath.
-func DiscoverFiles(paths []string, opts DiscoveryOptions) ([]string, error) {
+//  1. If no paths provided, default to current directory (".").
+//  2. Check each root path, following it if it is a symbolic link,
+//     reporting files given directly and queueing directories. The special
+//     "-" stdin marker is passed through.
+//  3. Walkers read queued directories in parallel, queueing subdirectories
+//     as they go, until none are left.
+//  4. Apply early-pruning and filters via handlePath.
+//
+// Paths that cannot be read are skipped and reported by Errors; the walk
+// carries on with everything else. Cancelling ctx stops the walk.
+func Discover(ctx context.Context, paths []string, opts DiscoveryOptions) *Discovery {
 	if len(paths) == 0 {
 		paths = []string{"."}
 	}
+	out := make(chan Found, 1024)
+	d := &Discovery{Files: out}
+	go d.walk(ctx, paths, opts, out)
+	return d
+}
+
+// walk runs the whole discovery and closes out when it is done.
+func (d *Discovery) walk(ctx context.Context, paths []string, opts DiscoveryOptions, out chan<- Found) {
+	defer close(out)
+	emit := func(index int) func(string, fs.FileInfo) {
+		return func(path string, info fs.FileInfo) {
+			found := Found{Path: path, Root: index}
+			if info != nil {
+				id, known := fileID(info)
+				found.Size, found.device = info.Size(), deviceKey{device: id.Device, known: known}
+			}
+			select {
+			case out <- found:
+			case <-ctx.Done():
+			}
+		}
+	}
 
-	var discovered []string
-	for _, root := range paths {
+	jobs := newQueue[dirJob]()
+	var pending sync.WaitGroup
+	for i, root := range paths {
 		if root == "-" {
 			// Special case: stdin marker is not a file path but a source signal.
-			discovered = append(discovered, root)
+			emit(i)(root, nil)
 			continue
 		}
+		info, err := os.Stat(root)
+		if err != nil {
+			d.record(root, err)
+			continue
+		}
+		if err := handlePath(root, root, fs.FileInfoToDirEntry(info), nil, opts, emit(i)); err != nil {
+			d.record(root, err)
+			continue
+		}
+		if info.IsDir() {
+			id := identify(root, info)
+			pending.Add(1)
+			jobs.push(dirJob{
+				path:      root,
+				root:      root,
+				index:     i,
+				ignore:    rootIgnores(root, opts, d.record),
+				device:    id.device,
+				ancestors: &dirChain{id: id},
+			})
+		}
+	}
+
+	// The queue closes once every directory queued has been read, which
+	// includes the subdirectories queued while reading them.
+	go func() {
+		pending.Wait()
+		jobs.close()
+	}()
+
+	walkers := opts.Walkers
+	if walkers <= 0 {
+		walkers = DefaultWalkers
+	}
+	var wg sync.WaitGroup
+	wg.Add(walkers)
+	for i := 0; i < walkers; i++ {
+		go func() {
+			defer wg.Done()
+			for {
+				job, ok := jobs.pop()
+				if !ok {
+					return
+				}
+				if ctx.Err() == nil {
+					readDir(ctx, job, opts, emit(job.index), d.record, d.skip, func(sub dirJob) {
+						pending.Add(1)
+						jobs.push(sub)
+					})
+				}
+				pending.Done()
+			}
+		}()
+	}
+	wg.Wait()
+}
 
-		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+// readDir handles every entry of one directory, handing subdirectories
+// worth descending into to descend, paths it cannot read to record and
+// paths it passes over on purpose to skip.
+func readDir(ctx context.Context, job dirJob, opts DiscoveryOptions, emit func(string, fs.FileInfo), record func(string, error), skip func(string, string), descend func(dirJob)) {
+	// A directory that fails part way through still yields the entries read
+	// before the failure.
+	entries, err := os.ReadDir(job.path)
+	if err != nil {
+		record(job.path, err)
+	}
+	ignore := job.ignore
+	if opts.IgnoreFiles || opts.GitIgnore {
+		rel, _ := filepath.Rel(job.root, job.path)
+		ignore = loadIgnores(job.path, filepath.ToSlash(rel), entries, ignore, opts, record)
+	}
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return
+		}
+		path := filepath.Join(job.path, entry.Name())
+
+		// A followed link stands for its target from here on.
+		if opts.FollowSymlinks && entry.Type()&fs.ModeSymlink != 0 {
+			info, err := os.Stat(path)
+			if errors.Is(err, fs.ErrNotExist) {
+				skip(path, SkipBrokenLink)
+				continue
+			}
 			if err != nil {
-				// We return errors immediately to fulfill the "Robustness" mandate.
-				// If we can't access part of the tree, the user should know why.
-				return err
+				record(path, err)
+				continue
 			}
-			return handlePath(path, root, info, opts, &discovered)
-		})
+			entry = fs.FileInfoToDirEntry(info)
+		}
+
+		err := handlePath(path, job.root, entry, ignore, opts, emit)
+		if err == filepath.SkipDir {
+			continue
+		}
+		if err == errSymlink {
+			skip(path, SkipSymlink)
+			continue
+		}
 		if err != nil {
-			return nil, err
+			record(path, err)
+			continue
+		}
+		if !entry.IsDir() {
+			continue
 		}
+
+		// Files in the subdirectory would lie job.depth+2 levels down.
+		sub := dirJob{
+			path:      path,
+			root:      job.root,
+			index:     job.index,
+			ignore:    ignore,
+			depth:     job.depth + 1,
+			device:    job.device,
+			ancestors: job.ancestors,
+		}
+		if opts.MaxDepth > 0 && sub.depth >= opts.MaxDepth {
+			continue
+		}
+		if opts.OneFileSystem || opts.FollowSymlinks {
+			info, err := entry.Info()
+			if err != nil {
+				record(path, err)
+				continue
+			}
+			id := identify(path, info)
+			if opts.OneFileSystem && id.device != job.device {
+				skip(path, SkipOtherDevice)
+				continue
+			}
+			if job.ancestors.contains(id) {
+				skip(path, SkipLoop)
+				continue
+			}
+			sub.ancestors = &dirChain{id: id, parent: job.ancestors}
+		}
+		descend(sub)
+	}
+}
+
+// errSymlink is returned by handlePath for a symbolic link it will not
+// follow, so that readDir can report it.
+var errSymlink = errors.New("symbolic link not followed")
+
+// DiscoverFiles finds all files in the given paths based on options and
+// returns them in the order a depth-first walk of each root, taken in
+// turn, would visit them (see SortFound). If some paths could not be read,
+// the files that could be found are returned with a DiscoveryErrors.
+// When ctx is cancelled the walk stops and ctx.Err() is returned.
+func DiscoverFiles(ctx context.Context, paths []string, opts DiscoveryOptions) ([]string, error) {
+	d := Discover(ctx, paths, opts)
+	var found []Found
+	for f := range d.Files {
+		found = append(found, f)
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	SortFound(found)
+	discovered := make([]string, len(found))
+	for i, f := range found {
+		discovered[i] = f.Path
+	}
+	if errs := d.Errors(); len(errs) > 0 {
+		return discovered, DiscoveryErrors(errs)
 	}
 	return discovered, nil
 }
 
+// SortFound puts files reported by Discover into a stable order: by root
+// argument, then as a depth-first walk visiting names in lexical order
+// would list them. Parallel walkers report files in whatever order their
+// directories happen to be read; sorting keeps output repeatable.
+func SortFound(found []Found) {
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].Root != found[j].Root {
+			return found[i].Root < found[j].Root
+		}
+		return walkLess(found[i].Path, found[j].Path)
+	})
+}
+
+// walkLess compares two paths component by component, so "a/b/c" sorts
+// before "a/b.txt" just as a walk visits directory "b" before "b.txt".
+func walkLess(a, b string) bool {
+	for a != "" && b != "" {
+		var ca, cb string
+		ca, a, _ = strings.Cut(a, string(filepath.Separator))
+		cb, b, _ = strings.Cut(b, string(filepath.Separator))
+		if ca != cb {
+			return ca < cb
+		}
+	}
+	return a == "" && b != ""
+}
+
 // handlePath decides whether to include, skip, or descend into a path.
-func handlePath(path, root string, info os.FileInfo, opts DiscoveryOptions, discovered *[]string) error {
+// It reports files that qualify to emit, and returns filepath.SkipDir for
+// directories that must not be descended into. ignore holds the ignore-file
+// rules in effect for path's directory; it may be nil.
+func handlePath(path, root string, d fs.DirEntry, ignore *ignoreList, opts DiscoveryOptions, emit func(path string, info fs.FileInfo)) error {
 	// Skip the root directory itself if it's not the current directory.
-	if path == root && info.IsDir() && path != "." {
+	if path == root && d.IsDir() && path != "." {
 		return nil
 	}
 
@@ -77,7 +422,25 @@ func handlePath(path, root string, info os.FileInfo, opts DiscoveryOptions, disc
 	// We prune hidden directories early (filepath.SkipDir) to avoid
 	// unnecessary traversal of large hidden trees like .git or .node_modules.
 	if !opts.Hidden && isHidden(path, root) {
-		if info.IsDir() {
+		if d.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	}
+
+	// Patterns match the path relative to the root; a file given directly
+	// is matched by its name.
+	rel := filepath.Base(path)
+	if path != root {
+		if r, err := filepath.Rel(root, path); err == nil {
+			rel = filepath.ToSlash(r)
+		}
+	}
+
+	// 1b. Honour ignore files. An ignored directory is pruned whole, so, as
+	// with git, a negated pattern cannot re-include a file inside it.
+	if ignore != nil && path != root && ignore.ignored(rel, d.IsDir()) {
+		if d.IsDir() {
 			return filepath.SkipDir
 		}
 		return nil
@@ -85,32 +448,61 @@ func handlePath(path, root string, info os.FileInfo, opts DiscoveryOptions, disc
 
 	// 2. Handle recursion and file types.
 	// If recursion is disabled, we skip any directory that isn't the root.
-	if info.IsDir() {
+	// A directory an exclude pattern covers entirely is never opened.
+	if d.IsDir() {
 		if path != root && !opts.Recursive {
 			return filepath.SkipDir
 		}
+		if path != root {
+			for _, pattern := range opts.Exclude {
+				if globCoversDir(pattern, rel) {
+					return filepath.SkipDir
+				}
+			}
+		}
 		return nil
 	}
 
 	// 2b. Resource Hardening: Only process regular files.
 	// This prevents chexum from hanging on /dev/zero, pipes, or other
 	// "infinite" or blocking sources which could be used for DoS.
-	if !info.Mode().IsRegular() {
+	// The entry's type is known without a stat. Links are only seen here
+	// when they are not being followed.
+	if d.Type()&fs.ModeSymlink != 0 {
+		return errSymlink
+	}
+	if !d.Type().IsRegular() {
 		return nil
 	}
 
-	// 3. Apply user-defined filters (Name, Size, Date).
-	if !passesFilters(info, path, opts) {
+	// 3. Apply user-defined filters (Name, Size, Date, metadata).
+	info, err := d.Info()
+	if err != nil {
+		return err
+	}
+	if !passesFilters(info, rel, opts) {
 		return nil
 	}
 
-	*discovered = append(*discovered, path)
+	// 4. Content type, last: it is the only filter that reads the file.
+	if len(opts.Types) > 0 {
+		ok, err := matchesType(path, opts.Types)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+	}
+
+	emit(path, info)
 	return nil
 }
 
 // passesFilters applies the "AND" logic for all active filters.
-// A file must pass EVERY active filter to be returned.
-func passesFilters(info os.FileInfo, path string, opts DiscoveryOptions) bool {
+// A file must pass EVERY active filter to be returned. rel is the file's
+// slash-separated path relative to its discovery root.
+func passesFilters(info os.FileInfo, rel string, opts DiscoveryOptions) bool {
 	// Size filters: Checked first as they are extremely fast (metadata only).
 	if opts.MinSize > 0 && info.Size() < opts.MinSize {
 		return false
@@ -127,12 +519,25 @@ func passesFilters(info os.FileInfo, path string, opts DiscoveryOptions) bool {
 		return false
 	}
 
-	// Name filters: Pattern matching via globbing.
-	name := filepath.Base(path)
+	// Ownership, permission and ctime/atime filters: still metadata only.
+	if opts.Perm.Match != 0 && !opts.Perm.matches(info.Mode()) {
+		return false
+	}
+	if !passesStatAttr(info, opts) {
+		return false
+	}
+
+	// Name filters: Pattern matching via globbing (see MatchGlob) and
+	// regular expressions, which must match one of PathRegex.
+	if len(opts.PathRegex) > 0 && !slices.ContainsFunc(opts.PathRegex, func(re *regexp.Regexp) bool {
+		return re.MatchString(rel)
+	}) {
+		return false
+	}
 
 	// Exclude patterns take absolute precedence.
 	for _, pattern := range opts.Exclude {
-		if matched, _ := filepath.Match(pattern, name); matched {
+		if MatchGlob(pattern, rel) {
 			return false
 		}
 	}
@@ -140,7 +545,7 @@ func passesFilters(info os.FileInfo, path string, opts DiscoveryOptions) bool {
 	// If include patterns exist, the file MUST match at least one.
 	if len(opts.Include) > 0 {
 		for _, pattern := range opts.Include {
-			if matched, _ := filepath.Match(pattern, name); matched {
+			if MatchGlob(pattern, rel) {
 				return true
 			}
 		}
@@ -1,6 +1,9 @@
 package hash
 
 import (
+	"context"
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"testing"
@@ -13,7 +16,7 @@ func TestDiscoverFiles(t *testing.T) {
 
 	t.Run("Default", func(t *testing.T) {
 		opts := DiscoveryOptions{Recursive: false, Hidden: false, MaxSize: -1}
-		files, _ := DiscoverFiles([]string{tmpDir}, opts)
+		files, _ := DiscoverFiles(context.Background(), []string{tmpDir}, opts)
 		if len(files) != 1 || filepath.Base(files[0]) != "file1.txt" {
 			t.Errorf("got %v", files)
 		}
@@ -21,7 +24,7 @@ func TestDiscoverFiles(t *testing.T) {
 
 	t.Run("Recursive", func(t *testing.T) {
 		opts := DiscoveryOptions{Recursive: true, Hidden: false, MaxSize: -1}
-		files, _ := DiscoverFiles([]string{tmpDir}, opts)
+		files, _ := DiscoverFiles(context.Background(), []string{tmpDir}, opts)
 		if len(files) != 2 {
 			t.Errorf("got %v", files)
 		}
@@ -29,7 +32,7 @@ func TestDiscoverFiles(t *testing.T) {
 
 	t.Run("Hidden", func(t *testing.T) {
 		opts := DiscoveryOptions{Recursive: true, Hidden: true, MaxSize: -1}
-		files, _ := DiscoverFiles([]string{tmpDir}, opts)
+		files, _ := DiscoverFiles(context.Background(), []string{tmpDir}, opts)
 		if len(files) != 4 {
 			t.Errorf("got %v", files)
 		}
@@ -41,7 +44,7 @@ func TestDiscoverFiles(t *testing.T) {
 		// Named pipes are not regular files and should be skipped
 		// This check is platform-dependent for creation, but our code should skip it regardless.
 		opts := DiscoveryOptions{Recursive: true, MaxSize: -1}
-		files, _ := DiscoverFiles([]string{tmpDir}, opts)
+		files, _ := DiscoverFiles(context.Background(), []string{tmpDir}, opts)
 		for _, f := range files {
 			if filepath.Base(f) == "testpipe" {
 				t.Errorf("Security violation: non-regular file 'testpipe' was not skipped")
@@ -72,7 +75,7 @@ func testDiscoveryFilters(tmpDir string) func(*testing.T) {
 		t.Run("Size", func(t *testing.T) {
 			// file1.txt is 1 byte
 			opts := DiscoveryOptions{Recursive: true, MinSize: 2, MaxSize: -1}
-			files, _ := DiscoverFiles([]string{tmpDir}, opts)
+			files, _ := DiscoverFiles(context.Background(), []string{tmpDir}, opts)
 			for _, f := range files {
 				if filepath.Base(f) == "file1.txt" {
 					t.Errorf("file1.txt should have been filtered by min-size")
@@ -82,7 +85,7 @@ func testDiscoveryFilters(tmpDir string) func(*testing.T) {
 
 		t.Run("Include", func(t *testing.T) {
 			opts := DiscoveryOptions{Recursive: true, Include: []string{"file1.txt"}, MaxSize: -1}
-			files, _ := DiscoverFiles([]string{tmpDir}, opts)
+			files, _ := DiscoverFiles(context.Background(), []string{tmpDir}, opts)
 			if len(files) != 1 || filepath.Base(files[0]) != "file1.txt" {
 				t.Errorf("got %v, want [file1.txt]", files)
 			}
@@ -90,7 +93,7 @@ func testDiscoveryFilters(tmpDir string) func(*testing.T) {
 
 		t.Run("Exclude", func(t *testing.T) {
 			opts := DiscoveryOptions{Recursive: true, Exclude: []string{"file2.txt"}, MaxSize: -1}
-			files, _ := DiscoverFiles([]string{tmpDir}, opts)
+			files, _ := DiscoverFiles(context.Background(), []string{tmpDir}, opts)
 			for _, f := range files {
 				if filepath.Base(f) == "file2.txt" {
 					t.Errorf("file2.txt should have been filtered by exclude")
@@ -104,7 +107,7 @@ func testDiscoveryFilters(tmpDir string) func(*testing.T) {
 			os.Chtimes(filepath.Join(tmpDir, "file1.txt"), oldTime, oldTime)
 
 			opts := DiscoveryOptions{Recursive: true, ModifiedAfter: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), MaxSize: -1}
-			files, _ := DiscoverFiles([]string{tmpDir}, opts)
+			files, _ := DiscoverFiles(context.Background(), []string{tmpDir}, opts)
 			for _, f := range files {
 				if filepath.Base(f) == "file1.txt" {
 					t.Errorf("file1.txt should have been filtered by modified-after")
@@ -113,3 +116,134 @@ func testDiscoveryFilters(tmpDir string) func(*testing.T) {
 		})
 	}
 }
+
+func TestDiscoverFiles_Cancelled(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a"), []byte("a"), 0644)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if files, err := DiscoverFiles(ctx, []string{dir}, DiscoveryOptions{Recursive: true}); err != context.Canceled || files != nil {
+		t.Errorf("DiscoverFiles() = %q, %v; want nothing and context.Canceled", files, err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	var want []string
+	for _, sub := range []string{"a", "a/b", "a/b/c", "d"} {
+		os.MkdirAll(filepath.Join(dir, sub), 0755)
+		for _, name := range []string{"1.txt", "2.txt"} {
+			path := filepath.Join(dir, sub, name)
+			os.WriteFile(path, []byte(sub), 0644)
+			want = append(want, path)
+		}
+	}
+
+	d := Discover(context.Background(), []string{dir}, DiscoveryOptions{Recursive: true, MaxSize: -1, Walkers: 3})
+	var found []Found
+	var total int64
+	for f := range d.Files {
+		found = append(found, f)
+		total += f.Size
+	}
+	if errs := d.Errors(); len(errs) > 0 {
+		t.Fatalf("Errors() = %v", errs)
+	}
+	if len(found) != len(want) {
+		t.Fatalf("found %d files, want %d", len(found), len(want))
+	}
+	if total != 2*int64(len("a")+len("a/b")+len("a/b/c")+len("d")) {
+		t.Errorf("sizes add up to %d", total)
+	}
+
+	SortFound(found)
+	files, _ := DiscoverFiles(context.Background(), []string{dir}, DiscoveryOptions{Recursive: true, MaxSize: -1})
+	for i := range found {
+		if found[i].Path != files[i] {
+			t.Errorf("sorted[%d] = %s, DiscoverFiles()[%d] = %s", i, found[i].Path, i, files[i])
+		}
+	}
+}
+
+func TestDiscover_Errors(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "file"), []byte("x"), 0644)
+	os.Mkdir(filepath.Join(dir, "open"), 0755)
+	os.WriteFile(filepath.Join(dir, "open", "file"), []byte("x"), 0644)
+	want := []string{filepath.Join(dir, "file"), filepath.Join(dir, "open", "file")}
+	opts := DiscoveryOptions{Recursive: true, MaxSize: -1}
+
+	t.Run("MissingRoot", func(t *testing.T) {
+		files, err := DiscoverFiles(context.Background(), []string{filepath.Join(dir, "missing"), dir}, opts)
+		if len(files) != len(want) || files[0] != want[0] || files[1] != want[1] {
+			t.Errorf("files = %v, want %v despite the error", files, want)
+		}
+		walkErrs, ok := err.(DiscoveryErrors)
+		if !ok || len(walkErrs) != 1 || !errors.Is(walkErrs[0], fs.ErrNotExist) {
+			t.Fatalf("error = %#v, want DiscoveryErrors holding one not-exist error", err)
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Error("errors.Is should see through DiscoveryErrors")
+		}
+		var de *DiscoveryError
+		if !errors.As(err, &de) || de.Path != filepath.Join(dir, "missing") {
+			t.Errorf("DiscoveryError.Path = %v, want the missing root", de)
+		}
+	})
+
+	t.Run("UnreadableDirectory", func(t *testing.T) {
+		locked := filepath.Join(dir, "locked")
+		os.Mkdir(locked, 0755)
+		os.WriteFile(filepath.Join(locked, "secret"), []byte("x"), 0644)
+		os.Chmod(locked, 0)
+		defer os.Chmod(locked, 0755)
+		if _, err := os.ReadDir(locked); err == nil {
+			t.Skip("running with privileges that ignore directory permissions")
+		}
+
+		files, err := DiscoverFiles(context.Background(), []string{dir}, opts)
+		if len(files) != len(want) {
+			t.Errorf("files = %v, want %v despite the error", files, want)
+		}
+		if walkErrs, ok := err.(DiscoveryErrors); !ok || len(walkErrs) != 1 || !errors.Is(walkErrs[0], fs.ErrPermission) {
+			t.Errorf("error = %#v, want DiscoveryErrors holding one permission error", err)
+		}
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		d := Discover(ctx, []string{filepath.Join(dir, "open")}, opts)
+		for range d.Files {
+		}
+		if errs := d.Errors(); len(errs) > 0 {
+			t.Errorf("a cancelled walk should not report errors, got %v", errs)
+		}
+	})
+}
+
+func TestSortFound(t *testing.T) {
+	sep := string(filepath.Separator)
+	found := []Found{
+		{Path: "b.txt", Root: 1},
+		{Path: "a" + sep + "b.txt"},
+		{Path: "a" + sep + "b" + sep + "c"},
+		{Path: "a" + sep + "a"},
+		{Path: "-", Root: 2},
+		{Path: "a.txt", Root: 1},
+	}
+	want := []string{
+		"a" + sep + "a",
+		"a" + sep + "b" + sep + "c",
+		"a" + sep + "b.txt",
+		"a.txt",
+		"b.txt",
+		"-",
+	}
+	SortFound(found)
+	for i, f := range found {
+		if f.Path != want[i] {
+			t.Errorf("SortFound()[%d] = %s, want %s", i, f.Path, want[i])
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package hash
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DESIGN PRINCIPLE: Read as Little as Possible
+// --------------------------------------------
+// Two files can only be duplicates if they are the same size, and files of
+// the same size nearly always differ somewhere near their start or end.
+// FindDuplicates therefore narrows the candidates in three passes, each more
+// expensive than the last but run on fewer files:
+//
+//  1. SIZE: stat every file and bucket by size. A file whose size is unique
+//     cannot have a duplicate and is never opened.
+//  2. PARTIAL: hash the first and last PartialBytes of every file that shares
+//     its size. Files that differ there are dropped.
+//  3. FULL: hash the survivors completely and group them by digest.
+//
+// Only the full pass decides a match, so a partial collision can never be
+// reported as a duplicate.
+
+// DefaultPartialBytes is how much of each end of a file the partial pass reads.
+const DefaultPartialBytes = 4 * 1024
+
+// DuplicateOptions tunes FindDuplicates.
+type DuplicateOptions struct {
+	Workers      int   // Parallel readers; <= 0 means runtime.NumCPU()
+	PartialBytes int64 // Bytes read from each end in the partial pass; <= 0 means DefaultPartialBytes
+
+	// Progress, if set, is called with the number of files just settled:
+	// ruled out as unique, or fully hashed. It is called from the calling
+	// goroutine only, and the counts sum to the number of files.
+	Progress func(files int)
+}
+
+// DuplicateStats records how many files each pass had to look at.
+type DuplicateStats struct {
+	Files          int   // Files stat'ed successfully
+	SizeMatched    int   // Files that share their size with another file
+	PartialMatched int   // Files that also share their partial digest
+	BytesRead      int64 // Bytes read across the partial and full passes
+}
+
+// FindDuplicates groups files with identical content, reading as little of
+// each file as it can (see above).
+//
+// The result fills the same structures as a full hashing run: every group of
+// two or more identical files becomes a MatchGroup with Size and Wasted set,
+// and Entries holds the grouped files followed by any that could not be read.
+// Files without a duplicate are counted in FilesProcessed but otherwise left
+// out, since they were never fully hashed.
+func (c *Computer) FindDuplicates(ctx context.Context, files []string, opts DuplicateOptions) (*Result, DuplicateStats) {
+	start := time.Now()
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+	if opts.PartialBytes <= 0 {
+		opts.PartialBytes = DefaultPartialBytes
+	}
+	progress := opts.Progress
+	if progress == nil {
+		progress = func(int) {}
+	}
+
+	result := &Result{}
+	var stats DuplicateStats
+	fail := func(path string, err error) {
+		result.Entries = append(result.Entries, Entry{Original: path, Error: err})
+		result.Errors = append(result.Errors, err)
+		progress(1)
+	}
+
+	// Pass 1: bucket by size.
+	bySize := make(map[int64][]string)
+	var sizes []int64
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err != nil {
+			fail(path, err)
+			continue
+		}
+		if !info.Mode().IsRegular() {
+			fail(path, fmt.Errorf("%s is not a regular file", path))
+			continue
+		}
+		stats.Files++
+		if _, ok := bySize[info.Size()]; !ok {
+			sizes = append(sizes, info.Size())
+		}
+		bySize[info.Size()] = append(bySize[info.Size()], path)
+	}
+	result.FilesProcessed = stats.Files
+
+	var candidates []string
+	for _, size := range sizes {
+		if paths := bySize[size]; len(paths) > 1 {
+			candidates = append(candidates, paths...)
+		} else {
+			progress(1)
+		}
+	}
+	stats.SizeMatched = len(candidates)
+
+	// Pass 2: partial digests. A file no larger than both ends together is
+	// read in full here, so its digest is final and pass 3 skips it.
+	type probe struct {
+		path  string
+		size  int64
+		key   string // Partial digest, or the full digest when final is set
+		final *Entry
+		err   error
+	}
+	probes := parallel(ctx, candidates, opts.Workers, func(path string) probe {
+		p := probe{path: path}
+		info, err := os.Stat(path)
+		if err != nil {
+			p.err = err
+			return p
+		}
+		p.size = info.Size()
+		if p.size <= 2*opts.PartialBytes {
+			p.final, p.err = c.ComputeFile(ctx, path)
+			if p.final != nil {
+				p.key = p.final.Hash
+			}
+			return p
+		}
+		p.key, p.err = c.partialDigest(ctx, path, p.size, opts.PartialBytes)
+		return p
+	})
+
+	type bucket struct {
+		size int64
+		key  string
+	}
+	byPartial := make(map[bucket][]probe)
+	var partialOrder []bucket
+	for _, p := range probes {
+		if p.err != nil {
+			if !isCancellation(ctx, p.err) {
+				fail(p.path, p.err)
+			}
+			continue
+		}
+		if p.final != nil {
+			stats.BytesRead += p.size
+		} else {
+			stats.BytesRead += 2 * opts.PartialBytes
+		}
+		b := bucket{p.size, p.key}
+		if _, ok := byPartial[b]; !ok {
+			partialOrder = append(partialOrder, b)
+		}
+		byPartial[b] = append(byPartial[b], p)
+	}
+
+	// Pass 3: fully hash whatever still collides.
+	byDigest := make(map[bucket][]Entry)
+	var digestOrder []bucket
+	add := func(e Entry) {
+		b := bucket{e.Size, e.Hash}
+		if _, ok := byDigest[b]; !ok {
+			digestOrder = append(digestOrder, b)
+		}
+		byDigest[b] = append(byDigest[b], e)
+	}
+	var full []string
+	for _, b := range partialOrder {
+		group := byPartial[b]
+		if len(group) < 2 {
+			progress(1)
+			continue
+		}
+		stats.PartialMatched += len(group)
+		for _, p := range group {
+			if p.final != nil {
+				add(*p.final)
+				progress(1)
+			} else {
+				full = append(full, p.path)
+			}
+		}
+	}
+	for entry := range c.ComputeBatch(ctx, full, opts.Workers) {
+		if entry.Error != nil {
+			fail(entry.Original, entry.Error)
+			continue
+		}
+		progress(1)
+		stats.BytesRead += entry.Size
+		add(entry)
+	}
+
+	for _, b := range digestOrder {
+		entries := byDigest[b]
+		if len(entries) < 2 {
+			continue
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Original < entries[j].Original })
+		result.Matches = append(result.Matches, MatchGroup{
+			Hash:    b.key,
+			Entries: entries,
+			Count:   len(entries),
+			Size:    b.size,
+			Wasted:  b.size * int64(len(entries)-1),
+		})
+	}
+	// Biggest savings first; ties in a stable, content-derived order.
+	sort.SliceStable(result.Matches, func(i, j int) bool {
+		mi, mj := result.Matches[i], result.Matches[j]
+		if mi.Wasted != mj.Wasted {
+			return mi.Wasted > mj.Wasted
+		}
+		return mi.Hash < mj.Hash
+	})
+	var grouped []Entry
+	for _, m := range result.Matches {
+		grouped = append(grouped, m.Entries...)
+	}
+
+	result.Entries = append(grouped, result.Entries...)
+	result.BytesProcessed = stats.BytesRead
+	result.Duration = time.Since(start)
+	result.Incomplete = ctx.Err() != nil
+	return result, stats
+}
+
+// partialDigest hashes the first and last n bytes of a file of the given
+// size with the primary algorithm. The reads are cancelled, rate limited and
+// counted like those of ComputeFile.
+func (c *Computer) partialDigest(ctx context.Context, path string, size, n int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := c.newHasher()
+	for _, off := range []int64{0, size - n} {
+		if _, err := c.copyBuffered(h, c.counted(c.diskReader(ctx, io.NewSectionReader(f, off, n)))); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// parallel applies fn to every item using the given number of workers and
+// returns the results in input order. Items not yet started when ctx is
+// cancelled are left out of the result.
+func parallel[T any](ctx context.Context, items []string, workers int, fn func(string) T) []T {
+	out := make([]T, len(items))
+	done := make([]bool, len(items))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				out[i] = fn(items[i])
+				done[i] = true
+			}
+		}()
+	}
+feed:
+	for i := range items {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	results := make([]T, 0, len(items))
+	for i, ok := range done {
+		if ok {
+			results = append(results, out[i])
+		}
+	}
+	return results
+}
@@ -0,0 +1,121 @@
+package hash
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	big := bytes.Repeat([]byte("0123456789abcdef"), 1024) // 16 KiB
+	sameEnds := append([]byte(nil), big...)
+	sameEnds[len(big)/2] = 'X' // Differs only in the middle
+
+	files := map[string][]byte{
+		"a.bin":  big,
+		"b.bin":  big,
+		"c.bin":  big,
+		"middle": sameEnds,
+		"small1": []byte("hello"),
+		"small2": []byte("hello"),
+		"other":  []byte("world"),
+		"unique": []byte("a size of its own"),
+		"empty1": nil,
+		"empty2": nil,
+	}
+	var paths []string
+	for name, data := range files {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, p)
+	}
+	paths = append(paths, filepath.Join(dir, "missing"))
+
+	c, _ := NewComputer(AlgorithmSHA256)
+	settled := 0
+	result, stats := c.FindDuplicates(context.Background(), paths, DuplicateOptions{
+		Workers:      2,
+		PartialBytes: 1024,
+		Progress:     func(n int) { settled += n },
+	})
+
+	names := func(g MatchGroup) string {
+		var out []string
+		for _, e := range g.Entries {
+			out = append(out, filepath.Base(e.Original))
+		}
+		return strings.Join(out, ",")
+	}
+	var got []string
+	for _, g := range result.Matches {
+		got = append(got, names(g))
+	}
+	// Largest waste first; "middle" shares size and ends with a.bin but not content.
+	want := []string{"a.bin,b.bin,c.bin", "small1,small2", "empty1,empty2"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("groups = %v, want %v", got, want)
+	}
+
+	if g := result.Matches[0]; g.Size != int64(len(big)) || g.Wasted != 2*int64(len(big)) || g.Count != 3 {
+		t.Errorf("group 0: Size %d Wasted %d Count %d", g.Size, g.Wasted, g.Count)
+	}
+	if stats.Files != 10 || stats.SizeMatched != 9 || stats.PartialMatched != 8 {
+		t.Errorf("stats = %+v", stats)
+	}
+	// unique was never opened; the four big files were read in full only
+	// when their ends matched.
+	if max := int64(4*1024*2 + 4*len(big) + 15); stats.BytesRead > max {
+		t.Errorf("BytesRead = %d, want at most %d", stats.BytesRead, max)
+	}
+	if len(result.Errors) != 1 || result.FilesProcessed != 10 {
+		t.Errorf("Errors = %v, FilesProcessed = %d", result.Errors, result.FilesProcessed)
+	}
+	if settled != len(paths) {
+		t.Errorf("progress settled %d files, want %d", settled, len(paths))
+	}
+}
+
+func TestFindDuplicates_Cancelled(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for _, name := range []string{"a", "b"} {
+		p := filepath.Join(dir, name)
+		os.WriteFile(p, []byte("same"), 0644)
+		paths = append(paths, p)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c, _ := NewComputer(AlgorithmSHA256)
+	result, _ := c.FindDuplicates(ctx, paths, DuplicateOptions{})
+	if !result.Incomplete || len(result.Matches) != 0 || len(result.Errors) != 0 {
+		t.Errorf("cancelled run: Incomplete %v, %d groups, errors %v", result.Incomplete, len(result.Matches), result.Errors)
+	}
+}
+
+func TestPartialDigest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "f")
+	data := append(bytes.Repeat([]byte("a"), 10), bytes.Repeat([]byte("b"), 10)...)
+	os.WriteFile(path, data, 0644)
+	c, _ := NewComputer(AlgorithmSHA256)
+
+	got, err := c.partialDigest(context.Background(), path, int64(len(data)), 4)
+	if err != nil {
+		t.Fatalf("partialDigest() error = %v", err)
+	}
+	if want, _ := c.ComputeReader(strings.NewReader("aaaabbbb")); got != want {
+		t.Errorf("partialDigest() = %s, want the digest of both ends %s", got, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.partialDigest(ctx, path, int64(len(data)), 4); err != context.Canceled {
+		t.Errorf("partialDigest() after cancel: error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,343 @@
+package hash
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Digest encodings accepted by --encoding.
+//
+// DESIGN PRINCIPLE: Hex Inside, Any Encoding at the Edges
+// -------------------------------------------------------
+// Every digest is computed, grouped and compared as lowercase hex. Other
+// encodings exist only at the boundaries: EncodeDigest renders a digest for
+// output and manifests, and DecodeDigest turns a reference hash typed in any
+// supported encoding back into hex before it is compared with anything.
+// Matching therefore never depends on how a digest was written down.
+const (
+	EncodingHex       = "hex"       // Lowercase hexadecimal (default)
+	EncodingBase64    = "base64"    // Standard base64 with padding
+	EncodingBase32    = "base32"    // RFC 4648 base32, lowercase, unpadded
+	EncodingNix32     = "nix32"     // Nix store base32 alphabet and byte order
+	EncodingSRI       = "sri"       // Subresource Integrity, e.g. "sha256-<base64>"
+	EncodingMultihash = "multihash" // Multihash (code, length, digest) in base58btc
+)
+
+// Encodings lists every supported digest encoding, default first.
+var Encodings = []string{EncodingHex, EncodingBase64, EncodingBase32, EncodingNix32, EncodingSRI, EncodingMultihash}
+
+var (
+	base32Lower = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+	nix32Chars  = "0123456789abcdfghijklmnpqrsvwxyz"
+	base58Chars = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+)
+
+// ValidEncoding reports whether name is a supported encoding.
+func ValidEncoding(name string) bool {
+	for _, e := range Encodings {
+		if e == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckEncoding reports whether digests of algorithm can be written in
+// encoding. SRI and multihash name the algorithm inside the digest, so they
+// only cover algorithms those standards define.
+func CheckEncoding(algorithm, encoding string) error {
+	if !ValidEncoding(encoding) {
+		return fmt.Errorf("unknown encoding %q: must be one of %s", encoding, strings.Join(Encodings, ", "))
+	}
+	spec, ok := LookupAlgorithm(algorithm)
+	if !ok {
+		return fmt.Errorf("unsupported algorithm: %s", algorithm)
+	}
+	switch {
+	case encoding == EncodingSRI && spec.SRIName == "":
+		return fmt.Errorf("%s cannot be written as SRI, which only defines sha256, sha384 and sha512", spec.Name)
+	case encoding == EncodingMultihash && spec.MultihashCode == 0:
+		return fmt.Errorf("%s has no multihash code", spec.Name)
+	}
+	return nil
+}
+
+// EncodeDigest renders a hex digest of algorithm in the given encoding.
+func EncodeDigest(hexDigest, algorithm, encoding string) (string, error) {
+	if encoding == "" || encoding == EncodingHex {
+		return hexDigest, nil
+	}
+	raw, err := hex.DecodeString(hexDigest)
+	if err != nil {
+		return "", fmt.Errorf("invalid hex digest: %w", err)
+	}
+	// Only the self-describing encodings need to know the algorithm.
+	var spec AlgorithmSpec
+	if encoding == EncodingSRI || encoding == EncodingMultihash {
+		if err := CheckEncoding(algorithm, encoding); err != nil {
+			return "", err
+		}
+		spec, _ = LookupAlgorithm(algorithm)
+	}
+
+	switch encoding {
+	case EncodingBase64:
+		return base64.StdEncoding.EncodeToString(raw), nil
+	case EncodingBase32:
+		return base32Lower.EncodeToString(raw), nil
+	case EncodingNix32:
+		return nix32Encode(raw), nil
+	case EncodingSRI:
+		return spec.SRIName + "-" + base64.StdEncoding.EncodeToString(raw), nil
+	case EncodingMultihash:
+		buf := binary.AppendUvarint(nil, spec.MultihashCode)
+		buf = binary.AppendUvarint(buf, uint64(len(raw)))
+		return base58Encode(append(buf, raw...)), nil
+	}
+	return "", fmt.Errorf("unknown encoding %q: must be one of %s", encoding, strings.Join(Encodings, ", "))
+}
+
+// EncodeEntry returns a copy of e with Hash and every digest in Hashes
+// rendered in encoding. Digests that cannot be encoded are left as hex.
+func EncodeEntry(e Entry, encoding string) Entry {
+	if encoding == "" || encoding == EncodingHex || e.Hash == "" {
+		return e
+	}
+	if s, err := EncodeDigest(e.Hash, e.Algorithm, encoding); err == nil {
+		e.Hash = s
+	}
+	if len(e.Hashes) > 0 {
+		encoded := make(map[string]string, len(e.Hashes))
+		for alg, h := range e.Hashes {
+			if s, err := EncodeDigest(h, alg, encoding); err == nil {
+				h = s
+			}
+			encoded[alg] = h
+		}
+		e.Hashes = encoded
+	}
+	return e
+}
+
+// EncodeEntries applies EncodeEntry to every entry.
+func EncodeEntries(entries []Entry, encoding string) []Entry {
+	if encoding == "" || encoding == EncodingHex {
+		return entries
+	}
+	out := make([]Entry, len(entries))
+	for i, e := range entries {
+		out[i] = EncodeEntry(e, encoding)
+	}
+	return out
+}
+
+// DecodedDigest is a reference digest recognised by DecodeDigest.
+type DecodedDigest struct {
+	Hex       string // Lowercase hex form, as used for all comparisons
+	Encoding  string // Encoding the digest was written in
+	Algorithm string // Set when the encoding names the algorithm (SRI, multihash)
+}
+
+// Size returns the digest length in bytes.
+func (d DecodedDigest) Size() int {
+	return len(d.Hex) / 2
+}
+
+// Candidates returns the algorithms that could have produced the digest:
+// the named one for self-describing encodings, otherwise every unkeyed
+// algorithm of the right size.
+func (d DecodedDigest) Candidates() []string {
+	if d.Algorithm != "" {
+		return []string{d.Algorithm}
+	}
+	return algorithmsForHexLength(len(d.Hex))
+}
+
+// Fits reports whether the digest could belong to algorithm. Unlike
+// Candidates it also accepts keyed algorithms, which are never guessed but
+// may be requested explicitly.
+func (d DecodedDigest) Fits(algorithm string) bool {
+	spec, ok := LookupAlgorithm(algorithm)
+	if !ok {
+		return false
+	}
+	if d.Algorithm != "" {
+		return d.Algorithm == spec.Name
+	}
+	return spec.Size == d.Size()
+}
+
+// minEncodedDigestSize is the smallest digest recognised in base64, base32
+// or nix32. Shorter checksums (crc32c, xxh64) encode to a handful of
+// characters that ordinary words would match, so they are read as hex only.
+const minEncodedDigestSize = 16
+
+// DecodeDigest recognises a digest written in any supported encoding.
+// ok is false when s is not a digest of any registered algorithm's size.
+//
+// Encodings are tried from most to least distinctive: hex, SRI, multihash,
+// base64, base32, nix32. A string valid in several encodings (rare outside
+// contrived inputs) takes the first.
+func DecodeDigest(s string) (DecodedDigest, bool) {
+	if len(DetectHashAlgorithm(s)) > 0 {
+		return DecodedDigest{Hex: strings.ToLower(s), Encoding: EncodingHex}, true
+	}
+	if d, ok := decodeSRI(s); ok {
+		return d, true
+	}
+	if d, ok := decodeMultihash(s); ok {
+		return d, true
+	}
+
+	decoders := []struct {
+		encoding string
+		decode   func(string) ([]byte, error)
+	}{
+		{EncodingBase64, base64.StdEncoding.DecodeString},
+		{EncodingBase64, base64.RawStdEncoding.DecodeString},
+		{EncodingBase32, func(s string) ([]byte, error) {
+			return base32Lower.DecodeString(strings.TrimRight(strings.ToLower(s), "="))
+		}},
+		{EncodingNix32, nix32Decode},
+	}
+	for _, dec := range decoders {
+		raw, err := dec.decode(s)
+		if err != nil || len(raw) < minEncodedDigestSize || len(algorithmsForHexLength(len(raw)*2)) == 0 {
+			continue
+		}
+		return DecodedDigest{Hex: hex.EncodeToString(raw), Encoding: dec.encoding}, true
+	}
+	return DecodedDigest{}, false
+}
+
+func decodeSRI(s string) (DecodedDigest, bool) {
+	name, b64, found := strings.Cut(s, "-")
+	if !found {
+		return DecodedDigest{}, false
+	}
+	for _, spec := range builtinAlgorithms {
+		if spec.SRIName == "" || spec.SRIName != name {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(b64)
+		if err != nil || len(raw) != spec.Size {
+			return DecodedDigest{}, false
+		}
+		return DecodedDigest{Hex: hex.EncodeToString(raw), Encoding: EncodingSRI, Algorithm: spec.Name}, true
+	}
+	return DecodedDigest{}, false
+}
+
+func decodeMultihash(s string) (DecodedDigest, bool) {
+	buf, ok := base58Decode(s)
+	if !ok {
+		return DecodedDigest{}, false
+	}
+	code, n := binary.Uvarint(buf)
+	if n <= 0 {
+		return DecodedDigest{}, false
+	}
+	size, m := binary.Uvarint(buf[n:])
+	if m <= 0 {
+		return DecodedDigest{}, false
+	}
+	raw := buf[n+m:]
+	for _, spec := range builtinAlgorithms {
+		if spec.MultihashCode != 0 && spec.MultihashCode == code && uint64(spec.Size) == size && len(raw) == spec.Size {
+			return DecodedDigest{Hex: hex.EncodeToString(raw), Encoding: EncodingMultihash, Algorithm: spec.Name}, true
+		}
+	}
+	return DecodedDigest{}, false
+}
+
+// nix32Encode renders b the way Nix prints store hashes: a 32-character
+// alphabet without e, o, u and t, reading the bytes from the end.
+func nix32Encode(b []byte) string {
+	length := (len(b)*8-1)/5 + 1
+	out := make([]byte, 0, length)
+	for n := length - 1; n >= 0; n-- {
+		bit := n * 5
+		i, j := bit/8, uint(bit%8)
+		c := b[i] >> j
+		if i+1 < len(b) {
+			c |= b[i+1] << (8 - j)
+		}
+		out = append(out, nix32Chars[c&0x1f])
+	}
+	return string(out)
+}
+
+// nix32Decode reverses nix32Encode for any registered digest size.
+func nix32Decode(s string) ([]byte, error) {
+	size := 0
+	for _, spec := range builtinAlgorithms {
+		if (spec.Size*8-1)/5+1 == len(s) {
+			size = spec.Size
+			break
+		}
+	}
+	if size == 0 {
+		return nil, fmt.Errorf("no digest size encodes to %d nix32 characters", len(s))
+	}
+	out := make([]byte, size)
+	for n := 0; n < len(s); n++ {
+		digit := strings.IndexByte(nix32Chars, s[len(s)-n-1])
+		if digit < 0 {
+			return nil, fmt.Errorf("invalid nix32 character %q", s[len(s)-n-1])
+		}
+		bit := n * 5
+		i, j := bit/8, uint(bit%8)
+		out[i] |= byte(digit << j)
+		carry := byte(digit >> (8 - j))
+		if i+1 < size {
+			out[i+1] |= carry
+		} else if carry != 0 {
+			return nil, fmt.Errorf("nix32 value overflows %d bytes", size)
+		}
+	}
+	return out, nil
+}
+
+func base58Encode(b []byte) string {
+	n := new(big.Int).SetBytes(b)
+	radix, mod := big.NewInt(58), new(big.Int)
+	var out []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		out = append(out, base58Chars[mod.Int64()])
+	}
+	for _, c := range b {
+		if c != 0 {
+			break
+		}
+		out = append(out, base58Chars[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+func base58Decode(s string) ([]byte, bool) {
+	if s == "" {
+		return nil, false
+	}
+	n, radix := new(big.Int), big.NewInt(58)
+	zeros := 0
+	for i := 0; i < len(s); i++ {
+		digit := strings.IndexByte(base58Chars, s[i])
+		if digit < 0 {
+			return nil, false
+		}
+		if digit == 0 && i == zeros {
+			zeros++
+		}
+		n.Mul(n, radix).Add(n, big.NewInt(int64(digit)))
+	}
+	return append(make([]byte, zeros), n.Bytes()...), true
+}
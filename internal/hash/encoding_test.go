@@ -0,0 +1,126 @@
+package hash
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// sha256("hello")
+const helloSHA256 = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+// TestEncodeDigest checks each encoding against known vectors.
+func TestEncodeDigest(t *testing.T) {
+	tests := []struct {
+		encoding string
+		want     string
+	}{
+		{EncodingHex, helloSHA256},
+		{EncodingBase64, "LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ="},
+		{EncodingBase32, "ftze3os7wcrq4jxihmvmlopctynrmhs4d6tuexttaqzwfe4ltasa"},
+		{EncodingNix32, "094qif9n4cq4fdg459qzbhg1c6wywawwaaivx0k0x8xhbyx4vwic"},
+		{EncodingSRI, "sha256-LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ="},
+		{EncodingMultihash, "QmRN6wdp1S2A5EtjW9A3M1vKSBuQQGcgvuhoMUoEz4iiT5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.encoding, func(t *testing.T) {
+			got, err := EncodeDigest(helloSHA256, AlgorithmSHA256, tt.encoding)
+			if err != nil {
+				t.Fatalf("EncodeDigest() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("EncodeDigest() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDecodeDigest checks that every encoding of every algorithm decodes
+// back to the original hex digest.
+func TestDecodeDigest(t *testing.T) {
+	for _, name := range []string{AlgorithmMD5, AlgorithmSHA1, AlgorithmSHA256, AlgorithmSHA512, AlgorithmBLAKE2b} {
+		spec, _ := LookupAlgorithm(name)
+		h := spec.New()
+		h.Write([]byte("hello"))
+		hexDigest := hex.EncodeToString(h.Sum(nil))
+
+		for _, encoding := range Encodings {
+			if CheckEncoding(name, encoding) != nil {
+				continue
+			}
+			t.Run(name+"/"+encoding, func(t *testing.T) {
+				encoded, err := EncodeDigest(hexDigest, name, encoding)
+				if err != nil {
+					t.Fatalf("EncodeDigest() error = %v", err)
+				}
+				got, ok := DecodeDigest(encoded)
+				if !ok {
+					t.Fatalf("DecodeDigest(%q) not recognised", encoded)
+				}
+				if got.Hex != hexDigest {
+					t.Errorf("DecodeDigest(%q).Hex = %s, want %s", encoded, got.Hex, hexDigest)
+				}
+				if got.Encoding != encoding {
+					t.Errorf("DecodeDigest(%q).Encoding = %s, want %s", encoded, got.Encoding, encoding)
+				}
+				if !got.Fits(name) {
+					t.Errorf("DecodeDigest(%q) does not fit %s", encoded, name)
+				}
+			})
+		}
+	}
+}
+
+// TestDecodeDigest_Rejects checks that ordinary words and short strings are
+// not mistaken for encoded digests.
+func TestDecodeDigest_Rejects(t *testing.T) {
+	for _, s := range []string{"", "hello", "README.md", "sha256-notbase64", "md5-" + strings.Repeat("A", 22) + "==", strings.Repeat("z", 10)} {
+		if d, ok := DecodeDigest(s); ok {
+			t.Errorf("DecodeDigest(%q) = %+v, want not recognised", s, d)
+		}
+	}
+}
+
+// TestCheckEncoding tests which algorithm/encoding pairs are allowed.
+func TestCheckEncoding(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		encoding  string
+		wantErr   bool
+	}{
+		{AlgorithmSHA256, EncodingSRI, false},
+		{AlgorithmMD5, EncodingSRI, true},
+		{AlgorithmMD5, EncodingBase64, false},
+		{AlgorithmBLAKE3, EncodingMultihash, false},
+		{AlgorithmCRC32C, EncodingMultihash, true},
+		{AlgorithmSHA256, "base85", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.algorithm+"/"+tt.encoding, func(t *testing.T) {
+			err := CheckEncoding(tt.algorithm, tt.encoding)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckEncoding() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestEncodeEntry checks that both the primary digest and secondary digests
+// are encoded, and that the original entry is left untouched.
+func TestEncodeEntry(t *testing.T) {
+	e := Entry{Original: "a.txt", Hash: helloSHA256, Algorithm: AlgorithmSHA256,
+		Hashes: map[string]string{AlgorithmSHA256: helloSHA256, AlgorithmMD5: "5d41402abc4b2a76b9719d911017c592"}}
+
+	got := EncodeEntry(e, EncodingSRI)
+	if got.Hash != "sha256-LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=" {
+		t.Errorf("Hash = %s", got.Hash)
+	}
+	if got.Hashes[AlgorithmMD5] != "5d41402abc4b2a76b9719d911017c592" {
+		t.Errorf("md5 has no SRI form and should stay hex, got %s", got.Hashes[AlgorithmMD5])
+	}
+	if e.Hashes[AlgorithmSHA256] != helloSHA256 {
+		t.Error("EncodeEntry modified the original entry")
+	}
+}
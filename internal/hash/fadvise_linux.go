@@ -0,0 +1,59 @@
+//go:build linux
+
+package hash
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// residentWindow is how much of the start of a file alreadyCached examines.
+const residentWindow = 16 * 1024 * 1024
+
+// adviseSequential tells the kernel f is about to be read from start to end,
+// so it reads ahead more aggressively. Hints are best effort; errors are
+// ignored.
+func adviseSequential(f *os.File) {
+	_ = unix.Fadvise(int(f.Fd()), 0, 0, unix.FADV_SEQUENTIAL)
+}
+
+// adviseDone tells the kernel chexum will not read f again, so its pages can
+// be dropped from the page cache before anything another program still
+// needs.
+func adviseDone(f *os.File) {
+	_ = unix.Fadvise(int(f.Fd()), 0, 0, unix.FADV_DONTNEED)
+}
+
+// alreadyCached reports whether any of the first pages of f, which is size
+// bytes long, are in the page cache before chexum reads them: someone else
+// uses the file, so its pages are not chexum's to drop. Anything that
+// cannot be checked counts as cached.
+func alreadyCached(f *os.File, size int64) bool {
+	n := size
+	if n > residentWindow {
+		n = residentWindow
+	}
+	if n <= 0 {
+		return false
+	}
+	data, err := unix.Mmap(int(f.Fd()), 0, int(n), unix.PROT_READ, unix.MAP_SHARED)
+	if err != nil {
+		return true
+	}
+	defer unix.Munmap(data)
+
+	page := os.Getpagesize()
+	vec := make([]byte, (len(data)+page-1)/page)
+	_, _, errno := unix.Syscall(unix.SYS_MINCORE, uintptr(unsafe.Pointer(&data[0])), uintptr(len(data)), uintptr(unsafe.Pointer(&vec[0])))
+	if errno != 0 {
+		return true
+	}
+	for _, v := range vec {
+		if v&1 != 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+//go:build linux
+
+package hash
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAlreadyCached(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data")
+	// Just written, so its pages are in the page cache.
+	if err := os.WriteFile(path, make([]byte, 64*1024), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if !alreadyCached(f, 64*1024) {
+		t.Error("a file just written should be reported as cached")
+	}
+	if alreadyCached(f, 0) {
+		t.Error("an empty file has no pages to be cached")
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package hash
+
+import "os"
+
+// adviseSequential does nothing: posix_fadvise is only used on Linux.
+func adviseSequential(f *os.File) {}
+
+// adviseDone does nothing: posix_fadvise is only used on Linux.
+func adviseDone(f *os.File) {}
+
+// alreadyCached reports true, so nothing is ever dropped: the page cache is
+// only examined on Linux.
+func alreadyCached(f *os.File, size int64) bool { return true }
@@ -0,0 +1,39 @@
+//go:build darwin
+
+package hash
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// fileID reads the device, inode and timestamps from a Darwin stat result.
+func fileID(info os.FileInfo) (FileID, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return FileID{}, false
+	}
+	return FileID{
+		Device:     uint64(st.Dev),
+		Inode:      uint64(st.Ino),
+		Size:       info.Size(),
+		ModTime:    unixNano(int64(st.Mtimespec.Sec), int64(st.Mtimespec.Nsec)),
+		ChangeTime: unixNano(int64(st.Ctimespec.Sec), int64(st.Ctimespec.Nsec)),
+	}, true
+}
+
+// statAttrs reads the owner, group, access and change times from a
+// Darwin stat result.
+func statAttrs(info os.FileInfo) (statAttr, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return statAttr{}, false
+	}
+	return statAttr{
+		UID:        st.Uid,
+		GID:        st.Gid,
+		AccessTime: time.Unix(int64(st.Atimespec.Sec), int64(st.Atimespec.Nsec)),
+		ChangeTime: time.Unix(int64(st.Ctimespec.Sec), int64(st.Ctimespec.Nsec)),
+	}, true
+}
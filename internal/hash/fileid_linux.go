@@ -0,0 +1,39 @@
+//go:build linux
+
+package hash
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// fileID reads the device, inode and timestamps from a Linux stat result.
+func fileID(info os.FileInfo) (FileID, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return FileID{}, false
+	}
+	return FileID{
+		Device:     uint64(st.Dev),
+		Inode:      uint64(st.Ino),
+		Size:       info.Size(),
+		ModTime:    unixNano(int64(st.Mtim.Sec), int64(st.Mtim.Nsec)),
+		ChangeTime: unixNano(int64(st.Ctim.Sec), int64(st.Ctim.Nsec)),
+	}, true
+}
+
+// statAttrs reads the owner, group, access and change times from a
+// Linux stat result.
+func statAttrs(info os.FileInfo) (statAttr, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return statAttr{}, false
+	}
+	return statAttr{
+		UID:        st.Uid,
+		GID:        st.Gid,
+		AccessTime: time.Unix(int64(st.Atim.Sec), int64(st.Atim.Nsec)),
+		ChangeTime: time.Unix(int64(st.Ctim.Sec), int64(st.Ctim.Nsec)),
+	}, true
+}
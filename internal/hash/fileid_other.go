@@ -0,0 +1,16 @@
+//go:build !linux && !darwin
+
+package hash
+
+import "os"
+
+// fileID reports false: without a change time the cache could not tell a
+// rewritten file from an untouched one, so it is not used on this platform.
+func fileID(info os.FileInfo) (FileID, bool) {
+	return FileID{}, false
+}
+
+// statAttrs reports false: owners and change times are not portable.
+func statAttrs(info os.FileInfo) (statAttr, bool) {
+	return statAttr{}, false
+}
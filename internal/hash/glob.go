@@ -0,0 +1,150 @@
+package hash
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Glob patterns used by --include, --exclude and wildcard arguments are
+// matched a path segment at a time, in the style of gitignore and
+// doublestar: "*", "?" and "[...]" never cross a "/", while a "**" segment
+// spans any number of directories. A pattern without a "/" is matched
+// against the file name at any depth, so "*.go" keeps meaning what it
+// always has.
+
+// HasGlobMeta reports whether s contains a wildcard.
+func HasGlobMeta(s string) bool {
+	return strings.ContainsAny(s, "*?[")
+}
+
+// globSegments splits a pattern into the segments matchSegments expects.
+func globSegments(pattern string) []string {
+	pattern = strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(pattern), "./"), "/")
+	if !strings.Contains(pattern, "/") {
+		return []string{"**", pattern}
+	}
+	return strings.Split(strings.TrimPrefix(pattern, "/"), "/")
+}
+
+// MatchGlob reports whether rel, a slash-separated path relative to a
+// discovery root, matches pattern.
+func MatchGlob(pattern, rel string) bool {
+	return matchSegments(globSegments(pattern), strings.Split(rel, "/"))
+}
+
+// globCoversDir reports whether pattern matches everything inside the
+// directory rel, so a walk need not descend into it: either the pattern
+// matches the directory itself, or it is "<dir>/**".
+func globCoversDir(pattern, rel string) bool {
+	segments := globSegments(pattern)
+	name := strings.Split(rel, "/")
+	if matchSegments(segments, name) {
+		return true
+	}
+	n := len(segments)
+	return n > 1 && segments[n-1] == "**" && matchSegments(segments[:n-1], name)
+}
+
+// matchSegments matches path segments against pattern segments. "**"
+// matches zero or more whole segments, except at the end of a pattern,
+// where it matches everything inside a directory but not the directory.
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			if len(rest) == 0 {
+				return len(name) > 0
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(rest, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// matchPrefix reports whether some path beginning with the segments of name
+// could match pattern, that is, whether a walk should descend into name.
+func matchPrefix(pattern, name []string) bool {
+	for len(name) > 0 {
+		if len(pattern) == 0 {
+			return false
+		}
+		if pattern[0] == "**" {
+			return true
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(pattern) > 0
+}
+
+// ExpandGlob returns the files and directories matching pattern, in the
+// order a lexical walk visits them, or nil if nothing matches. It is the
+// shell's globbing with "**" added, for patterns that reach chexum quoted
+// or from a shell that does not expand them. As in the shell, wildcards do
+// not match names starting with "." unless the pattern spells out the dot,
+// and unreadable directories are passed over. A trailing "**" lists the
+// files inside directories but not the directories, so nothing is listed
+// twice.
+func ExpandGlob(pattern string) []string {
+	segments := strings.Split(filepath.ToSlash(pattern), "/")
+
+	// Walk from the longest leading run of segments without wildcards.
+	fixed := 0
+	for fixed < len(segments)-1 && !HasGlobMeta(segments[fixed]) {
+		fixed++
+	}
+	root := filepath.FromSlash(strings.Join(segments[:fixed], "/"))
+	switch {
+	case root == "" && strings.HasPrefix(pattern, "/"):
+		root = string(filepath.Separator)
+	case root == "":
+		root = "."
+	}
+	rest := segments[fixed:]
+	dots := false
+	for _, s := range rest {
+		dots = dots || strings.HasPrefix(s, ".")
+	}
+
+	var matches []string
+	filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == root {
+			return nil
+		}
+		if !dots && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return nil
+		}
+		name := strings.Split(filepath.ToSlash(rel), "/")
+		if matchSegments(rest, name) && !(d.IsDir() && rest[len(rest)-1] == "**") {
+			matches = append(matches, p)
+		}
+		if d.IsDir() && !matchPrefix(rest, name) {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return matches
+}
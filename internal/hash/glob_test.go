@@ -0,0 +1,145 @@
+package hash
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		rel     string
+		want    bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/chexum/main.go", true},
+		{"*.go", "main.go.orig", false},
+		{"src/**/*.go", "src/main.go", true},
+		{"src/**/*.go", "src/a/b/main.go", true},
+		{"src/**/*.go", "lib/src/main.go", false},
+		{"src/*.go", "src/a/main.go", false},
+		{"vendor/**", "vendor/github.com/x/y.go", true},
+		{"vendor/**", "vendor", false},
+		{"**/testdata/*", "internal/hash/testdata/x.bin", true},
+		{"./docs/*.md", "docs/index.md", true},
+		{"/docs/*.md", "docs/index.md", true},
+		{"docs/", "docs", true},
+		{"file?.txt", "file1.txt", true},
+		{"[ab].txt", "c.txt", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.rel, func(t *testing.T) {
+			if got := MatchGlob(tt.pattern, tt.rel); got != tt.want {
+				t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.rel, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlobCoversDir(t *testing.T) {
+	tests := []struct {
+		pattern string
+		rel     string
+		want    bool
+	}{
+		{"vendor/**", "vendor", true},
+		{"vendor", "vendor", true},
+		{"vendor", "lib/vendor", true},
+		{"node_modules/", "web/node_modules", true},
+		{"vendor/*.go", "vendor", false},
+		{"*.go", "src", false},
+		{"src/**/gen", "src/a/gen", true},
+	}
+	for _, tt := range tests {
+		if got := globCoversDir(tt.pattern, tt.rel); got != tt.want {
+			t.Errorf("globCoversDir(%q, %q) = %v, want %v", tt.pattern, tt.rel, got, tt.want)
+		}
+	}
+}
+
+func TestExpandGlob(t *testing.T) {
+	dir := t.TempDir()
+	for _, rel := range []string{
+		"build/lib.so", "build/x86/a.so", "build/x86/b.o", "build/arm/c.so", "build/.cache/d.so", "src/main.go",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, nil, 0644)
+	}
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"build/**/*.so", []string{"build/arm/c.so", "build/lib.so", "build/x86/a.so"}},
+		{"build/*/*.so", []string{"build/arm/c.so", "build/x86/a.so"}},
+		{"build/.*/*.so", []string{"build/.cache/d.so"}},
+		{"*/main.go", []string{"src/main.go"}},
+		{"src/**", []string{"src/main.go"}},
+		{"build/*", []string{"build/arm", "build/lib.so", "build/x86"}},
+		{"nothing/**/*.so", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got := ExpandGlob(filepath.Join(dir, filepath.FromSlash(tt.pattern)))
+			for i, p := range got {
+				rel, _ := filepath.Rel(dir, p)
+				got[i] = filepath.ToSlash(rel)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ExpandGlob(%q) = %v, want %v", tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscover_PathPatterns(t *testing.T) {
+	dir := t.TempDir()
+	for _, rel := range []string{"main.go", "src/a.go", "src/deep/b.go", "src/deep/b.txt", "vendor/x/y.go"} {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, nil, 0644)
+	}
+
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    []string
+	}{
+		{"basename", []string{"*.go"}, nil, []string{"main.go", "src/a.go", "src/deep/b.go", "vendor/x/y.go"}},
+		{"doublestar include", []string{"src/**/*.go"}, nil, []string{"src/a.go", "src/deep/b.go"}},
+		{"directory exclude", nil, []string{"vendor/**"}, []string{"main.go", "src/a.go", "src/deep/b.go", "src/deep/b.txt"}},
+		{"both", []string{"**/*.go"}, []string{"src/deep"}, []string{"main.go", "src/a.go", "vendor/x/y.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DiscoveryOptions{Recursive: true, MaxSize: -1, Include: tt.include, Exclude: tt.exclude}
+			files, err := DiscoverFiles(context.Background(), []string{dir}, opts)
+			if err != nil {
+				t.Fatalf("DiscoverFiles() error = %v", err)
+			}
+			for i, f := range files {
+				rel, _ := filepath.Rel(dir, f)
+				files[i] = filepath.ToSlash(rel)
+			}
+			if strings.Join(files, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v, want %v", files, tt.want)
+			}
+		})
+	}
+
+	// An excluded directory is pruned, not merely filtered: an unreadable
+	// one would otherwise be reported.
+	if os.Geteuid() != 0 {
+		locked := filepath.Join(dir, "vendor", "x")
+		os.Chmod(locked, 0)
+		defer os.Chmod(locked, 0755)
+		if _, err := DiscoverFiles(context.Background(), []string{dir}, DiscoveryOptions{Recursive: true, MaxSize: -1, Exclude: []string{"vendor/**"}}); err != nil {
+			t.Errorf("excluded directory was opened: %v", err)
+		}
+	}
+}
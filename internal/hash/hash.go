@@ -6,47 +6,105 @@
 // exhaust available system memory if the entire file is loaded at once.
 //
 // This package prioritizes memory efficiency by using "Streaming". Instead of
-// ioutil.ReadFile (which buffers everything), we use io.Copy to pipe data from
-// an open file handle directly into the cryptographic hasher. This maintains a
-// constant, small memory footprint regardless of file size.
+// ioutil.ReadFile (which buffers everything), we pipe data from an open file
+// handle directly into the cryptographic hasher through a pooled, fixed-size
+// buffer (see buffer.go). This maintains a constant, small memory footprint
+// regardless of file size.
 package hash
 
 import (
-	"crypto/md5"
-	"crypto/sha1"
-	"crypto/sha256"
-	"crypto/sha512"
+	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"hash"
 	"io"
 	"os"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
-
-	"golang.org/x/crypto/blake2b"
 )
 
-// Supported hash algorithms
+// Supported hash algorithms. See registry.go for their definitions.
 const (
-	AlgorithmSHA256  = "sha256"
-	AlgorithmMD5     = "md5"
-	AlgorithmSHA1    = "sha1"
-	AlgorithmSHA512  = "sha512"
-	AlgorithmBLAKE2b = "blake2b"
+	AlgorithmSHA256   = "sha256"
+	AlgorithmMD5      = "md5"
+	AlgorithmSHA1     = "sha1"
+	AlgorithmSHA512   = "sha512"
+	AlgorithmBLAKE2b  = "blake2b"
+	AlgorithmSHA3_256 = "sha3-256"
+	AlgorithmSHA3_512 = "sha3-512"
+	AlgorithmBLAKE2s  = "blake2s-256"
+	AlgorithmBLAKE3   = "blake3"
+	AlgorithmXXH64    = "xxh64"
+	AlgorithmCRC32C   = "crc32c"
+
+	// Sampled fingerprints; see sampled.go.
+	AlgorithmSampledSHA256 = SampledPrefix + "sha256"
+	AlgorithmSampledXXH64  = SampledPrefix + "xxh64"
+
+	// Keyed algorithms; see NewKeyedComputer.
+	AlgorithmHMACSHA256   = "hmac-sha256"
+	AlgorithmHMACSHA512   = "hmac-sha512"
+	AlgorithmBLAKE2bKeyed = "blake2b-keyed"
 )
 
+// StdinEntryName is the Original of the entry produced by hashing standard input.
+const StdinEntryName = "<stdin>"
+
 // Entry represents a hash computation result for a single file or input.
 type Entry struct {
-	Original    string    // Original argument (file path or hash string)
-	Hash        string    // Computed or provided hash value
-	IsFile      bool      // True if this entry represents a file
-	IsReference bool      // True if this entry represents a user-provided reference hash
-	Error       error     // Processing error, if any
-	Size        int64     // File size in bytes
-	ModTime     time.Time // File modification time
-	Algorithm   string    // Hash algorithm used
+	Original    string            // Original argument (file path or hash string)
+	Hash        string            // Computed or provided hash value (primary algorithm)
+	Hashes      map[string]string // Digest per algorithm when computed; includes the primary
+	IsFile      bool              // True if this entry represents a file
+	IsReference bool              // True if this entry represents a user-provided reference hash
+	Error       error             // Processing error, if any
+	Size        int64             // File size in bytes
+	ModTime     time.Time         // File modification time
+	Mode        os.FileMode       // File mode, from the same stat as Size and ModTime
+	Algorithm   string            // Primary hash algorithm used
+	Cached      bool              // True if the digests came from the hash cache instead of the file
+}
+
+// Digest pairs an algorithm with the digest it produced.
+type Digest struct {
+	Algorithm string
+	Hash      string
+}
+
+// Digests returns every digest carried by the entry. The primary algorithm
+// comes first and any others follow in registry order, so output is stable
+// regardless of map iteration order. Entries without a Hashes map (such as
+// reference hashes) yield their single Hash.
+func (e Entry) Digests() []Digest {
+	if len(e.Hashes) == 0 {
+		return []Digest{{Algorithm: e.Algorithm, Hash: e.Hash}}
+	}
+	digests := make([]Digest, 0, len(e.Hashes))
+	if h, ok := e.Hashes[e.Algorithm]; ok {
+		digests = append(digests, Digest{Algorithm: e.Algorithm, Hash: h})
+	}
+	for _, spec := range builtinAlgorithms {
+		if spec.Name == e.Algorithm {
+			continue
+		}
+		if h, ok := e.Hashes[spec.Name]; ok {
+			digests = append(digests, Digest{Algorithm: spec.Name, Hash: h})
+		}
+	}
+	return digests
+}
+
+// HasDigest reports whether any of the entry's digests equals h (case-insensitive).
+func (e Entry) HasDigest(h string) bool {
+	for _, d := range e.Digests() {
+		if strings.EqualFold(d.Hash, h) {
+			return true
+		}
+	}
+	return false
 }
 
 // MatchGroup represents a group of entries with matching hashes.
@@ -55,6 +113,10 @@ type MatchGroup struct {
 	Hash    string  // The common hash value
 	Entries []Entry // All entries with this hash
 	Count   int     // Number of entries in the group
+
+	// Set by FindDuplicates only.
+	Size   int64 // Size of each file in the group
+	Wasted int64 // Bytes reclaimable by keeping a single copy
 }
 
 // PoolMatch represents a match between a file and a provided hash string.
@@ -77,63 +139,236 @@ type Result struct {
 	Duration       time.Duration // Total processing time
 	FilesProcessed int           // Number of files processed
 	BytesProcessed int64         // Total bytes processed
+	Incomplete     bool          // True if processing was cancelled before every file was hashed
 }
 
 // Computer handles hash computation for files.
 // It abstracts away the specific algorithm implementation from the caller.
 type Computer struct {
-	algorithm string
+	algorithm string          // Primary algorithm (first requested)
+	specs     []AlgorithmSpec // All requested algorithms, primary first
+	key       []byte          // Secret for keyed algorithms; never exposed
+	progress  func(n int64)   // Optional byte-progress callback
+	archives  *ArchiveLimits  // Non-nil when archive members should be hashed too
+	cache     Cache           // Optional digests of unchanged files; see SetCache
+	buffers   *bufferPool     // Read buffers; nil means defaultBuffers. See SetBufferSize
+
+	deviceWorkers DeviceWorkers // Optional per-device pool sizes; see SetDeviceWorkers
+	limiter       *rateLimiter  // Optional bandwidth cap shared by all workers; see SetMaxRate
+	adaptive      *Adaptive     // Optional throughput-driven worker limit; see SetAdaptive
+}
+
+// ErrKeyRequired is returned when a keyed algorithm is requested without a key.
+var ErrKeyRequired = errors.New("keyed algorithm requires a key")
+
+// ErrSampledNeedsFile is returned when a sampled algorithm is asked to hash a
+// stream: sampling needs random access to data of known size.
+var ErrSampledNeedsFile = errors.New("sampled algorithms need a regular file, not a stream")
+
+// NewComputer creates a new hash computer with the specified algorithms.
+// The first algorithm is the primary one: its digest populates Entry.Hash and
+// drives match grouping. Any further algorithms are computed in the same pass
+// over the data. Aliases are accepted and resolved to canonical names.
+// Keyed algorithms need NewKeyedComputer instead.
+func NewComputer(algorithms ...string) (*Computer, error) {
+	return newComputer(nil, algorithms)
+}
+
+// NewKeyedComputer is NewComputer for runs that include keyed algorithms
+// (HMAC, keyed BLAKE2b). The key is used only by keyed algorithms; plain ones
+// in the same run are computed as usual. The Computer keeps its own copy of
+// key and never reveals it.
+func NewKeyedComputer(key []byte, algorithms ...string) (*Computer, error) {
+	if len(key) == 0 {
+		return nil, ErrKeyRequired
+	}
+	return newComputer(append([]byte(nil), key...), algorithms)
 }
 
-// NewComputer creates a new hash computer with the specified algorithm.
-func NewComputer(algorithm string) (*Computer, error) {
+func newComputer(key []byte, algorithms []string) (*Computer, error) {
 	// We perform validation here to ensure the Computer is always in a valid state
 	// when used in subsequent hashing operations.
-	switch algorithm {
-	case AlgorithmSHA256, AlgorithmMD5, AlgorithmSHA1, AlgorithmSHA512, AlgorithmBLAKE2b:
-		return &Computer{algorithm: algorithm}, nil
-	default:
-		return nil, fmt.Errorf("unsupported algorithm: %s", algorithm)
+	if len(algorithms) == 0 {
+		return nil, fmt.Errorf("no algorithm specified")
+	}
+	c := &Computer{key: key}
+	seen := make(map[string]bool, len(algorithms))
+	for _, algorithm := range algorithms {
+		spec, ok := LookupAlgorithm(algorithm)
+		if !ok {
+			return nil, fmt.Errorf("unsupported algorithm: %s", algorithm)
+		}
+		if spec.Keyed {
+			if key == nil {
+				return nil, fmt.Errorf("%s: %w", spec.Name, ErrKeyRequired)
+			}
+			if spec.MaxKeySize > 0 && len(key) > spec.MaxKeySize {
+				return nil, fmt.Errorf("%s keys are at most %d bytes, got %d", spec.Name, spec.MaxKeySize, len(key))
+			}
+		}
+		if seen[spec.Name] {
+			continue
+		}
+		seen[spec.Name] = true
+		c.specs = append(c.specs, spec)
+	}
+	c.algorithm = c.specs[0].Name
+	return c, nil
+}
+
+// Keyed reports whether any of the computer's algorithms uses the secret key.
+func (c *Computer) Keyed() bool {
+	for _, spec := range c.specs {
+		if spec.Keyed {
+			return true
+		}
+	}
+	return false
+}
+
+// Sampled reports whether any of the computer's algorithms is sampled.
+func (c *Computer) Sampled() bool {
+	for _, spec := range c.specs {
+		if spec.Sampled {
+			return true
+		}
+	}
+	return false
+}
+
+// streams reports whether any configured algorithm reads the whole file.
+func (c *Computer) streams() bool {
+	for _, spec := range c.specs {
+		if !spec.Sampled {
+			return true
+		}
 	}
+	return false
 }
 
-// newHasher returns a new hash.Hash for the configured algorithm.
+// SetProgressFunc registers fn to be told how many bytes were just read each
+// time ComputeFile pulls a chunk from disk. Large files therefore report
+// progress while they are being hashed rather than only when they finish.
+// fn is called from worker goroutines and must be safe for concurrent use.
+func (c *Computer) SetProgressFunc(fn func(n int64)) {
+	c.progress = fn
+}
+
+// newHasher returns a new hash.Hash for the primary algorithm.
 // Note: This uses the standard library hash.Hash interface, allowing us
 // to handle different algorithms polymorphically.
 func (c *Computer) newHasher() hash.Hash {
-	switch c.algorithm {
-	case AlgorithmMD5:
-		return md5.New()
-	case AlgorithmSHA1:
-		return sha1.New()
-	case AlgorithmSHA512:
-		return sha512.New()
-	case AlgorithmBLAKE2b:
-		// BLAKE2b-512 (64 bytes output)
-		// BLAKE2b is often faster than SHA-2 on modern CPUs.
-		h, _ := blake2b.New512(nil)
-		return h
-	default:
-		// We default to SHA-256 as it is the current industry standard
-		// for cryptographic security and compatibility.
-		return sha256.New()
+	return c.hasherFor(c.specs[0])
+}
+
+// hasherFor constructs a hasher for spec, supplying the key to keyed algorithms.
+func (c *Computer) hasherFor(spec AlgorithmSpec) hash.Hash {
+	if spec.Keyed {
+		return spec.NewKeyed(c.key)
+	}
+	return spec.New()
+}
+
+// digestStream feeds r through every configured hasher in a single pass.
+// Sampled algorithms cannot hash a stream, so their presence is an error.
+func (c *Computer) digestStream(r io.Reader) (map[string]string, int64, error) {
+	if c.Sampled() {
+		return nil, 0, ErrSampledNeedsFile
+	}
+	return c.digestSpecs(r, c.specs)
+}
+
+// digestSpecs feeds r through a hasher for each of specs in a single pass.
+//
+// A single io.MultiWriter fans each buffer out to all hashers, so requesting
+// "sha256,md5" costs one read of the file rather than two.
+func (c *Computer) digestSpecs(r io.Reader, specs []AlgorithmSpec) (map[string]string, int64, error) {
+	hashers := make([]hash.Hash, len(specs))
+	writers := make([]io.Writer, len(specs))
+	for i, spec := range specs {
+		hashers[i] = c.hasherFor(spec)
+		writers[i] = hashers[i]
 	}
+
+	var w io.Writer = hashers[0]
+	if len(writers) > 1 {
+		w = io.MultiWriter(writers...)
+	}
+
+	// copyBuffered handles the heavy lifting of reading from the source and
+	// writing to the hashers, reusing a pooled buffer rather than allocating
+	// one per file.
+	size, err := c.copyBuffered(w, r)
+	if err != nil {
+		return nil, size, err
+	}
+
+	digests := make(map[string]string, len(c.specs))
+	for i, spec := range specs {
+		digests[spec.Name] = hex.EncodeToString(hashers[i].Sum(nil))
+	}
+	return digests, size, nil
 }
 
+// digestFile computes every configured digest of an open file: streaming
+// algorithms in one pass over r, sampled ones from their blocks of f.
+func (c *Computer) digestFile(ctx context.Context, r io.Reader, f io.ReaderAt, size int64) (map[string]string, int64, error) {
+	var streaming, sampled []AlgorithmSpec
+	for _, spec := range c.specs {
+		if spec.Sampled {
+			sampled = append(sampled, spec)
+		} else {
+			streaming = append(streaming, spec)
+		}
+	}
+
+	digests := make(map[string]string, len(c.specs))
+	if len(streaming) > 0 {
+		var err error
+		if digests, size, err = c.digestSpecs(r, streaming); err != nil {
+			return nil, size, err
+		}
+	} else if c.progress != nil {
+		// Nothing streams the file, so count it as covered once sampled;
+		// otherwise a byte-based progress bar would never move.
+		defer c.progress(size)
+	}
+	for _, spec := range sampled {
+		digest, err := sampleDigest(ctx, f, size, spec)
+		if err != nil {
+			return nil, size, err
+		}
+		digests[spec.Name] = digest
+	}
+	return digests, size, nil
+}
+
+// adviseMinSize is the smallest file ComputeFile gives the kernel access
+// hints for. A smaller file is read in a few system calls, which the hints
+// would double, and holds too few pages to crowd the page cache.
+const adviseMinSize = 8 * 1024 * 1024
+
 // ComputeFile computes the hash of a file using a streaming approach.
 //
 // STEP-BY-STEP PROCESS:
-// 1. Open the file handle (read-only).
-// 2. Fetch file metadata (size, modtime) for the result entry.
-// 3. Initialize the cryptographic hasher.
-// 4. Use io.Copy to stream data in chunks from the file to the hasher.
-// 5. Finalize the hash (Sum) and convert the binary digest to a hex string.
-func (c *Computer) ComputeFile(path string) (*Entry, error) {
+//  1. Open the file handle (read-only).
+//  2. Fetch file metadata (size, modtime) for the result entry.
+//  3. Initialize one hasher per requested algorithm.
+//  4. Stream data in chunks through a pooled buffer from the file to all
+//     hashers, hinting to the kernel that a large file is read sequentially.
+//  5. Finalize each hash (Sum) and convert the binary digests to hex strings.
+//
+// With a cache (see SetCache), a file whose identity is unchanged since it
+// was last hashed skips steps 3-5 and is returned with Cached set.
+//
+// The copy checks ctx between chunks, so cancellation takes effect mid-file
+// rather than only once the file is finished.
+func (c *Computer) ComputeFile(ctx context.Context, path string) (*Entry, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
-	// defer ensures the file handle is closed even if io.Copy fails,
+	// defer ensures the file handle is closed even if hashing fails,
 	// preventing file descriptor leaks.
 	defer file.Close()
 
@@ -143,96 +378,369 @@ func (c *Computer) ComputeFile(path string) (*Entry, error) {
 		return nil, err
 	}
 
-	// io.Copy handles the heavy lifting of reading from the file and writing
-	// to the hasher. By default, it uses a 32KB buffer, which is a good
-	// balance between memory usage and performance.
-	hasher := c.newHasher()
-	size, err := io.Copy(hasher, file)
+	// An unchanged file already in the cache is not read at all.
+	id, cacheable := c.cacheID(info)
+	if cacheable {
+		if digests, ok := c.cachedDigests(id); ok {
+			if c.progress != nil {
+				c.progress(info.Size())
+			}
+			entry := c.fileEntry(path, digests, info.Size(), info)
+			entry.Cached = true
+			return entry, nil
+		}
+	}
+
+	r := c.counted(c.diskReader(ctx, file))
+	ra := c.diskReaderAt(ctx, file)
+
+	// Read a large file ahead for a full pass, and drop its pages once
+	// hashed so a large batch does not push everything else out of the
+	// page cache; but only the pages chexum brought in, not a file that
+	// another program has cached.
+	var drop bool
+	if c.streams() && info.Size() >= adviseMinSize {
+		drop = !alreadyCached(file, info.Size())
+		adviseSequential(file)
+	}
+	digests, size, err := c.digestFile(ctx, r, ra, info.Size())
+	if drop {
+		adviseDone(file)
+	}
 	if err != nil {
 		return nil, err
 	}
+	if cacheable {
+		c.remember(file, id, digests)
+	}
+
+	return c.fileEntry(path, digests, size, info), nil
+}
 
+// fileEntry builds the entry for a hashed file.
+func (c *Computer) fileEntry(path string, digests map[string]string, size int64, info os.FileInfo) *Entry {
 	return &Entry{
 		Original:  path,
-		Hash:      hex.EncodeToString(hasher.Sum(nil)),
+		Hash:      digests[c.algorithm],
+		Hashes:    digests,
 		IsFile:    true,
 		Size:      size,
 		ModTime:   info.ModTime(),
+		Mode:      info.Mode(),
+		Algorithm: c.algorithm,
+	}
+}
+
+// ComputeStream hashes everything read from r with every configured
+// algorithm and returns it as an entry named name, e.g. StdinEntryName.
+// Unlike ComputeReader it honours ctx and the progress callback, so a long
+// pipe behaves like a large file.
+func (c *Computer) ComputeStream(ctx context.Context, name string, r io.Reader) (*Entry, error) {
+	r = &contextReader{ctx: ctx, r: r}
+	if c.progress != nil {
+		r = &countingReader{r: r, report: c.progress}
+	}
+
+	digests, size, err := c.digestStream(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Entry{
+		Original:  name,
+		Hash:      digests[c.algorithm],
+		Hashes:    digests,
+		IsFile:    true,
+		Size:      size,
 		Algorithm: c.algorithm,
 	}, nil
 }
 
+// contextReader aborts a read loop once its context is cancelled.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// Read implements io.Reader.
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
+// diskReader wraps r, which reads a file's content from disk, so that reads
+// stop once ctx is cancelled and are charged to the --max-rate limiter.
+// Every read of file content goes through it or diskReaderAt.
+func (c *Computer) diskReader(ctx context.Context, r io.Reader) io.Reader {
+	r = &contextReader{ctx: ctx, r: r}
+	if c.limiter != nil {
+		r = &limitedReader{ctx: ctx, r: r, limiter: c.limiter}
+	}
+	return r
+}
+
+// diskReaderAt is diskReader for random access, as used by sampling.
+func (c *Computer) diskReaderAt(ctx context.Context, r io.ReaderAt) io.ReaderAt {
+	if c.limiter != nil {
+		return &limitedReaderAt{ctx: ctx, r: r, limiter: c.limiter}
+	}
+	return r
+}
+
+// counted wraps r so that what it reads is reported to the progress
+// callback and the adaptive controller.
+func (c *Computer) counted(r io.Reader) io.Reader {
+	if c.progress != nil {
+		r = &countingReader{r: r, report: c.progress}
+	}
+	if c.adaptive != nil {
+		r = &countingReader{r: r, report: c.adaptive.add}
+	}
+	return r
+}
+
+// countingReader reports the size of every successful read.
+type countingReader struct {
+	r      io.Reader
+	report func(n int64)
+}
+
+// Read implements io.Reader.
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	if n > 0 {
+		cr.report(int64(n))
+	}
+	return n, err
+}
+
 // ComputeReader computes the hash of data from an io.Reader.
 // This allows hashing data from stdin or network streams.
+// Only the primary digest is returned; see ComputeReaderAll for the rest.
 func (c *Computer) ComputeReader(r io.Reader) (string, error) {
+	if c.specs[0].Sampled {
+		return "", ErrSampledNeedsFile
+	}
 	hasher := c.newHasher()
-	if _, err := io.Copy(hasher, r); err != nil {
+	if _, err := c.copyBuffered(hasher, r); err != nil {
 		return "", err
 	}
 	return hex.EncodeToString(hasher.Sum(nil)), nil
 }
 
+// ComputeReaderAll computes every configured digest of r in a single pass.
+// It returns the digests keyed by algorithm and the number of bytes read.
+func (c *Computer) ComputeReaderAll(r io.Reader) (map[string]string, int64, error) {
+	return c.digestStream(r)
+}
+
 // ComputeBytes computes the hash of a byte slice.
 // Used primarily for testing or very small metadata strings.
 func (c *Computer) ComputeBytes(data []byte) string {
+	if c.specs[0].Sampled {
+		return sampledBytes(data, c.specs[0])
+	}
 	hasher := c.newHasher()
 	hasher.Write(data)
 	// hasher.Sum(nil) appends the current hash to nil, returning the digest.
 	return hex.EncodeToString(hasher.Sum(nil))
 }
 
-// ComputeBatch performs hashing of multiple files using a worker pool.
+// ComputeBatch performs hashing of multiple files using worker pools.
 // It returns a channel that will receive the results as they are computed.
+// See ComputeFiles, which it feeds, for how the work is spread out.
+func (c *Computer) ComputeBatch(ctx context.Context, files []string, workers int) <-chan Entry {
+	found := make(chan Found)
+	go func() {
+		defer close(found)
+		for _, f := range files {
+			select {
+			case found <- Found{Path: f}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return c.ComputeFiles(ctx, found, workers)
+}
+
+// ComputeFiles hashes files as they arrive, e.g. from Discover, so hashing
+// starts long before a large tree has been walked. The returned channel
+// closes once files has been closed and every file hashed.
+//
+// Files are grouped by the device they live on, taken from the stat that
+// discovery already made; only files that did not come from Discover are
+// stat'ed here. Each device gets its own pool of workers, as many as the
+// function given to SetDeviceWorkers says. Pools without a size of their
+// own share workers between them, so a run spanning several devices
+// never reads more than workers files at once from them. Results from all
+// pools arrive on the one channel in no particular order. With
+// SetAdaptive, the controller additionally decides how many workers hash
+// at once.
+//
+// When ctx is cancelled the feeder stops handing out files and in-flight
+// files are abandoned mid-read. Abandoned files produce no entry at all, so
+// the channel only ever carries completed hashes and genuine errors; callers
+// detect the partial run via ctx.Err() once the channel closes. Paths still
+// unsent at that point are never read, so senders must stop on ctx.Done().
 //
 // Reviewed: NESTED-LOOP - Standard worker pool pattern for concurrent processing.
-func (c *Computer) ComputeBatch(files []string, workers int) <-chan Entry {
+func (c *Computer) ComputeFiles(ctx context.Context, files <-chan Found, workers int) <-chan Entry {
 	if workers <= 0 {
 		workers = runtime.NumCPU()
 	}
 
-	jobs := make(chan string, workers)
 	// Buffer results slightly to prevent tight coupling, though the consumer should be fast
 	results := make(chan Entry, workers)
+	var wg sync.WaitGroup
 
-	// Feeder
+	// Feeder: route each file to its device's queue, starting that
+	// device's pool the first time the device is seen. It counts as a
+	// worker itself, so the closer cannot finish before any pool starts.
+	// Pools of the default size take a slot in shared before each file.
+	shared := make(chan struct{}, workers)
+	wg.Add(1)
 	go func() {
-		for _, f := range files {
-			jobs <- f
+		queues := make(map[deviceKey]*queue[string])
+		defer func() {
+			for _, q := range queues {
+				q.close()
+			}
+			wg.Done()
+		}()
+		for {
+			var f Found
+			select {
+			case found, ok := <-files:
+				if !ok {
+					return
+				}
+				f = found
+			case <-ctx.Done():
+				return
+			}
+			key := f.device
+			if !key.known {
+				device, known := DeviceOf(f.Path)
+				key = deviceKey{device: device, known: known}
+			}
+			q, ok := queues[key]
+			if !ok {
+				q = newQueue[string]()
+				queues[key] = q
+				n, slots := c.workersFor(key), (chan struct{})(nil)
+				if n <= 0 {
+					n, slots = workers, shared
+				}
+				wg.Add(n)
+				for i := 0; i < n; i++ {
+					go c.hashWorker(ctx, q, slots, results, &wg)
+				}
+			}
+			q.push(f.Path)
 		}
-		close(jobs)
 	}()
 
-	// Workers
-	var wg sync.WaitGroup
-	wg.Add(workers)
-	for i := 0; i < workers; i++ {
-		go func() {
-			defer wg.Done()
-			for path := range jobs {
-				entry, err := c.ComputeFile(path)
-				if err != nil {
-					results <- Entry{Original: path, Error: err}
-				} else {
-					results <- *entry
-				}
-			}
-		}()
+	var stop chan struct{}
+	if c.adaptive != nil {
+		stop = make(chan struct{})
+		go c.adaptive.run(ctx, stop)
 	}
 
 	// Closer
 	go func() {
 		wg.Wait()
+		if stop != nil {
+			close(stop)
+		}
 		close(results)
 	}()
 
 	return results
 }
 
-// Algorithm returns the algorithm used by this computer.
+// hashWorker hashes paths from q until it is closed and drained. With
+// slots, it holds one of them while it reads each file.
+func (c *Computer) hashWorker(ctx context.Context, q *queue[string], slots chan struct{}, results chan<- Entry, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for {
+		path, ok := q.pop()
+		if !ok {
+			return
+		}
+		if ctx.Err() != nil {
+			continue // Drain remaining jobs without hashing them
+		}
+		if slots != nil {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				continue
+			}
+		}
+		c.hashPath(ctx, path, results)
+		if slots != nil {
+			<-slots
+		}
+	}
+}
+
+// hashPath hashes one file for hashWorker and sends its entries.
+func (c *Computer) hashPath(ctx context.Context, path string, results chan<- Entry) {
+	if c.adaptive != nil {
+		c.adaptive.enter()
+	}
+	entry, err := c.ComputeFile(ctx, path)
+	if c.adaptive != nil {
+		c.adaptive.leave()
+	}
+	if err != nil {
+		if !isCancellation(ctx, err) {
+			results <- Entry{Original: path, Error: err}
+		}
+		return
+	}
+	results <- *entry
+	if c.archives != nil && IsArchive(path) {
+		c.emitArchive(ctx, path, results)
+	}
+}
+
+// emitArchive sends an entry for every member of the archive at path.
+// An archive that cannot be opened yields a single error entry for path.
+func (c *Computer) emitArchive(ctx context.Context, path string, results chan<- Entry) {
+	err := c.ComputeArchive(ctx, path, *c.archives, func(e Entry) {
+		if e.Error != nil && isCancellation(ctx, e.Error) {
+			return
+		}
+		results <- e
+	})
+	if err != nil && !isCancellation(ctx, err) {
+		results <- Entry{Original: path, Error: fmt.Errorf("cannot read archive %s: %w", path, err)}
+	}
+}
+
+// isCancellation reports whether err was caused by ctx being cancelled.
+func isCancellation(ctx context.Context, err error) bool {
+	return ctx.Err() != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded))
+}
+
+// Algorithm returns the primary algorithm used by this computer.
 func (c *Computer) Algorithm() string {
 	return c.algorithm
 }
 
+// Algorithms returns every algorithm computed by this computer, primary first.
+func (c *Computer) Algorithms() []string {
+	names := make([]string, len(c.specs))
+	for i, spec := range c.specs {
+		names[i] = spec.Name
+	}
+	return names
+}
+
 // DetectHashAlgorithm returns possible algorithms for a hash string.
 //
 // RATIONALE:
@@ -247,20 +755,9 @@ func DetectHashAlgorithm(hashStr string) []string {
 		return []string{}
 	}
 
-	// Map lengths to algorithms
-	switch len(hashStr) {
-	case 32:
-		return []string{AlgorithmMD5}
-	case 40:
-		return []string{AlgorithmSHA1}
-	case 64:
-		return []string{AlgorithmSHA256}
-	case 128:
-		// Ambiguous - could be SHA-512 or BLAKE2b-512 as both output 512 bits.
-		return []string{AlgorithmSHA512, AlgorithmBLAKE2b}
-	default:
-		return []string{}
-	}
+	// Map lengths to algorithms. Several algorithms share a digest size
+	// (e.g. SHA-512, BLAKE2b-512 and SHA3-512), so the result may be ambiguous.
+	return algorithmsForHexLength(len(hashStr))
 }
 
 // isValidHexString checks if a string contains only valid hexadecimal characters.
@@ -280,23 +777,12 @@ func isValidHexString(s string) bool {
 
 // IsValidHash checks if a string is a valid hash for the given algorithm.
 func IsValidHash(hash, algorithm string) bool {
-	expectedLen := 0
-	switch algorithm {
-	case AlgorithmMD5:
-		expectedLen = 32
-	case AlgorithmSHA1:
-		expectedLen = 40
-	case AlgorithmSHA256:
-		expectedLen = 64
-	case AlgorithmSHA512:
-		expectedLen = 128
-	case AlgorithmBLAKE2b:
-		expectedLen = 128 // BLAKE2b-512 produces 64 bytes = 128 hex chars
-	default:
+	spec, ok := LookupAlgorithm(algorithm)
+	if !ok {
 		return false
 	}
 
-	if len(hash) != expectedLen {
+	if len(hash) != spec.HexLength() {
 		return false
 	}
 
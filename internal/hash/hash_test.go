@@ -3,6 +3,8 @@ package hash
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
@@ -24,6 +26,12 @@ func TestNewComputer(t *testing.T) {
 		{"sha1", AlgorithmSHA1, false},
 		{"sha512", AlgorithmSHA512, false},
 		{"blake2b", AlgorithmBLAKE2b, false},
+		{"sha3-256", AlgorithmSHA3_256, false},
+		{"sha3-512", AlgorithmSHA3_512, false},
+		{"blake2s-256", AlgorithmBLAKE2s, false},
+		{"blake3", AlgorithmBLAKE3, false},
+		{"xxh64", AlgorithmXXH64, false},
+		{"crc32c", AlgorithmCRC32C, false},
 		{"invalid", "invalid", true},
 	}
 
@@ -90,6 +98,42 @@ var computeBytesTests = []struct {
 		[]byte("hello"),
 		"e4cfa39a3d37be31c59609e807970799caa68a19bfaa15135f165085e01d41a65ba1e1b146aeb6bd0092b49eac214c103ccfa3a365954bbbe52f74a2b3620c94",
 	},
+	{
+		"sha3-256 empty",
+		AlgorithmSHA3_256,
+		[]byte{},
+		"a7ffc6f8bf1ed76651c14756a061d662f580ff4de43b49fa82d80a4b80f8434a",
+	},
+	{
+		"sha3-512 empty",
+		AlgorithmSHA3_512,
+		[]byte{},
+		"a69f73cca23a9ac5c8b567dc185a756e97c982164fe25859e0d1dcc1475c80a615b2123af1f5f94c11e3e9402c3ac558f500199d95b6d3e301758586281dcd26",
+	},
+	{
+		"blake2s-256 empty",
+		AlgorithmBLAKE2s,
+		[]byte{},
+		"69217a3079908094e11121d042354a7c1f55b6482ca1a51e1b250dfd1ed0eef9",
+	},
+	{
+		"blake3 empty",
+		AlgorithmBLAKE3,
+		[]byte{},
+		"af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262",
+	},
+	{
+		"xxh64 empty",
+		AlgorithmXXH64,
+		[]byte{},
+		"ef46db3751d8e999",
+	},
+	{
+		"crc32c check value",
+		AlgorithmCRC32C,
+		[]byte("123456789"),
+		"e3069283",
+	},
 }
 
 // TestComputeBytes tests hash computation for byte slices.
@@ -147,7 +191,7 @@ func TestComputeFile(t *testing.T) {
 		t.Fatalf("NewComputer() error = %v", err)
 	}
 
-	entry, err := c.ComputeFile(tmpFile)
+	entry, err := c.ComputeFile(context.Background(), tmpFile)
 	if err != nil {
 		t.Fatalf("ComputeFile() error = %v", err)
 	}
@@ -173,6 +217,41 @@ func TestComputeFile(t *testing.T) {
 	}
 }
 
+// TestComputeFile_MultiAlgorithm verifies several digests come from one pass.
+func TestComputeFile_MultiAlgorithm(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "multi.txt")
+	if err := os.WriteFile(tmpFile, []byte("hello"), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	c, err := NewComputer(AlgorithmSHA256, AlgorithmMD5, AlgorithmMD5)
+	if err != nil {
+		t.Fatalf("NewComputer() error = %v", err)
+	}
+	if got := c.Algorithms(); !reflect.DeepEqual(got, []string{AlgorithmSHA256, AlgorithmMD5}) {
+		t.Errorf("Algorithms() = %v, want duplicates removed", got)
+	}
+
+	entry, err := c.ComputeFile(context.Background(), tmpFile)
+	if err != nil {
+		t.Fatalf("ComputeFile() error = %v", err)
+	}
+	if entry.Hash != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+		t.Errorf("primary Hash = %s", entry.Hash)
+	}
+	if entry.Hashes[AlgorithmMD5] != "5d41402abc4b2a76b9719d911017c592" {
+		t.Errorf("md5 digest = %s", entry.Hashes[AlgorithmMD5])
+	}
+
+	digests := entry.Digests()
+	if len(digests) != 2 || digests[0].Algorithm != AlgorithmSHA256 || digests[1].Algorithm != AlgorithmMD5 {
+		t.Errorf("Digests() = %v, want primary first", digests)
+	}
+	if !entry.HasDigest("5D41402ABC4B2A76B9719D911017C592") {
+		t.Error("HasDigest() should match secondary digests case-insensitively")
+	}
+}
+
 // TestComputeBatch tests parallel hash computation for multiple files.
 func TestComputeBatch(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -186,7 +265,7 @@ func TestComputeBatch(t *testing.T) {
 	}
 
 	c, _ := NewComputer(AlgorithmSHA256)
-	results := c.ComputeBatch(files, 2)
+	results := c.ComputeBatch(context.Background(), files, 2)
 
 	count := 0
 	for entry := range results {
@@ -201,6 +280,84 @@ func TestComputeBatch(t *testing.T) {
 	}
 }
 
+// TestComputeBatch_Cancelled verifies that a cancelled context stops the batch
+// without reporting the abandoned files as errors.
+func TestComputeBatch_Cancelled(t *testing.T) {
+	tmpDir := t.TempDir()
+	var files []string
+	for i := 0; i < 20; i++ {
+		f := filepath.Join(tmpDir, fmt.Sprintf("file%d.txt", i))
+		os.WriteFile(f, []byte("content"), 0644)
+		files = append(files, f)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c, _ := NewComputer(AlgorithmSHA256)
+	count := 0
+	for entry := range c.ComputeBatch(ctx, files, 2) {
+		if entry.Error != nil {
+			t.Errorf("Cancelled file reported as error: %v", entry.Error)
+		}
+		count++
+	}
+	if count >= len(files) {
+		t.Errorf("Expected cancellation to skip files, got %d of %d results", count, len(files))
+	}
+
+	if _, err := c.ComputeFile(ctx, files[0]); !errors.Is(err, context.Canceled) {
+		t.Errorf("ComputeFile() with cancelled context: got %v, want context.Canceled", err)
+	}
+}
+
+// TestComputeFile_Progress verifies that every byte read is reported.
+func TestComputeFile_Progress(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "large.bin")
+	data := bytes.Repeat([]byte("x"), 100*1024)
+	os.WriteFile(tmpFile, data, 0644)
+
+	c, _ := NewComputer(AlgorithmSHA256)
+	c.SetBufferSize(32 * 1024) // Smaller than the file, so it takes several reads
+	var reported, calls int64
+	c.SetProgressFunc(func(n int64) {
+		reported += n
+		calls++
+	})
+
+	if _, err := c.ComputeFile(context.Background(), tmpFile); err != nil {
+		t.Fatalf("ComputeFile() error = %v", err)
+	}
+	if reported != int64(len(data)) {
+		t.Errorf("reported %d bytes, want %d", reported, len(data))
+	}
+	if calls < 2 {
+		t.Errorf("expected progress during the read, got %d callbacks", calls)
+	}
+}
+
+func TestComputeStream(t *testing.T) {
+	c, _ := NewComputer(AlgorithmSHA256, AlgorithmMD5)
+	data := []byte("piped data")
+
+	entry, err := c.ComputeStream(context.Background(), StdinEntryName, bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ComputeStream() error = %v", err)
+	}
+	if entry.Original != StdinEntryName || entry.Size != int64(len(data)) {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	if entry.Hash != c.ComputeBytes(data) || entry.Hashes[AlgorithmMD5] == "" {
+		t.Errorf("expected digests for every algorithm, got %v", entry.Hashes)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.ComputeStream(ctx, StdinEntryName, bytes.NewReader(data)); err == nil {
+		t.Error("expected an error from a cancelled stream")
+	}
+}
+
 // TestComputeFile_NotFound tests error handling for missing files.
 func TestComputeFile_NotFound(t *testing.T) {
 	c, err := NewComputer(AlgorithmSHA256)
@@ -208,7 +365,7 @@ func TestComputeFile_NotFound(t *testing.T) {
 		t.Fatalf("NewComputer() error = %v", err)
 	}
 
-	_, err = c.ComputeFile("/nonexistent/file.txt")
+	_, err = c.ComputeFile(context.Background(), "/nonexistent/file.txt")
 	if err == nil {
 		t.Error("ComputeFile() expected error for nonexistent file")
 	}
@@ -316,6 +473,12 @@ func TestComputeBytes_ConsistentLength(t *testing.T) {
 		{AlgorithmSHA1, 40},
 		{AlgorithmSHA512, 128},
 		{AlgorithmBLAKE2b, 128},
+		{AlgorithmSHA3_256, 64},
+		{AlgorithmSHA3_512, 128},
+		{AlgorithmBLAKE2s, 64},
+		{AlgorithmBLAKE3, 64},
+		{AlgorithmXXH64, 16},
+		{AlgorithmCRC32C, 8},
 	}
 
 	for _, alg := range algorithms {
@@ -357,7 +520,7 @@ func verifyHexValidationProperty(s string) bool {
 			}
 		}
 		// Also check that length matches expected algorithm lengths
-		validLengths := []int{32, 40, 64, 128}
+		validLengths := []int{8, 16, 32, 40, 64, 128}
 		validLength := false
 		for _, length := range validLengths {
 			if len(s) == length {
@@ -383,7 +546,7 @@ func verifyHexValidationProperty(s string) bool {
 
 		// If it has valid hex but no algorithms, it must be wrong length
 		if !hasInvalidHex {
-			validLengths := []int{32, 40, 64, 128}
+			validLengths := []int{8, 16, 32, 40, 64, 128}
 			for _, length := range validLengths {
 				if len(s) == length {
 					return false // Valid hex and valid length but no algorithms detected
@@ -404,8 +567,10 @@ func TestDetectHashAlgorithm_AlgorithmIdentification(t *testing.T) {
 		validLengths := map[int][]string{
 			32:  {AlgorithmMD5},
 			40:  {AlgorithmSHA1},
-			64:  {AlgorithmSHA256},
-			128: {AlgorithmSHA512, AlgorithmBLAKE2b},
+			8:   {AlgorithmCRC32C},
+			16:  {AlgorithmXXH64},
+			64:  {AlgorithmSHA256, AlgorithmSHA3_256, AlgorithmBLAKE2s, AlgorithmBLAKE3},
+			128: {AlgorithmSHA512, AlgorithmBLAKE2b, AlgorithmSHA3_512},
 		}
 
 		expectedAlgorithms, isValidLength := validLengths[length]
@@ -421,7 +586,7 @@ func TestDetectHashAlgorithm_AlgorithmIdentification(t *testing.T) {
 	// Custom generator for valid lengths and hex characters
 	config := &quick.Config{
 		Values: func(values []reflect.Value, rand *rand.Rand) {
-			validLengths := []int{32, 40, 64, 128}
+			validLengths := []int{8, 16, 32, 40, 64, 128}
 			length := validLengths[rand.Intn(len(validLengths))]
 			values[0] = reflect.ValueOf(length)
 
@@ -484,13 +649,13 @@ var detectHashAlgorithmTests = []struct {
 	// Valid hex strings of each length
 	{"valid md5", "d41d8cd98f00b204e9800998ecf8427e", []string{AlgorithmMD5}},
 	{"valid sha1", "da39a3ee5e6b4b0d3255bfef95601890afd80709", []string{AlgorithmSHA1}},
-	{"valid sha256", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", []string{AlgorithmSHA256}},
-	{"valid sha512", "cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e", []string{AlgorithmSHA512, AlgorithmBLAKE2b}},
-	{"valid blake2b (same length as sha512)", "786a02f742015903c6c6fd852552d272912f4740e15847618a86e217f71f5419d25e1031afee585313896444934eb04b903a685b1448b755d56f701afe9be2ce", []string{AlgorithmSHA512, AlgorithmBLAKE2b}},
+	{"valid sha256", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", []string{AlgorithmSHA256, AlgorithmSHA3_256, AlgorithmBLAKE2s, AlgorithmBLAKE3}},
+	{"valid sha512", "cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e", []string{AlgorithmSHA512, AlgorithmBLAKE2b, AlgorithmSHA3_512}},
+	{"valid blake2b (same length as sha512)", "786a02f742015903c6c6fd852552d272912f4740e15847618a86e217f71f5419d25e1031afee585313896444934eb04b903a685b1448b755d56f701afe9be2ce", []string{AlgorithmSHA512, AlgorithmBLAKE2b, AlgorithmSHA3_512}},
 
 	// Uppercase should work
 	{"uppercase md5", "D41D8CD98F00B204E9800998ECF8427E", []string{AlgorithmMD5}},
-	{"uppercase sha256", "E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855", []string{AlgorithmSHA256}},
+	{"uppercase sha256", "E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855", []string{AlgorithmSHA256, AlgorithmSHA3_256, AlgorithmBLAKE2s, AlgorithmBLAKE3}},
 
 	// Mixed case should work
 	{"mixed case sha1", "Da39A3ee5E6b4B0d3255BfeF95601890aFd80709", []string{AlgorithmSHA1}},
@@ -503,15 +668,17 @@ var detectHashAlgorithmTests = []struct {
 	// Wrong lengths
 	{"too short", "abc123", []string{}},
 	{"too long", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855aa", []string{}},
-	{"wrong length 16", "1234567890abcdef", []string{}},
+	{"xxh64 length", "1234567890abcdef", []string{AlgorithmXXH64}},
+	{"crc32c length", "e3069283", []string{AlgorithmCRC32C}},
+	{"wrong length 12", "1234567890ab", []string{}},
 	{"wrong length 48", "123456789012345678901234567890123456789012345678", []string{}},
 
 	// Edge cases
 	{"empty string", "", []string{}},
 	{"single char", "a", []string{}},
 	{"all zeros md5", "00000000000000000000000000000000", []string{AlgorithmMD5}},
-	{"all zeros sha256", "0000000000000000000000000000000000000000000000000000000000000000", []string{AlgorithmSHA256}},
-	{"all f's sha512", "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", []string{AlgorithmSHA512, AlgorithmBLAKE2b}},
+	{"all zeros sha256", "0000000000000000000000000000000000000000000000000000000000000000", []string{AlgorithmSHA256, AlgorithmSHA3_256, AlgorithmBLAKE2s, AlgorithmBLAKE3}},
+	{"all f's sha512", "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", []string{AlgorithmSHA512, AlgorithmBLAKE2b, AlgorithmSHA3_512}},
 }
 
 // TestDetectHashAlgorithm tests hash algorithm detection with specific cases.
@@ -0,0 +1,223 @@
+package hash
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Ignore files honoured by discovery. ChexumIgnoreFile is read when
+// DiscoveryOptions.IgnoreFiles is set, the git files when GitIgnore is.
+const (
+	ChexumIgnoreFile = ".chexumignore"
+	GitIgnoreFile    = ".gitignore"
+	GitExcludeFile   = ".git/info/exclude"
+)
+
+// ignoreRule is one pattern line of an ignore file, in gitignore syntax.
+type ignoreRule struct {
+	segments []string // Pattern split on "/"; "**" matches any number of directories
+	negate   bool     // "!pattern" re-includes what an earlier rule ignored
+	dirOnly  bool     // "pattern/" matches directories only
+}
+
+// ignoreList holds the rules of one ignore file. Lists are chained from the
+// deepest directory up, so rules closer to a path take precedence, just as
+// git gives a .gitignore precedence over those in its parent directories.
+type ignoreList struct {
+	parent *ignoreList
+	rules  []ignoreRule
+
+	// The rules match paths relative to the directory holding the file.
+	// Walked paths are relative to the walk root, so a list records where
+	// its directory lies: base below the root, or for ignore files above
+	// the root, prefix, the root's path relative to their directory.
+	base   string
+	prefix string
+}
+
+// parseIgnore reads gitignore-syntax patterns: blank lines and lines
+// starting with "#" are skipped, a leading "!" negates, a trailing "/"
+// matches directories only, and a pattern containing any other "/" is
+// anchored to the ignore file's directory rather than matched at any depth.
+func parseIgnore(data []byte) []ignoreRule {
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := trimIgnoreSpace(strings.TrimSuffix(scanner.Text(), "\r"))
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		var rule ignoreRule
+		switch {
+		case line[0] == '!':
+			rule.negate, line = true, line[1:]
+		case strings.HasPrefix(line, `\!`), strings.HasPrefix(line, `\#`):
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly, line = true, strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		anchored := strings.Contains(line, "/")
+		rule.segments = strings.Split(strings.TrimPrefix(line, "/"), "/")
+		if !anchored {
+			rule.segments = append([]string{"**"}, rule.segments...)
+		}
+		if validIgnorePattern(rule.segments) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// trimIgnoreSpace drops trailing spaces unless they are escaped.
+func trimIgnoreSpace(line string) string {
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	return line
+}
+
+// validIgnorePattern reports whether every segment is a well-formed glob;
+// git silently ignores patterns it cannot parse, and so does chexum.
+func validIgnorePattern(segments []string) bool {
+	for _, s := range segments {
+		if _, err := path.Match(s, ""); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// ignored reports whether rel, a slash-separated path relative to the walk
+// root, is ignored. The last matching rule of the deepest file decides.
+func (l *ignoreList) ignored(rel string, isDir bool) bool {
+	for ; l != nil; l = l.parent {
+		p := rel
+		switch {
+		case l.prefix != "":
+			p = l.prefix + "/" + rel
+		case l.base != "":
+			var ok bool
+			if p, ok = strings.CutPrefix(rel, l.base+"/"); !ok {
+				continue
+			}
+		}
+		segments := strings.Split(p, "/")
+		for i := len(l.rules) - 1; i >= 0; i-- {
+			rule := l.rules[i]
+			if rule.dirOnly && !isDir {
+				continue
+			}
+			if matchSegments(rule.segments, segments) {
+				return !rule.negate
+			}
+		}
+	}
+	return false
+}
+
+// push adds the rules of the ignore file at file, if it exists, on top of
+// l. A file that cannot be read is reported to record and skipped.
+func (l *ignoreList) push(file, base, prefix string, record func(string, error)) *ignoreList {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			record(file, err)
+		}
+		return l
+	}
+	rules := parseIgnore(data)
+	if len(rules) == 0 {
+		return l
+	}
+	return &ignoreList{parent: l, rules: rules, base: base, prefix: prefix}
+}
+
+// loadIgnores adds the ignore files among the entries of dir, a directory
+// at rel below the walk root, on top of parent. Within one directory
+// .git/info/exclude ranks lowest and .chexumignore highest.
+func loadIgnores(dir, rel string, entries []fs.DirEntry, parent *ignoreList, opts DiscoveryOptions, record func(string, error)) *ignoreList {
+	if rel == "." {
+		rel = ""
+	}
+	var hasGit, hasGitIgnore, hasChexumIgnore bool
+	for _, entry := range entries {
+		switch entry.Name() {
+		case ".git":
+			hasGit = entry.IsDir()
+		case GitIgnoreFile:
+			hasGitIgnore = true
+		case ChexumIgnoreFile:
+			hasChexumIgnore = true
+		}
+	}
+	ignore := parent
+	if opts.GitIgnore && hasGit {
+		ignore = ignore.push(filepath.Join(dir, filepath.FromSlash(GitExcludeFile)), rel, "", record)
+	}
+	if opts.GitIgnore && hasGitIgnore {
+		ignore = ignore.push(filepath.Join(dir, GitIgnoreFile), rel, "", record)
+	}
+	if opts.IgnoreFiles && hasChexumIgnore {
+		ignore = ignore.push(filepath.Join(dir, ChexumIgnoreFile), rel, "", record)
+	}
+	return ignore
+}
+
+// rootIgnores returns the rules that apply to root from the directories
+// above it. As in git, when root lies inside a repository, the ignore files
+// from the repository's top level down to root's parent apply; outside a
+// repository only files inside root count. .chexumignore files are found
+// the same way whether or not the git files are read, so hashing a
+// subdirectory honours the same rules as hashing the whole repository.
+func rootIgnores(root string, opts DiscoveryOptions, record func(string, error)) *ignoreList {
+	if !opts.GitIgnore && !opts.IgnoreFiles {
+		return nil
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil
+	}
+	top := ""
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		if info, err := os.Stat(filepath.Join(dir, ".git")); err == nil && info.IsDir() {
+			top = dir
+			break
+		}
+		if dir == filepath.Dir(dir) {
+			break
+		}
+	}
+	if top == "" || top == abs {
+		return nil // Root's own ignore files are read as it is walked
+	}
+
+	prefix := func(dir string) string {
+		rel, _ := filepath.Rel(dir, abs)
+		return filepath.ToSlash(rel)
+	}
+	var ignore *ignoreList
+	if opts.GitIgnore {
+		ignore = ignore.push(filepath.Join(top, filepath.FromSlash(GitExcludeFile)), "", prefix(top), record)
+	}
+	for dir := top; dir != abs; {
+		if opts.GitIgnore {
+			ignore = ignore.push(filepath.Join(dir, GitIgnoreFile), "", prefix(dir), record)
+		}
+		if opts.IgnoreFiles {
+			ignore = ignore.push(filepath.Join(dir, ChexumIgnoreFile), "", prefix(dir), record)
+		}
+		next, _, _ := strings.Cut(prefix(dir), "/")
+		dir = filepath.Join(dir, next)
+	}
+	return ignore
+}
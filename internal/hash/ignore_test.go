@@ -0,0 +1,142 @@
+package hash
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIgnoreList(t *testing.T) {
+	rules := parseIgnore([]byte(strings.Join([]string{
+		"# build output",
+		"*.o",
+		"build/",
+		"/TODO",
+		"docs/*.pdf",
+		"cache/**",
+		"**/tmp",
+		"*.bin",
+		"!keep.bin",
+		`\#notes`,
+		"trailing   ",
+		"[bad",
+		"",
+	}, "\n")))
+	if len(rules) != 10 {
+		t.Fatalf("parseIgnore() kept %d rules, want 10 (comments, blanks and bad patterns dropped)", len(rules))
+	}
+	list := &ignoreList{rules: rules}
+
+	tests := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{"main.o", false, true},
+		{"src/deep/main.o", false, true},
+		{"build", true, true},
+		{"src/build", true, true},
+		{"build", false, false}, // Directory-only pattern
+		{"TODO", false, true},
+		{"src/TODO", false, false}, // Anchored to the ignore file's directory
+		{"docs/manual.pdf", false, true},
+		{"docs/sub/manual.pdf", false, false},
+		{"cache", true, false}, // Trailing ** matches the contents only
+		{"cache/a/b", false, true},
+		{"a/b/tmp", true, true},
+		{"data.bin", false, true},
+		{"keep.bin", false, false},
+		{"sub/keep.bin", false, false},
+		{"#notes", false, true},
+		{"trailing", false, true},
+		{"main.go", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rel, func(t *testing.T) {
+			if got := list.ignored(tt.rel, tt.isDir); got != tt.want {
+				t.Errorf("ignored(%q, %v) = %v, want %v", tt.rel, tt.isDir, got, tt.want)
+			}
+		})
+	}
+
+	// A deeper ignore file overrides its parents.
+	child := &ignoreList{parent: list, rules: parseIgnore([]byte("!special.o\n")), base: "src"}
+	if child.ignored("src/special.o", false) {
+		t.Error("deeper negation should re-include src/special.o")
+	}
+	if !child.ignored("special.o", false) {
+		t.Error("deeper ignore file must not apply outside its directory")
+	}
+}
+
+func TestDiscover_IgnoreFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel, data string) {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(data), 0644)
+	}
+	write(".git/info/exclude", "secret.txt\n")
+	write(".gitignore", "*.log\nbuild/\n/local.txt\n")
+	write(".chexumignore", "*.iso\n")
+	write("app.go", "")
+	write("debug.log", "")
+	write("local.txt", "")
+	write("secret.txt", "")
+	write("image.iso", "")
+	write("build/out.bin", "")
+	write("src/local.txt", "")
+	write("src/.gitignore", "!important.log\n")
+	write("src/important.log", "")
+	write("src/other.log", "")
+	write("src/disk.iso", "")
+
+	discover := func(root string, opts DiscoveryOptions) []string {
+		t.Helper()
+		opts.Recursive, opts.MaxSize = true, -1
+		files, err := DiscoverFiles(context.Background(), []string{root}, opts)
+		if err != nil {
+			t.Fatalf("DiscoverFiles() error = %v", err)
+		}
+		for i, f := range files {
+			rel, _ := filepath.Rel(root, f)
+			files[i] = filepath.ToSlash(rel)
+		}
+		return files
+	}
+
+	tests := []struct {
+		name string
+		root string
+		opts DiscoveryOptions
+		want []string
+	}{
+		{"none", "", DiscoveryOptions{}, []string{
+			"app.go", "build/out.bin", "debug.log", "image.iso", "local.txt", "secret.txt",
+			"src/disk.iso", "src/important.log", "src/local.txt", "src/other.log",
+		}},
+		{"chexumignore", "", DiscoveryOptions{IgnoreFiles: true}, []string{
+			"app.go", "build/out.bin", "debug.log", "local.txt", "secret.txt",
+			"src/important.log", "src/local.txt", "src/other.log",
+		}},
+		{"gitignore", "", DiscoveryOptions{IgnoreFiles: true, GitIgnore: true}, []string{
+			"app.go", "src/important.log", "src/local.txt",
+		}},
+		{"inside repository", "src", DiscoveryOptions{IgnoreFiles: true, GitIgnore: true}, []string{
+			"important.log", "local.txt",
+		}},
+		{"inside repository without git files", "src", DiscoveryOptions{IgnoreFiles: true}, []string{
+			"important.log", "local.txt", "other.log",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := discover(filepath.Join(dir, tt.root), tt.opts)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package hash
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SniffSize is how many leading bytes of a file SniffType looks at.
+const SniffSize = 512
+
+// binaryType is the pattern "binary" resolves to. It matches every type
+// that is not text, which no single MIME pattern can express.
+const binaryType = "binary"
+
+// typeAliases are the short names --type accepts besides MIME patterns.
+var typeAliases = map[string]string{
+	"audio":  "audio/*",
+	"binary": binaryType,
+	"elf":    "application/x-elf",
+	"exe":    "application/vnd.microsoft.portable-executable",
+	"gif":    "image/gif",
+	"gzip":   "application/x-gzip",
+	"html":   "text/html",
+	"image":  "image/*",
+	"jpeg":   "image/jpeg",
+	"jpg":    "image/jpeg",
+	"json":   "application/json",
+	"macho":  "application/x-mach-binary",
+	"pdf":    "application/pdf",
+	"png":    "image/png",
+	"text":   "text/*",
+	"video":  "video/*",
+	"wasm":   "application/wasm",
+	"webp":   "image/webp",
+	"xml":    "text/xml",
+	"zip":    "application/zip",
+}
+
+// TypeAliases returns the short names ResolveType accepts, sorted.
+func TypeAliases() []string {
+	names := make([]string, 0, len(typeAliases))
+	for name := range typeAliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveType turns a --type argument into the MIME pattern SniffType
+// results are matched against: a short name such as "elf" or "image", or a
+// pattern such as "image/*" or "text/plain".
+func ResolveType(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if pattern, ok := typeAliases[name]; ok {
+		return pattern, nil
+	}
+	if !strings.Contains(name, "/") {
+		return "", fmt.Errorf("unknown type %q: use a MIME type such as image/* or one of %s", name, strings.Join(TypeAliases(), ", "))
+	}
+	if _, err := path.Match(name, ""); err != nil {
+		return "", fmt.Errorf("invalid type pattern %q: %w", name, err)
+	}
+	return name, nil
+}
+
+// SniffType returns the MIME type of content starting with head, without
+// parameters. It recognises executables by their magic numbers and JSON by
+// its syntax, and leaves everything else to http.DetectContentType, which
+// implements the WHATWG sniffing algorithm over the first SniffSize bytes.
+func SniffType(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte("\x7fELF")):
+		return "application/x-elf"
+	case isPE(head):
+		return "application/vnd.microsoft.portable-executable"
+	case bytes.HasPrefix(head, []byte{0xcf, 0xfa, 0xed, 0xfe}), bytes.HasPrefix(head, []byte{0xce, 0xfa, 0xed, 0xfe}),
+		bytes.HasPrefix(head, []byte{0xfe, 0xed, 0xfa, 0xcf}), bytes.HasPrefix(head, []byte{0xfe, 0xed, 0xfa, 0xce}):
+		return "application/x-mach-binary"
+	}
+	detected := http.DetectContentType(head)
+	media, _, err := mime.ParseMediaType(detected)
+	if err != nil {
+		return detected
+	}
+	if media == "text/plain" && isJSON(head) {
+		return "application/json"
+	}
+	return media
+}
+
+// isPE reports whether head starts a Windows executable: a DOS header
+// whose e_lfanew field, at offset 0x3C, points at the "PE\0\0" signature.
+// A signature beyond head cannot be confirmed, so the file is not taken
+// for one; plenty of text starts with "MZ".
+func isPE(head []byte) bool {
+	if !bytes.HasPrefix(head, []byte("MZ")) || len(head) < 0x40 {
+		return false
+	}
+	offset := int64(binary.LittleEndian.Uint32(head[0x3C:]))
+	return offset+4 <= int64(len(head)) && bytes.Equal(head[offset:offset+4], []byte("PE\x00\x00"))
+}
+
+// isJSON reports whether head is a JSON object or array. A head of
+// SniffSize bytes may cut the document short, so it need only be a valid
+// start of one.
+func isJSON(head []byte) bool {
+	trimmed := bytes.TrimLeft(head, " \t\r\n")
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return false
+	}
+	if len(head) < SniffSize {
+		return json.Valid(trimmed)
+	}
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	for {
+		_, err := dec.Token()
+		if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+			return true
+		}
+		if err != nil {
+			return false
+		}
+	}
+}
+
+// typeMatches reports whether the sniffed type matches pattern, a result
+// of ResolveType.
+func typeMatches(pattern, sniffed string) bool {
+	if pattern == binaryType {
+		return !strings.HasPrefix(sniffed, "text/") && sniffed != "application/json"
+	}
+	ok, _ := path.Match(pattern, sniffed)
+	return ok
+}
+
+// matchesType reports whether the file at name has a content type matching
+// one of the MIME patterns.
+func matchesType(name string, patterns []string) (bool, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+	head := make([]byte, SniffSize)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, err
+	}
+	sniffed := SniffType(head[:n])
+	for _, pattern := range patterns {
+		if typeMatches(pattern, sniffed) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// PermFilter selects files by permission bits, as find's -perm does.
+type PermFilter struct {
+	Bits  fs.FileMode // Permission bits, with fs.ModeSetuid, ModeSetgid and ModeSticky
+	Match byte        // 0: no filter; '=': exactly Bits; '-': all of Bits; '/': any of Bits
+}
+
+// permMask covers the bits a PermFilter compares.
+const permMask = fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky
+
+// matches reports whether mode passes the filter.
+func (p PermFilter) matches(mode fs.FileMode) bool {
+	mode &= permMask
+	switch p.Match {
+	case '=':
+		return mode == p.Bits
+	case '-':
+		return mode&p.Bits == p.Bits
+	case '/':
+		return p.Bits == 0 || mode&p.Bits != 0
+	}
+	return true
+}
+
+// statAttr holds the metadata beyond os.FileInfo that predicates need.
+type statAttr struct {
+	UID, GID   uint32
+	AccessTime time.Time
+	ChangeTime time.Time
+}
+
+// needsStatAttr reports whether any predicate reads statAttr.
+func (opts DiscoveryOptions) needsStatAttr() bool {
+	return len(opts.Owners) > 0 || len(opts.Groups) > 0 ||
+		!opts.ChangedAfter.IsZero() || !opts.ChangedBefore.IsZero() ||
+		!opts.AccessedAfter.IsZero() || !opts.AccessedBefore.IsZero()
+}
+
+// passesStatAttr applies the owner, group, ctime and atime predicates. On
+// platforms without them (see statAttrs) no file passes, rather than every
+// file passing unfiltered.
+func passesStatAttr(info os.FileInfo, opts DiscoveryOptions) bool {
+	if !opts.needsStatAttr() {
+		return true
+	}
+	attr, ok := statAttrs(info)
+	if !ok {
+		return false
+	}
+	if len(opts.Owners) > 0 && !slices.Contains(opts.Owners, attr.UID) {
+		return false
+	}
+	if len(opts.Groups) > 0 && !slices.Contains(opts.Groups, attr.GID) {
+		return false
+	}
+	return inWindow(attr.ChangeTime, opts.ChangedAfter, opts.ChangedBefore) &&
+		inWindow(attr.AccessTime, opts.AccessedAfter, opts.AccessedBefore)
+}
+
+// inWindow reports whether t lies between after and before; a zero bound
+// is open.
+func inWindow(t, after, before time.Time) bool {
+	if !after.IsZero() && t.Before(after) {
+		return false
+	}
+	if !before.IsZero() && t.After(before) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,162 @@
+package hash
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSniffType(t *testing.T) {
+	pe := make([]byte, 0x44)
+	copy(pe, "MZ")
+	pe[0x3C] = 0x40
+	copy(pe[0x40:], "PE\x00\x00")
+	farPE := slices.Clone(pe)
+	farPE[0x3C] = 0xff
+	longJSON := `{"items": [` + strings.Repeat(`"entry", `, SniffSize/9) + `"last"]}`
+
+	tests := []struct {
+		name string
+		head []byte
+		want string
+	}{
+		{"elf", []byte("\x7fELF\x02\x01\x01"), "application/x-elf"},
+		{"pe", pe, "application/vnd.microsoft.portable-executable"},
+		{"pe header beyond head", farPE, "application/octet-stream"},
+		{"text starting MZ", []byte("MZ is a postcode prefix\n"), "text/plain"},
+		{"mach-o", []byte{0xcf, 0xfa, 0xed, 0xfe, 7, 0, 0, 1}, "application/x-mach-binary"},
+		{"png", []byte("\x89PNG\r\n\x1a\n"), "image/png"},
+		{"pdf", []byte("%PDF-1.7"), "application/pdf"},
+		{"text drops charset", []byte("hello world\n"), "text/plain"},
+		{"json object", []byte(" {\"a\": 1}\n"), "application/json"},
+		{"json array", []byte("[1, 2]"), "application/json"},
+		{"json cut short", []byte(longJSON[:SniffSize]), "application/json"},
+		{"brace text", []byte("{not json}"), "text/plain"},
+		{"empty", nil, "text/plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SniffType(tt.head); got != tt.want {
+				t.Errorf("SniffType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveType(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"elf", "application/x-elf", false},
+		{"binary", binaryType, false},
+		{"IMAGE", "image/*", false},
+		{"image/*", "image/*", false},
+		{"text/plain", "text/plain", false},
+		{"spreadsheet", "", true},
+		{"image/[", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveType(tt.name)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ResolveType(%q) = %q, %v, want %q (error %v)", tt.name, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPermFilter(t *testing.T) {
+	tests := []struct {
+		filter PermFilter
+		mode   fs.FileMode
+		want   bool
+	}{
+		{PermFilter{}, 0600, true},
+		{PermFilter{Bits: 0644, Match: '='}, 0644, true},
+		{PermFilter{Bits: 0644, Match: '='}, 0755, false},
+		{PermFilter{Bits: 0111, Match: '-'}, 0755, true},
+		{PermFilter{Bits: 0111, Match: '-'}, 0744, false},
+		{PermFilter{Bits: 0022, Match: '/'}, 0664, true},
+		{PermFilter{Bits: 0022, Match: '/'}, 0644, false},
+		{PermFilter{Bits: fs.ModeSetuid, Match: '-'}, fs.ModeSetuid | 0755, true},
+		{PermFilter{Bits: 0755, Match: '='}, fs.ModeSetuid | 0755, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.matches(tt.mode); got != tt.want {
+			t.Errorf("%+v.matches(%v) = %v, want %v", tt.filter, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestDiscover_Predicates(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel string, data []byte, perm fs.FileMode) {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, data, 0644)
+		os.Chmod(path, perm)
+	}
+	write("bin/tool", []byte("\x7fELF\x02\x01\x01\x00"), 0755)
+	write("img/logo.png", []byte("\x89PNG\r\n\x1a\n0000"), 0644)
+	write("docs/readme.txt", []byte("plain words"), 0600)
+	write("docs/v2/notes.txt", []byte("more words"), 0644)
+	write("data/config.json", []byte("{\"name\": \"chexum\", \"tags\": [\"a\", \"b\"]}\n"), 0644)
+
+	attr, ok := statAttrs(mustStat(t, filepath.Join(dir, "bin/tool")))
+	tests := []struct {
+		name string
+		opts DiscoveryOptions
+		want []string
+		unix bool
+	}{
+		{"regex", DiscoveryOptions{PathRegex: []*regexp.Regexp{regexp.MustCompile(`^docs/v\d+/`)}}, []string{"docs/v2/notes.txt"}, false},
+		{"type alias", DiscoveryOptions{Types: []string{"application/x-elf"}}, []string{"bin/tool"}, false},
+		{"json", DiscoveryOptions{Types: []string{"application/json"}}, []string{"data/config.json"}, false},
+		{"binary", DiscoveryOptions{Types: []string{binaryType}}, []string{"bin/tool", "img/logo.png"}, false},
+		{"type pattern", DiscoveryOptions{Types: []string{"image/*", "text/*"}}, []string{"docs/readme.txt", "docs/v2/notes.txt", "img/logo.png"}, false},
+		{"executable", DiscoveryOptions{Perm: PermFilter{Bits: 0111, Match: '/'}}, []string{"bin/tool"}, false},
+		{"exact perm", DiscoveryOptions{Perm: PermFilter{Bits: 0600, Match: '='}}, []string{"docs/readme.txt"}, false},
+		{"owner", DiscoveryOptions{Owners: []uint32{attr.UID}, Groups: []uint32{attr.GID}}, []string{"bin/tool", "data/config.json", "docs/readme.txt", "docs/v2/notes.txt", "img/logo.png"}, true},
+		{"other owner", DiscoveryOptions{Owners: []uint32{attr.UID + 1}}, nil, true},
+		{"changed recently", DiscoveryOptions{ChangedAfter: time.Now().Add(-time.Hour)}, []string{"bin/tool", "data/config.json", "docs/readme.txt", "docs/v2/notes.txt", "img/logo.png"}, true},
+		{"changed long ago", DiscoveryOptions{ChangedBefore: time.Now().Add(-time.Hour)}, nil, true},
+		{"accessed in future", DiscoveryOptions{AccessedAfter: time.Now().Add(time.Hour)}, nil, true},
+		{"combined", DiscoveryOptions{Types: []string{"text/*"}, Perm: PermFilter{Bits: 0044, Match: '-'}}, []string{"docs/v2/notes.txt"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.unix && !ok {
+				t.Skip("owners and change times are not available on this platform")
+			}
+			tt.opts.Recursive, tt.opts.MaxSize = true, -1
+			files, err := DiscoverFiles(context.Background(), []string{dir}, tt.opts)
+			if err != nil {
+				t.Fatalf("DiscoverFiles() error = %v", err)
+			}
+			for i, f := range files {
+				rel, _ := filepath.Rel(dir, f)
+				files[i] = filepath.ToSlash(rel)
+			}
+			if strings.Join(files, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v, want %v", files, tt.want)
+			}
+		})
+	}
+}
+
+func mustStat(t *testing.T, path string) os.FileInfo {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info
+}
@@ -1,6 +1,7 @@
 package hash
 
 import (
+	"context"
 	"math/rand"
 	"testing"
 	"testing/quick"
@@ -130,7 +131,7 @@ func TestProperty_ComputeBatch_LargeLists(t *testing.T) {
 
 		computer, _ := NewComputer(AlgorithmSHA256)
 		// Use auto workers (0)
-		resultChan := computer.ComputeBatch(files, 0)
+		resultChan := computer.ComputeBatch(context.Background(), files, 0)
 
 		count := 0
 		for range resultChan {
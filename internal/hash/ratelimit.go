@@ -0,0 +1,112 @@
+package hash
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// minRateBurst is the smallest burst a rate limiter allows, so a low limit
+// still lets whole read buffers through instead of stalling on every read.
+const minRateBurst = 256 * 1024
+
+// rateLimiter is a token bucket shared by every worker of a Computer. Tokens
+// are bytes; a reader pays for what it has just read and, when the bucket is
+// overdrawn, sleeps until its share of the debt has been earned back.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // Bytes per second
+	burst  float64 // Most tokens the bucket holds
+	tokens float64 // May go negative while readers wait
+	last   time.Time
+}
+
+// newRateLimiter returns a limiter for bytesPerSecond with a burst of a
+// tenth of a second's worth of data.
+func newRateLimiter(bytesPerSecond int64) *rateLimiter {
+	rate := float64(bytesPerSecond)
+	burst := rate / 10
+	if burst < minRateBurst {
+		burst = minRateBurst
+	}
+	return &rateLimiter{rate: rate, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// wait takes n tokens and blocks until the bucket is no longer in debt for
+// them, or ctx is cancelled.
+func (l *rateLimiter) wait(ctx context.Context, n int) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SetMaxRate caps the bytes per second ComputeFile reads from disk, summed
+// over every worker of every ComputeBatch using this computer. Archives
+// read for their members and partial reads of FindDuplicates count too.
+// Zero or less removes the cap. Digests served from the cache cost nothing.
+func (c *Computer) SetMaxRate(bytesPerSecond int64) {
+	if bytesPerSecond <= 0 {
+		c.limiter = nil
+		return
+	}
+	c.limiter = newRateLimiter(bytesPerSecond)
+}
+
+// limitedReader charges every read to a rate limiter.
+type limitedReader struct {
+	ctx     context.Context
+	r       io.Reader
+	limiter *rateLimiter
+}
+
+// Read implements io.Reader.
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	n, err := lr.r.Read(p)
+	if n > 0 {
+		if werr := lr.limiter.wait(lr.ctx, n); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+// limitedReaderAt charges every read of sampled blocks to a rate limiter.
+type limitedReaderAt struct {
+	ctx     context.Context
+	r       io.ReaderAt
+	limiter *rateLimiter
+}
+
+// ReadAt implements io.ReaderAt.
+func (lr *limitedReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := lr.r.ReadAt(p, off)
+	if n > 0 {
+		if werr := lr.limiter.wait(lr.ctx, n); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
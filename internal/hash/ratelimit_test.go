@@ -0,0 +1,82 @@
+package hash
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSetMaxRate(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+	for _, name := range []string{"a", "b"} {
+		p := filepath.Join(dir, name)
+		os.WriteFile(p, make([]byte, 512*1024), 0644)
+		files = append(files, p)
+	}
+
+	// 1 MiB over two workers at 2 MiB/s, less the initial burst, takes
+	// about 375ms however the workers share the bucket.
+	c, _ := NewComputer(AlgorithmSHA256)
+	c.SetMaxRate(2 * 1024 * 1024)
+	start := time.Now()
+	for entry := range c.ComputeBatch(context.Background(), files, 2) {
+		if entry.Error != nil {
+			t.Fatalf("ComputeBatch() error = %v", entry.Error)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Errorf("1 MiB at 2 MiB/s took %v", elapsed)
+	}
+
+	c.SetMaxRate(0)
+	if c.limiter != nil {
+		t.Error("SetMaxRate(0) should remove the cap")
+	}
+}
+
+func TestSetMaxRate_Archives(t *testing.T) {
+	dir := t.TempDir()
+	data := make([]byte, 1024*1024)
+	rand.New(rand.NewSource(1)).Read(data) // Incompressible, so the archive is 1 MiB too
+	for _, name := range []string{"a.tar.gz", "a.zip"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if name == "a.zip" {
+				writeZip(t, path, []archiveMember{{"data", data}})
+			} else {
+				writeTarGz(t, path, []archiveMember{{"data", data}})
+			}
+			c, _ := NewComputer(AlgorithmSHA256)
+			c.SetMaxRate(2 * 1024 * 1024)
+			start := time.Now()
+			if entries := collectArchive(t, c, path, ArchiveLimits{}); len(entries) != 1 || entries[0].Error != nil {
+				t.Fatalf("ComputeArchive() = %+v", entries)
+			}
+			if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+				t.Errorf("a 1 MiB archive at 2 MiB/s took %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestRateLimiter_Cancel(t *testing.T) {
+	l := newRateLimiter(1024)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	start := time.Now()
+	// Far more than the burst: without cancellation this waits minutes.
+	if err := l.wait(ctx, 100*1024*1024); !errors.Is(err, context.Canceled) {
+		t.Errorf("wait() error = %v, want context.Canceled", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("wait() ignored cancellation")
+	}
+}
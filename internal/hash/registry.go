@@ -0,0 +1,230 @@
+package hash
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
+	"hash"
+	"hash/crc32"
+
+	"github.com/cespare/xxhash/v2"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/blake2s"
+	"lukechampine.com/blake3"
+)
+
+// AlgorithmSpec describes a single supported hash algorithm.
+type AlgorithmSpec struct {
+	Name          string           // Canonical name used in flags, output and manifests
+	Aliases       []string         // Alternative spellings accepted on input
+	Size          int              // Digest length in bytes
+	Cryptographic bool             // False for checksums that offer no collision resistance
+	New           func() hash.Hash // Constructor for a fresh hasher (nil when Keyed)
+
+	// Keyed algorithms produce tags that cannot be computed without a secret
+	// key, so outsiders can neither forge them nor confirm guesses about
+	// low-entropy content. They are never guessed from a digest's length.
+	Keyed      bool
+	NewKeyed   func(key []byte) hash.Hash // Constructor for a fresh keyed hasher
+	MaxKeySize int                        // Longest accepted key in bytes (0 = unlimited)
+
+	// Names used by self-describing encodings (see encoding.go); empty or
+	// zero when the standard does not cover the algorithm.
+	SRIName       string // Subresource Integrity prefix, e.g. "sha256"
+	MultihashCode uint64 // Multicodec code, e.g. 0x12 for sha2-256
+
+	// Sampled algorithms fingerprint a file from its size and SampleBlocks
+	// blocks of SampleBlockSize bytes, hashed with New (see sampled.go).
+	// They need random access to a file and are never guessed from a
+	// digest's length.
+	Sampled         bool
+	SampleBlocks    int
+	SampleBlockSize int64
+}
+
+// HexLength returns the length of the digest when rendered as hex.
+func (s AlgorithmSpec) HexLength() int {
+	return s.Size * 2
+}
+
+// castagnoli is shared by every CRC32C hasher; building the table is not free.
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// builtinAlgorithms is the ordered registry of algorithms.
+//
+// DESIGN PRINCIPLE: One Source of Truth for Algorithms
+// ----------------------------------------------------
+// Every part of chexum that needs to know about an algorithm (constructing
+// hashers, validating --algorithm, guessing an algorithm from a digest length)
+// reads from this registry. Adding an algorithm is therefore a single entry
+// here rather than a hunt through switch statements.
+//
+// Order matters: it is the order used for --help listings and for the
+// candidate list returned by DetectHashAlgorithm on ambiguous lengths.
+var builtinAlgorithms = []AlgorithmSpec{
+	{
+		// We default to SHA-256 as it is the current industry standard
+		// for cryptographic security and compatibility.
+		Name: AlgorithmSHA256, Aliases: []string{"sha-256", "sha2-256"},
+		Size: sha256.Size, Cryptographic: true, New: sha256.New,
+		SRIName: "sha256", MultihashCode: 0x12,
+	},
+	{
+		Name: AlgorithmMD5,
+		Size: md5.Size, Cryptographic: true, New: md5.New,
+		MultihashCode: 0xd5,
+	},
+	{
+		Name: AlgorithmSHA1, Aliases: []string{"sha-1"},
+		Size: sha1.Size, Cryptographic: true, New: sha1.New,
+		MultihashCode: 0x11,
+	},
+	{
+		Name: AlgorithmSHA512, Aliases: []string{"sha-512", "sha2-512"},
+		Size: sha512.Size, Cryptographic: true, New: sha512.New,
+		SRIName: "sha512", MultihashCode: 0x13,
+	},
+	{
+		// BLAKE2b-512 (64 bytes output)
+		// BLAKE2b is often faster than SHA-2 on modern CPUs.
+		Name: AlgorithmBLAKE2b, Aliases: []string{"blake2b-512"},
+		Size: blake2b.Size, Cryptographic: true, MultihashCode: 0xb240,
+		New: func() hash.Hash {
+			h, _ := blake2b.New512(nil)
+			return h
+		},
+	},
+	{
+		Name: AlgorithmSHA3_256, Aliases: []string{"sha3_256"},
+		Size: 32, Cryptographic: true, MultihashCode: 0x16,
+		New: func() hash.Hash { return sha3.New256() },
+	},
+	{
+		Name: AlgorithmSHA3_512, Aliases: []string{"sha3_512"},
+		Size: 64, Cryptographic: true, MultihashCode: 0x14,
+		New: func() hash.Hash { return sha3.New512() },
+	},
+	{
+		Name: AlgorithmBLAKE2s, Aliases: []string{"blake2s"},
+		Size: blake2s.Size, Cryptographic: true, MultihashCode: 0xb260,
+		New: func() hash.Hash {
+			h, _ := blake2s.New256(nil)
+			return h
+		},
+	},
+	{
+		// BLAKE3 with the standard 256-bit output.
+		Name: AlgorithmBLAKE3, Aliases: []string{"blake3-256"},
+		Size: 32, Cryptographic: true, MultihashCode: 0x1e,
+		New: func() hash.Hash { return blake3.New(32, nil) },
+	},
+	{
+		// xxHash64 is a very fast non-cryptographic checksum. The digest is
+		// rendered big-endian, matching the output of the reference xxhsum.
+		Name: AlgorithmXXH64, Aliases: []string{"xxhash64", "xxhash"},
+		Size: 8, Cryptographic: false,
+		New: func() hash.Hash { return xxhash.New() },
+	},
+	{
+		// CRC-32C (Castagnoli), as used by iSCSI, ext4 and cloud object stores.
+		Name: AlgorithmCRC32C, Aliases: []string{"crc-32c", "castagnoli"},
+		Size: crc32.Size, Cryptographic: false,
+		New: func() hash.Hash { return crc32.New(castagnoli) },
+	},
+	{
+		// Sampled fingerprints for quick change triage of huge files.
+		// Not full digests: see sampled.go.
+		Name: AlgorithmSampledSHA256,
+		Size: sha256.Size, Cryptographic: false, New: sha256.New,
+		Sampled: true, SampleBlocks: DefaultSampleBlocks, SampleBlockSize: DefaultSampleBlockSize,
+	},
+	{
+		Name: AlgorithmSampledXXH64,
+		Size: 8, Cryptographic: false,
+		New:     func() hash.Hash { return xxhash.New() },
+		Sampled: true, SampleBlocks: DefaultSampleBlocks, SampleBlockSize: DefaultSampleBlockSize,
+	},
+	{
+		Name: AlgorithmHMACSHA256, Aliases: []string{"hmac-sha-256"},
+		Size: sha256.Size, Cryptographic: true, Keyed: true,
+		NewKeyed: func(key []byte) hash.Hash { return hmac.New(sha256.New, key) },
+	},
+	{
+		Name: AlgorithmHMACSHA512, Aliases: []string{"hmac-sha-512"},
+		Size: sha512.Size, Cryptographic: true, Keyed: true,
+		NewKeyed: func(key []byte) hash.Hash { return hmac.New(sha512.New, key) },
+	},
+	{
+		// BLAKE2b-512 in its native keyed (MAC) mode rather than wrapped in HMAC.
+		Name: AlgorithmBLAKE2bKeyed, Aliases: []string{"keyed-blake2b"},
+		Size: blake2b.Size, Cryptographic: true, Keyed: true, MaxKeySize: blake2b.Size,
+		NewKeyed: func(key []byte) hash.Hash {
+			h, _ := blake2b.New512(key)
+			return h
+		},
+	},
+}
+
+// LookupAlgorithm resolves a name or alias to its spec.
+// Names are case-sensitive, matching the behaviour of --algorithm.
+func LookupAlgorithm(name string) (AlgorithmSpec, bool) {
+	for _, spec := range builtinAlgorithms {
+		if spec.Name == name {
+			return spec, true
+		}
+		for _, alias := range spec.Aliases {
+			if alias == name {
+				return spec, true
+			}
+		}
+	}
+	return AlgorithmSpec{}, false
+}
+
+// CanonicalAlgorithm returns the canonical name for an algorithm or alias.
+// Unknown names are returned unchanged so callers can report them verbatim.
+func CanonicalAlgorithm(name string) string {
+	if spec, ok := LookupAlgorithm(name); ok {
+		return spec.Name
+	}
+	return name
+}
+
+// IsKeyed reports whether name (or alias) is a keyed algorithm.
+func IsKeyed(name string) bool {
+	spec, ok := LookupAlgorithm(name)
+	return ok && spec.Keyed
+}
+
+// Algorithms returns a copy of every registered algorithm, in registry order.
+func Algorithms() []AlgorithmSpec {
+	specs := make([]AlgorithmSpec, len(builtinAlgorithms))
+	copy(specs, builtinAlgorithms)
+	return specs
+}
+
+// AlgorithmNames returns the canonical names of every registered algorithm.
+func AlgorithmNames() []string {
+	names := make([]string, 0, len(builtinAlgorithms))
+	for _, spec := range builtinAlgorithms {
+		names = append(names, spec.Name)
+	}
+	return names
+}
+
+// algorithmsForHexLength returns the names of all unkeyed, unsampled
+// algorithms whose hex digest has the given length. Keyed tags and sampled
+// fingerprints share lengths with full digests and must always be requested
+// explicitly.
+func algorithmsForHexLength(n int) []string {
+	names := []string{}
+	for _, spec := range builtinAlgorithms {
+		if spec.HexLength() == n && !spec.Keyed && !spec.Sampled {
+			names = append(names, spec.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,116 @@
+package hash
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"testing"
+)
+
+// TestLookupAlgorithm tests name and alias resolution against the registry.
+func TestLookupAlgorithm(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		ok    bool
+	}{
+		{"sha256", AlgorithmSHA256, true},
+		{"sha-256", AlgorithmSHA256, true},
+		{"blake2s", AlgorithmBLAKE2s, true},
+		{"xxhash64", AlgorithmXXH64, true},
+		{"castagnoli", AlgorithmCRC32C, true},
+		{"SHA256", "", false},
+		{"whirlpool", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			spec, ok := LookupAlgorithm(tt.input)
+			if ok != tt.ok {
+				t.Fatalf("LookupAlgorithm(%q) ok = %v, want %v", tt.input, ok, tt.ok)
+			}
+			if ok && spec.Name != tt.want {
+				t.Errorf("LookupAlgorithm(%q) = %s, want %s", tt.input, spec.Name, tt.want)
+			}
+		})
+	}
+}
+
+// TestNewComputer_Alias ensures aliases resolve to the canonical name.
+func TestNewComputer_Alias(t *testing.T) {
+	c, err := NewComputer("sha3_256")
+	if err != nil {
+		t.Fatalf("NewComputer() error = %v", err)
+	}
+	if c.Algorithm() != AlgorithmSHA3_256 {
+		t.Errorf("Algorithm() = %s, want %s", c.Algorithm(), AlgorithmSHA3_256)
+	}
+}
+
+// TestRegistry_Consistency verifies every spec's declared size matches its hasher.
+func TestRegistry_Consistency(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, spec := range Algorithms() {
+		names := append([]string{spec.Name}, spec.Aliases...)
+		for _, n := range names {
+			if seen[n] {
+				t.Errorf("duplicate algorithm name or alias %q", n)
+			}
+			seen[n] = true
+		}
+		var h hash.Hash
+		if spec.Keyed {
+			h = spec.NewKeyed([]byte("key"))
+		} else {
+			h = spec.New()
+		}
+		if got := h.Size(); got != spec.Size {
+			t.Errorf("%s: hasher size = %d, registry size = %d", spec.Name, got, spec.Size)
+		}
+	}
+}
+
+// TestKeyedAlgorithms checks keyed algorithms against their reference
+// constructions and that they are only ever used with a key.
+func TestKeyedAlgorithms(t *testing.T) {
+	key := []byte("secret")
+	data := []byte("hello")
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	want := hex.EncodeToString(mac.Sum(nil))
+
+	c, err := NewKeyedComputer(key, AlgorithmHMACSHA256, AlgorithmSHA256)
+	if err != nil {
+		t.Fatalf("NewKeyedComputer() error = %v", err)
+	}
+	if got := c.ComputeBytes(data); got != want {
+		t.Errorf("hmac-sha256 = %s, want %s", got, want)
+	}
+	if !c.Keyed() {
+		t.Error("Keyed() = false for a computer with a keyed algorithm")
+	}
+
+	other, _ := NewKeyedComputer([]byte("other"), AlgorithmBLAKE2bKeyed)
+	same, _ := NewKeyedComputer(key, AlgorithmBLAKE2bKeyed)
+	if other.ComputeBytes(data) == same.ComputeBytes(data) {
+		t.Error("keyed BLAKE2b tags should depend on the key")
+	}
+
+	if _, err := NewComputer(AlgorithmHMACSHA512); !errors.Is(err, ErrKeyRequired) {
+		t.Errorf("NewComputer(hmac-sha512) error = %v, want ErrKeyRequired", err)
+	}
+	if _, err := NewKeyedComputer(nil, AlgorithmHMACSHA512); !errors.Is(err, ErrKeyRequired) {
+		t.Errorf("NewKeyedComputer(nil) error = %v, want ErrKeyRequired", err)
+	}
+	if _, err := NewKeyedComputer(make([]byte, 65), AlgorithmBLAKE2bKeyed); err == nil {
+		t.Error("expected an error for a keyed BLAKE2b key longer than 64 bytes")
+	}
+	for _, name := range DetectHashAlgorithm(want) {
+		if IsKeyed(name) {
+			t.Errorf("DetectHashAlgorithm guessed keyed algorithm %s", name)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package hash
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSampleOffsets(t *testing.T) {
+	const block = 10
+	tests := []struct {
+		name string
+		size int64
+		want []int64
+	}{
+		{"empty", 0, []int64{0}},
+		{"fits in the blocks", 40, []int64{0}},
+		{"evenly spread", 100, []int64{0, 30, 60, 90}},
+		{"huge", 1 << 62, []int64{0, (1<<62 - block) / 3, 2 * ((1<<62 - block) / 3), 1<<62 - block}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sampleOffsets(tt.size, 4, block)
+			if len(got) != len(tt.want) {
+				t.Fatalf("sampleOffsets() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("sampleOffsets()[%d] = %d, want %d", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSampledAlgorithms(t *testing.T) {
+	dir := t.TempDir()
+	size := 4 * DefaultSampleBlocks * DefaultSampleBlockSize
+	data := make([]byte, size)
+	path := filepath.Join(dir, "image")
+	os.WriteFile(path, data, 0644)
+
+	c, err := NewComputer(AlgorithmSampledSHA256, AlgorithmSHA256)
+	if err != nil {
+		t.Fatalf("NewComputer() error = %v", err)
+	}
+	entry, err := c.ComputeFile(context.Background(), path)
+	if err != nil {
+		t.Fatalf("ComputeFile() error = %v", err)
+	}
+	full, _ := NewComputer(AlgorithmSHA256)
+	if entry.Hash == full.ComputeBytes(data) || entry.Hashes[AlgorithmSHA256] != full.ComputeBytes(data) {
+		t.Errorf("sampled and full digests mixed up: %v", entry.Hashes)
+	}
+	if digest, _ := SampledDigest(path, AlgorithmSampledSHA256); digest != entry.Hash {
+		t.Errorf("SampledDigest() = %s, ComputeFile() = %s", digest, entry.Hash)
+	}
+	if c.ComputeBytes(data) != entry.Hash {
+		t.Error("ComputeBytes() should sample in-memory data the same way")
+	}
+
+	// A change inside a sampled block is seen; one between blocks is not.
+	data[0] = 1
+	os.WriteFile(path, data, 0644)
+	if digest, _ := SampledDigest(path, AlgorithmSampledSHA256); digest == entry.Hash {
+		t.Error("change in the first block not detected")
+	}
+	data[0], data[DefaultSampleBlockSize+1] = 0, 1
+	os.WriteFile(path, data, 0644)
+	if digest, _ := SampledDigest(path, AlgorithmSampledSHA256); digest != entry.Hash {
+		t.Error("change between blocks should not affect a sampled digest")
+	}
+
+	if _, err := c.ComputeStream(context.Background(), StdinEntryName, strings.NewReader("x")); !errors.Is(err, ErrSampledNeedsFile) {
+		t.Errorf("ComputeStream() error = %v, want ErrSampledNeedsFile", err)
+	}
+	if got := DetectHashAlgorithm(entry.Hash); len(got) > 0 && IsSampled(got[0]) {
+		t.Errorf("sampled algorithms must not be guessed from a digest, got %v", got)
+	}
+	if got := LabelDigest("abcd", AlgorithmSampledXXH64); got != "sampled-xxh64:abcd" {
+		t.Errorf("LabelDigest() = %s", got)
+	}
+	if got := LabelDigest("abcd", AlgorithmSHA256); got != "abcd" {
+		t.Errorf("LabelDigest() labelled a full digest: %s", got)
+	}
+}
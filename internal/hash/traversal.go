@@ -0,0 +1,79 @@
+package hash
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Reasons a walk passes over a path without reading it, reported by
+// Discovery.Skipped. None of them is an error: they are what the options
+// asked for.
+const (
+	SkipSymlink     = "symbolic link not followed"
+	SkipBrokenLink  = "broken symbolic link"
+	SkipLoop        = "file system loop"
+	SkipOtherDevice = "on another file system"
+)
+
+// SkippedPath is a path the walk deliberately did not follow.
+type SkippedPath struct {
+	Path   string
+	Reason string // One of the Skip constants
+}
+
+// Skipped returns the symbolic links, loops and mount points the walk
+// passed over, sorted by path. It is only complete once Files has been
+// closed.
+func (d *Discovery) Skipped() []SkippedPath {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	skipped := append([]SkippedPath(nil), d.skipped...)
+	sort.Slice(skipped, func(i, j int) bool { return skipped[i].Path < skipped[j].Path })
+	return skipped
+}
+
+// skip notes that path was passed over on purpose.
+func (d *Discovery) skip(path, reason string) {
+	d.mu.Lock()
+	d.skipped = append(d.skipped, SkippedPath{Path: path, Reason: reason})
+	d.mu.Unlock()
+}
+
+// dirIdentity tells directories apart for loop detection and
+// OneFileSystem: by device and inode where the platform has them (see
+// fileID), otherwise by the absolute path with every link resolved.
+type dirIdentity struct {
+	device, inode uint64
+	path          string
+}
+
+// identify returns the identity of the directory at path, described by info.
+func identify(path string, info os.FileInfo) dirIdentity {
+	if id, ok := fileID(info); ok {
+		return dirIdentity{device: id.Device, inode: id.Inode}
+	}
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		path = real
+	}
+	abs, _ := filepath.Abs(path)
+	return dirIdentity{path: abs}
+}
+
+// dirChain lists the directories from a root down to the one being read.
+// Like ignoreList it is immutable, so jobs share their parents' chains.
+type dirChain struct {
+	id     dirIdentity
+	parent *dirChain
+}
+
+// contains reports whether id is the directory at the end of the chain or
+// one of those above it, that is, whether descending into it would loop.
+func (c *dirChain) contains(id dirIdentity) bool {
+	for ; c != nil; c = c.parent {
+		if c.id == id {
+			return true
+		}
+	}
+	return false
+}
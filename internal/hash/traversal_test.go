@@ -0,0 +1,126 @@
+package hash
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// walkTree runs Discover over dir and returns the files found and the paths
+// skipped, relative to dir.
+func walkTree(t *testing.T, dir string, opts DiscoveryOptions) (files, skipped []string) {
+	t.Helper()
+	d := Discover(context.Background(), []string{dir}, opts)
+	var found []Found
+	for f := range d.Files {
+		found = append(found, f)
+	}
+	if errs := d.Errors(); len(errs) > 0 {
+		t.Fatalf("Discover() errors = %v", errs)
+	}
+	SortFound(found)
+	rel := func(p string) string {
+		r, _ := filepath.Rel(dir, p)
+		return filepath.ToSlash(r)
+	}
+	for _, f := range found {
+		files = append(files, rel(f.Path))
+	}
+	for _, s := range d.Skipped() {
+		skipped = append(skipped, fmt.Sprintf("%s: %s", rel(s.Path), s.Reason))
+	}
+	return files, skipped
+}
+
+func TestDiscover_MaxDepth(t *testing.T) {
+	dir := t.TempDir()
+	for _, rel := range []string{"a", "x/b", "x/y/c", "x/y/z/d"} {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, nil, 0644)
+	}
+
+	tests := []struct {
+		depth int
+		want  []string
+	}{
+		{0, []string{"a", "x/b", "x/y/c", "x/y/z/d"}},
+		{1, []string{"a"}},
+		{2, []string{"a", "x/b"}},
+		{3, []string{"a", "x/b", "x/y/c"}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.depth), func(t *testing.T) {
+			files, _ := walkTree(t, dir, DiscoveryOptions{Recursive: true, MaxSize: -1, MaxDepth: tt.depth})
+			if strings.Join(files, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("MaxDepth %d: got %v, want %v", tt.depth, files, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscover_Symlinks(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "data", "sub"), 0755)
+	os.WriteFile(filepath.Join(dir, "data", "f"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, "data", "sub", "g"), nil, 0644)
+	links := map[string]string{
+		"data/sub/up": "..",     // a loop back to data
+		"data/gone":   "absent", // a broken link
+		"flink":       "data/f",
+		"dlink":       "data/sub",
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, filepath.Join(dir, filepath.FromSlash(link))); err != nil {
+			t.Skipf("symbolic links not supported: %v", err)
+		}
+	}
+
+	t.Run("not followed", func(t *testing.T) {
+		files, skipped := walkTree(t, dir, DiscoveryOptions{Recursive: true, MaxSize: -1})
+		if want := "data/f,data/sub/g"; strings.Join(files, ",") != want {
+			t.Errorf("files = %v, want %s", files, want)
+		}
+		want := []string{
+			"data/gone: " + SkipSymlink,
+			"data/sub/up: " + SkipSymlink,
+			"dlink: " + SkipSymlink,
+			"flink: " + SkipSymlink,
+		}
+		if strings.Join(skipped, "\n") != strings.Join(want, "\n") {
+			t.Errorf("skipped = %q, want %q", skipped, want)
+		}
+	})
+
+	t.Run("followed", func(t *testing.T) {
+		files, skipped := walkTree(t, dir, DiscoveryOptions{Recursive: true, MaxSize: -1, FollowSymlinks: true})
+		// As with find -L, a directory is walked once for each way of
+		// reaching it; only a link back to a directory above stops.
+		if want := "data/f,data/sub/g,dlink/g,dlink/up/f,flink"; strings.Join(files, ",") != want {
+			t.Errorf("files = %v, want %s", files, want)
+		}
+		want := []string{
+			"data/gone: " + SkipBrokenLink,
+			"data/sub/up: " + SkipLoop,
+			"dlink/up/gone: " + SkipBrokenLink,
+			"dlink/up/sub: " + SkipLoop,
+		}
+		if strings.Join(skipped, "\n") != strings.Join(want, "\n") {
+			t.Errorf("skipped = %q, want %q", skipped, want)
+		}
+	})
+
+	t.Run("link arguments", func(t *testing.T) {
+		files, err := DiscoverFiles(context.Background(), []string{filepath.Join(dir, "flink"), filepath.Join(dir, "dlink")}, DiscoveryOptions{MaxSize: -1})
+		if err != nil {
+			t.Fatalf("DiscoverFiles() error = %v", err)
+		}
+		want := []string{filepath.Join(dir, "flink"), filepath.Join(dir, "dlink", "g")}
+		if strings.Join(files, ",") != strings.Join(want, ",") {
+			t.Errorf("got %v, want %v", files, want)
+		}
+	})
+}
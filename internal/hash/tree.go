@@ -0,0 +1,196 @@
+package hash
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TreeDigest is a single digest describing a whole directory tree.
+//
+// DESIGN PRINCIPLE: Location-Independent, Order-Independent
+// ---------------------------------------------------------
+// The digest of a directory is the hash of a canonical listing of its
+// children, sorted by name. A file contributes its permission bits, content
+// hash and name; a subdirectory contributes its own digest and name. Because
+// only paths relative to the root are used and every listing is sorted, two
+// trees with identical contents produce the same root digest no matter where
+// they live on disk or in which order the filesystem returns entries.
+//
+// Only files selected by discovery take part, so empty directories (and
+// anything excluded by filters or hidden-file rules) do not affect the digest.
+type TreeDigest struct {
+	Root        string      // Directory the digest describes, as given by the caller
+	Algorithm   string      // Algorithm used for file and directory digests
+	Hash        string      // Root digest
+	Files       int         // Number of files included
+	Directories []DirDigest // Digest of every directory, root (".") first, then by path
+}
+
+// DirDigest is the digest of one directory within a TreeDigest.
+type DirDigest struct {
+	Path string // Slash-separated path relative to the tree root
+	Hash string
+}
+
+// treeChild is one line of a directory's canonical listing.
+type treeChild struct {
+	name  string
+	isDir bool
+	mode  os.FileMode
+	hash  string
+}
+
+// ComputeTree computes the Merkle digest of the directory at root.
+//
+// STEP-BY-STEP PROCESS:
+// 1. Discover every file below root (recursion is always on).
+// 2. Hash the files with the worker pool, using the primary algorithm.
+// 3. Group files under their parent directories, registering every ancestor.
+// 4. Hash directories deepest-first so each parent sees its children's digests.
+//
+// Any file that fails to hash fails the whole tree: a digest over a partial
+// tree would silently describe something other than what is on disk.
+func (c *Computer) ComputeTree(ctx context.Context, root string, opts DiscoveryOptions, workers int) (*TreeDigest, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+
+	opts.Recursive = true
+	files, err := DiscoverFiles(ctx, []string{root}, opts)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if walkErrs, ok := err.(DiscoveryErrors); ok {
+		// An unreadable directory fails the tree just as an unreadable file
+		// does; report the first so the message names the path.
+		return nil, walkErrs[0]
+	}
+
+	// The first failure cancels the rest of the batch; the channel is still
+	// drained so no worker is left blocked on a send.
+	batchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	children := map[string][]treeChild{".": nil}
+	var firstErr error
+	for entry := range c.ComputeBatch(batchCtx, files, workers) {
+		if firstErr != nil {
+			continue
+		}
+		child, dir, err := treeLeaf(root, entry)
+		if err != nil {
+			firstErr = err
+			cancel()
+			continue
+		}
+		registerAncestors(children, dir)
+		children[dir] = append(children[dir], child)
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	dirs := make([]string, 0, len(children))
+	for dir := range children {
+		dirs = append(dirs, dir)
+	}
+	// Deepest first, so every subdirectory is hashed before its parent.
+	sort.Slice(dirs, func(i, j int) bool {
+		di, dj := treeDepth(dirs[i]), treeDepth(dirs[j])
+		if di != dj {
+			return di > dj
+		}
+		return dirs[i] < dirs[j]
+	})
+
+	digests := make(map[string]string, len(dirs))
+	for _, dir := range dirs {
+		digests[dir] = c.hashListing(children[dir], digests, dir)
+	}
+
+	result := &TreeDigest{
+		Root:      root,
+		Algorithm: c.algorithm,
+		Hash:      digests["."],
+		Files:     len(files),
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if dirs[i] == "." || dirs[j] == "." {
+			return dirs[i] == "."
+		}
+		return dirs[i] < dirs[j]
+	})
+	for _, dir := range dirs {
+		result.Directories = append(result.Directories, DirDigest{Path: dir, Hash: digests[dir]})
+	}
+	return result, nil
+}
+
+// treeLeaf turns a hashed file into a listing entry and the slash-separated
+// directory (relative to root) it belongs to. The mode comes from the stat
+// of the open file that was hashed, so a file replaced in the meantime
+// cannot pair one file's content with another's permissions.
+func treeLeaf(root string, entry Entry) (treeChild, string, error) {
+	if entry.Error != nil {
+		return treeChild{}, "", entry.Error
+	}
+	rel, err := filepath.Rel(root, entry.Original)
+	if err != nil {
+		return treeChild{}, "", err
+	}
+	rel = filepath.ToSlash(rel)
+	child := treeChild{name: path.Base(rel), mode: entry.Mode.Perm(), hash: entry.Hash}
+	return child, path.Dir(rel), nil
+}
+
+// registerAncestors makes sure dir and every directory above it appear in
+// children, each listed as a child of its parent exactly once.
+func registerAncestors(children map[string][]treeChild, dir string) {
+	for dir != "." {
+		if _, ok := children[dir]; ok {
+			return
+		}
+		children[dir] = nil
+		parent := path.Dir(dir)
+		children[parent] = append(children[parent], treeChild{name: path.Base(dir), isDir: true})
+		dir = parent
+	}
+}
+
+// hashListing hashes the canonical listing of one directory. Subdirectory
+// digests are looked up in digests, which must already contain them.
+func (c *Computer) hashListing(entries []treeChild, digests map[string]string, dir string) string {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+
+	h := c.newHasher()
+	for _, e := range entries {
+		// Names are NUL-terminated: NUL is the one byte a file name cannot contain.
+		if e.isDir {
+			fmt.Fprintf(h, "dir %s %s\x00", digests[path.Join(dir, e.name)], e.name)
+		} else {
+			fmt.Fprintf(h, "file %04o %s %s\x00", e.mode, e.hash, e.name)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// treeDepth returns how many levels below the root a relative directory is.
+func treeDepth(dir string) int {
+	if dir == "." {
+		return 0
+	}
+	return strings.Count(dir, "/") + 1
+}
@@ -0,0 +1,134 @@
+package hash
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTree creates files (relative path -> content) under dir in the given order.
+func writeTree(t *testing.T, dir string, order []string, files map[string]string) {
+	t.Helper()
+	for _, rel := range order {
+		p := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(files[rel]), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestComputeTree(t *testing.T) {
+	files := map[string]string{
+		"a.txt":         "alpha",
+		"sub/b.txt":     "bravo",
+		"sub/deep/c.go": "charlie",
+	}
+	forward := []string{"a.txt", "sub/b.txt", "sub/deep/c.go"}
+	backward := []string{"sub/deep/c.go", "sub/b.txt", "a.txt"}
+
+	c, _ := NewComputer(AlgorithmSHA256)
+	compute := func(dir string) *TreeDigest {
+		t.Helper()
+		tree, err := c.ComputeTree(context.Background(), dir, DiscoveryOptions{MaxSize: -1}, 2)
+		if err != nil {
+			t.Fatalf("ComputeTree(%s) error = %v", dir, err)
+		}
+		return tree
+	}
+
+	dir1 := t.TempDir()
+	writeTree(t, dir1, forward, files)
+	dir2 := filepath.Join(t.TempDir(), "elsewhere", "copy")
+	writeTree(t, dir2, backward, files)
+
+	tree1, tree2 := compute(dir1), compute(dir2)
+
+	t.Run("location and order independent", func(t *testing.T) {
+		if tree1.Hash != tree2.Hash {
+			t.Errorf("identical trees differ: %s vs %s", tree1.Hash, tree2.Hash)
+		}
+		if tree1.Files != 3 {
+			t.Errorf("Files = %d, want 3", tree1.Files)
+		}
+	})
+
+	t.Run("breakdown", func(t *testing.T) {
+		want := []string{".", "sub", "sub/deep"}
+		if len(tree1.Directories) != len(want) {
+			t.Fatalf("got %d directories, want %d", len(tree1.Directories), len(want))
+		}
+		for i, d := range tree1.Directories {
+			if d.Path != want[i] {
+				t.Errorf("Directories[%d] = %s, want %s", i, d.Path, want[i])
+			}
+		}
+		if tree1.Directories[0].Hash != tree1.Hash {
+			t.Error("root entry should carry the root digest")
+		}
+	})
+
+	t.Run("content change", func(t *testing.T) {
+		os.WriteFile(filepath.Join(dir2, "sub", "deep", "c.go"), []byte("changed"), 0644)
+		changed := compute(dir2)
+		if changed.Hash == tree1.Hash {
+			t.Error("content change should alter the root digest")
+		}
+		if changed.Directories[1].Hash == tree1.Directories[1].Hash {
+			t.Error("content change should alter the parent directory digests")
+		}
+	})
+
+	t.Run("mode change", func(t *testing.T) {
+		os.Chmod(filepath.Join(dir1, "a.txt"), 0755)
+		if compute(dir1).Hash == tree1.Hash {
+			t.Error("mode change should alter the root digest")
+		}
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		dir3 := t.TempDir()
+		writeTree(t, dir3, []string{"a.txt", "sub/b.txt", "sub/deep/d.go"}, map[string]string{
+			"a.txt": "alpha", "sub/b.txt": "bravo", "sub/deep/d.go": "charlie",
+		})
+		if compute(dir3).Hash == tree1.Hash {
+			t.Error("renaming a file should alter the root digest")
+		}
+	})
+}
+
+func TestComputeTree_NotDirectory(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file.txt")
+	os.WriteFile(file, []byte("x"), 0644)
+
+	c, _ := NewComputer(AlgorithmSHA256)
+	if _, err := c.ComputeTree(context.Background(), file, DiscoveryOptions{MaxSize: -1}, 1); err == nil {
+		t.Error("expected an error for a non-directory root")
+	}
+}
+
+func TestTreeLeaf_ModeFromEntry(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "sub", "f")
+	os.MkdirAll(filepath.Dir(path), 0755)
+	os.WriteFile(path, []byte("data"), 0640)
+
+	c, _ := NewComputer(AlgorithmSHA256)
+	entry, err := c.ComputeFile(context.Background(), path)
+	if err != nil {
+		t.Fatalf("ComputeFile() error = %v", err)
+	}
+	// The file is gone by the time the leaf is built; the mode hashed with
+	// it must still be the one it had when it was read.
+	os.Remove(path)
+	child, dir, err := treeLeaf(root, *entry)
+	if err != nil {
+		t.Fatalf("treeLeaf() error = %v", err)
+	}
+	if dir != "sub" || child.name != "f" || child.mode != entry.Mode.Perm() || child.mode&0200 == 0 {
+		t.Errorf("treeLeaf() = %+v in %q, want f in sub with mode %v", child, dir, entry.Mode.Perm())
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/Les-El/chexum/internal/hash"
@@ -13,18 +14,24 @@ import (
 
 // Manifest represents a snapshot of file hashes and metadata.
 type Manifest struct {
-	Version   int          `json:"version"`
-	Algorithm string       `json:"algorithm"`
-	Created   time.Time    `json:"created"`
-	Files     []FileRecord `json:"files"`
+	Version    int          `json:"version"`
+	Algorithm  string       `json:"algorithm"`
+	Algorithms []string     `json:"algorithms,omitempty"` // Set when several digests were computed
+	Created    time.Time    `json:"created"`
+	Incomplete bool         `json:"incomplete,omitempty"` // Set when the run was interrupted
+	Keyed      bool         `json:"keyed,omitempty"`      // Set when a keyed algorithm was used; the key is never stored
+	Encoding   string       `json:"encoding,omitempty"`   // Digest encoding when not hex (see hash.Encodings)
+	Sampled    bool         `json:"sampled,omitempty"`    // Set when a sampled fingerprint (not a full digest) was recorded
+	Files      []FileRecord `json:"files"`
 }
 
 // FileRecord represents metadata for a single file in the manifest.
 type FileRecord struct {
-	Path  string    `json:"path"`
-	Size  int64     `json:"size"`
-	Mtime time.Time `json:"mtime"`
-	Hash  string    `json:"hash"`
+	Path   string            `json:"path"`
+	Size   int64             `json:"size"`
+	Mtime  time.Time         `json:"mtime"`
+	Hash   string            `json:"hash"`
+	Hashes map[string]string `json:"hashes,omitempty"` // Every digest, keyed by algorithm
 }
 
 // New creates a new Manifest.
@@ -38,12 +45,17 @@ func New(algorithm string, entries []hash.Entry) *Manifest {
 
 	for _, e := range entries {
 		if e.Error == nil {
-			m.Files = append(m.Files, FileRecord{
+			record := FileRecord{
 				Path:  e.Original,
 				Size:  e.Size,
 				Mtime: e.ModTime,
 				Hash:  e.Hash,
-			})
+			}
+			// Only record the full map when it adds information beyond Hash.
+			if len(e.Hashes) > 1 {
+				record.Hashes = e.Hashes
+			}
+			m.Files = append(m.Files, record)
 		}
 	}
 
@@ -111,6 +123,11 @@ const (
 )
 
 // GetChangedFiles compares current files against the manifest.
+//
+// A file is changed when its size or mtime differs from its record. When the
+// manifest also holds a sampled fingerprint (see hash.SampledDigest), files
+// that pass that test are re-sampled too: reading a few blocks is cheap and
+// catches rewrites that preserved the mtime.
 func (m *Manifest) GetChangedFiles(currentFiles []string) ([]string, error) {
 	manifestMap := make(map[string]FileRecord)
 	for _, r := range m.Files {
@@ -139,8 +156,42 @@ func (m *Manifest) GetChangedFiles(currentFiles []string) ([]string, error) {
 		// Change detection based on size and mtime
 		if info.Size() != record.Size || !info.ModTime().Equal(record.Mtime) {
 			changed = append(changed, path)
+			continue
+		}
+
+		if algorithm, recorded, ok := m.sampledDigest(record); ok {
+			current, err := hash.SampledDigest(path, algorithm)
+			if err != nil || !m.sameDigest(current, recorded, algorithm) {
+				changed = append(changed, path)
+			}
 		}
 	}
 
 	return changed, nil
 }
+
+// sampledDigest returns the first sampled fingerprint recorded for a file.
+func (m *Manifest) sampledDigest(record FileRecord) (algorithm, digest string, ok bool) {
+	if hash.IsSampled(m.Algorithm) && record.Hash != "" {
+		return m.Algorithm, record.Hash, true
+	}
+	for _, spec := range hash.Algorithms() {
+		if d, found := record.Hashes[spec.Name]; found && spec.Sampled {
+			return spec.Name, d, true
+		}
+	}
+	return "", "", false
+}
+
+// sameDigest compares a freshly computed hex digest with one recorded in the
+// manifest's encoding.
+func (m *Manifest) sameDigest(hexDigest, recorded, algorithm string) bool {
+	encoded, err := hash.EncodeDigest(hexDigest, algorithm, m.Encoding)
+	if err != nil {
+		return false
+	}
+	if m.Encoding == "" || m.Encoding == hash.EncodingHex {
+		return strings.EqualFold(encoded, recorded)
+	}
+	return encoded == recorded
+}
@@ -1,6 +1,7 @@
 package manifest
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -19,6 +20,20 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNew_MultipleDigests(t *testing.T) {
+	entries := []hash.Entry{
+		{Original: "f1.txt", Hash: "h1", Hashes: map[string]string{"sha256": "h1", "md5": "m1"}, Algorithm: "sha256"},
+		{Original: "f2.txt", Hash: "h2", Hashes: map[string]string{"sha256": "h2"}, Algorithm: "sha256"},
+	}
+	m := New("sha256", entries)
+	if m.Files[0].Hashes["md5"] != "m1" {
+		t.Errorf("expected md5 digest to be recorded, got %v", m.Files[0].Hashes)
+	}
+	if m.Files[1].Hashes != nil {
+		t.Errorf("single-digest records should omit hashes, got %v", m.Files[1].Hashes)
+	}
+}
+
 func TestLoad(t *testing.T) {
 	tmpDir := t.TempDir()
 	manifestPath := filepath.Join(tmpDir, "manifest.json")
@@ -154,6 +169,33 @@ func TestGetChangedFiles(t *testing.T) {
 	})
 }
 
+func TestGetChangedFiles_Sampled(t *testing.T) {
+	tmpDir := t.TempDir()
+	f1 := filepath.Join(tmpDir, "disk.img")
+	os.WriteFile(f1, []byte("original content"), 0644)
+
+	c, _ := hash.NewComputer(hash.AlgorithmSHA256, hash.AlgorithmSampledXXH64)
+	entry, err := c.ComputeFile(context.Background(), f1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := New(hash.AlgorithmSHA256, []hash.Entry{*entry})
+
+	t.Run("Unchanged", func(t *testing.T) {
+		if changed, _ := m.GetChangedFiles([]string{f1}); len(changed) != 0 {
+			t.Errorf("Expected 0 changed, got %v", changed)
+		}
+	})
+
+	t.Run("RewrittenWithSameMtime", func(t *testing.T) {
+		os.WriteFile(f1, []byte("modified content"), 0644)
+		os.Chtimes(f1, entry.ModTime, entry.ModTime)
+		if changed, _ := m.GetChangedFiles([]string{f1}); len(changed) != 1 {
+			t.Errorf("Expected the sampled fingerprint to catch the rewrite, got %v", changed)
+		}
+	})
+}
+
 func TestManifest(t *testing.T) {
 	// Wrapper test to keep original entry point if needed
 	t.Run("New", TestNew)
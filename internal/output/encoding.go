@@ -0,0 +1,95 @@
+package output
+
+import (
+	"strings"
+
+	"github.com/Les-El/chexum/internal/hash"
+)
+
+// encodingFormatter renders every digest in a non-hex encoding before
+// handing the result to the wrapped formatter.
+type encodingFormatter struct {
+	inner    Formatter
+	encoding string
+}
+
+// WithEncoding wraps f so that every digest it prints is written in encoding
+// (see hash.Encodings). Grouping and matching have already happened on hex
+// digests by the time a result is formatted, so only a copy is re-encoded.
+func WithEncoding(f Formatter, encoding string) Formatter {
+	if encoding == "" || encoding == hash.EncodingHex {
+		return f
+	}
+	return &encodingFormatter{inner: f, encoding: encoding}
+}
+
+// Format implements Formatter for encodingFormatter.
+func (f *encodingFormatter) Format(result *hash.Result) string {
+	return f.inner.Format(encodeResult(result, f.encoding))
+}
+
+// encodeResult returns a shallow copy of result with every digest encoded.
+func encodeResult(result *hash.Result, encoding string) *hash.Result {
+	out := *result
+	out.Entries = hash.EncodeEntries(result.Entries, encoding)
+	out.Unmatched = hash.EncodeEntries(result.Unmatched, encoding)
+	out.RefOrphans = hash.EncodeEntries(result.RefOrphans, encoding)
+
+	out.Matches = make([]hash.MatchGroup, len(result.Matches))
+	for i, group := range result.Matches {
+		encoded := group
+		encoded.Entries = hash.EncodeEntries(group.Entries, encoding)
+		if len(group.Entries) > 0 {
+			encoded.Hash = encodeOrKeep(group.Hash, group.Entries[0].Algorithm, encoding)
+		}
+		out.Matches[i] = encoded
+	}
+
+	out.PoolMatches = make([]hash.PoolMatch, len(result.PoolMatches))
+	for i, m := range result.PoolMatches {
+		// The provided hash may have matched a secondary digest; encode it
+		// with the algorithm that actually produced it.
+		providedAlg := m.Algorithm
+		for _, e := range result.Entries {
+			if e.Original != m.FilePath {
+				continue
+			}
+			for _, d := range e.Digests() {
+				if strings.EqualFold(d.Hash, m.ProvidedHash) {
+					providedAlg = d.Algorithm
+				}
+			}
+		}
+		m.ComputedHash = encodeOrKeep(m.ComputedHash, m.Algorithm, encoding)
+		m.ProvidedHash = encodeOrKeep(m.ProvidedHash, providedAlg, encoding)
+		out.PoolMatches[i] = m
+	}
+	return &out
+}
+
+// EncodeTrees returns copies of trees with the root and directory digests
+// written in encoding.
+func EncodeTrees(trees []*hash.TreeDigest, encoding string) []*hash.TreeDigest {
+	if encoding == "" || encoding == hash.EncodingHex {
+		return trees
+	}
+	out := make([]*hash.TreeDigest, len(trees))
+	for i, tree := range trees {
+		encoded := *tree
+		encoded.Hash = encodeOrKeep(tree.Hash, tree.Algorithm, encoding)
+		encoded.Directories = make([]hash.DirDigest, len(tree.Directories))
+		for j, d := range tree.Directories {
+			encoded.Directories[j] = hash.DirDigest{Path: d.Path, Hash: encodeOrKeep(d.Hash, tree.Algorithm, encoding)}
+		}
+		out[i] = &encoded
+	}
+	return out
+}
+
+// encodeOrKeep encodes a hex digest, keeping it as hex if it cannot be encoded.
+func encodeOrKeep(hexDigest, algorithm, encoding string) string {
+	if s, err := hash.EncodeDigest(hexDigest, algorithm, encoding); err == nil {
+		return s
+	}
+	return hexDigest
+}
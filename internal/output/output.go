@@ -17,6 +17,7 @@ package output
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -71,9 +72,12 @@ func (f *DefaultFormatter) writeMatchGroups(sb *strings.Builder, groups []hash.M
 			if entry.IsReference {
 				sb.WriteString(fmt.Sprintf("REFERENCE:    %s\n", entry.Hash))
 			} else {
-				sb.WriteString(fmt.Sprintf("%s    %s\n", security.SanitizeOutput(entry.Original), entry.Hash))
+				sb.WriteString(fmt.Sprintf("%s    %s\n", security.SanitizeOutput(entry.Original), formatDigests(entry, "  ")))
 			}
 		}
+		if group.Wasted > 0 {
+			sb.WriteString(fmt.Sprintf("(%d copies of %s, %s wasted)\n", group.Count, FormatBytes(group.Size), FormatBytes(group.Wasted)))
+		}
 	}
 }
 
@@ -82,7 +86,7 @@ func (f *DefaultFormatter) writeUnmatched(sb *strings.Builder, unmatched []hash.
 		if i > 0 {
 			sb.WriteString("\n")
 		}
-		sb.WriteString(fmt.Sprintf("%s    %s\n", security.SanitizeOutput(entry.Original), entry.Hash))
+		sb.WriteString(fmt.Sprintf("%s    %s\n", security.SanitizeOutput(entry.Original), formatDigests(entry, "  ")))
 	}
 }
 
@@ -125,7 +129,7 @@ func (f *PreserveOrderFormatter) Format(result *hash.Result) string {
 
 	for _, entry := range result.Entries {
 		if entry.Error == nil {
-			sb.WriteString(fmt.Sprintf("%s    %s\n", security.SanitizeOutput(entry.Original), entry.Hash))
+			sb.WriteString(fmt.Sprintf("%s    %s\n", security.SanitizeOutput(entry.Original), formatDigests(entry, "  ")))
 		}
 	}
 
@@ -140,16 +144,28 @@ func (f *VerboseFormatter) Format(result *hash.Result) string {
 	var sb strings.Builder
 
 	// Header with processing stats
-	sb.WriteString(fmt.Sprintf("Processed %d files in %s\n\n",
+	sb.WriteString(fmt.Sprintf("Processed %d files in %s",
 		result.FilesProcessed, result.Duration.Round(time.Millisecond)))
+	if seconds := result.Duration.Seconds(); result.BytesProcessed > 0 && seconds > 0 {
+		sb.WriteString(fmt.Sprintf(" (%.1f MB/s)", float64(result.BytesProcessed)/seconds/(1024*1024)))
+	}
+	sb.WriteString("\n")
+	if result.Incomplete {
+		sb.WriteString("Interrupted: results are incomplete\n")
+	}
+	sb.WriteString("\n")
 
 	// Match groups
 	if len(result.Matches) > 0 {
 		sb.WriteString("Match Groups:\n")
 		for i, group := range result.Matches {
-			sb.WriteString(fmt.Sprintf("  Group %d (%d files):\n", i+1, group.Count))
+			if group.Wasted > 0 {
+				sb.WriteString(fmt.Sprintf("  Group %d (%d files, %s wasted):\n", i+1, group.Count, FormatBytes(group.Wasted)))
+			} else {
+				sb.WriteString(fmt.Sprintf("  Group %d (%d files):\n", i+1, group.Count))
+			}
 			for _, entry := range group.Entries {
-				sb.WriteString(fmt.Sprintf("    %s    %s\n", security.SanitizeOutput(entry.Original), entry.Hash))
+				sb.WriteString(fmt.Sprintf("    %s    %s\n", security.SanitizeOutput(entry.Original), formatDigests(entry, "  ")))
 			}
 			sb.WriteString("\n")
 		}
@@ -159,7 +175,7 @@ func (f *VerboseFormatter) Format(result *hash.Result) string {
 	if len(result.Unmatched) > 0 {
 		sb.WriteString("Unmatched Files:\n")
 		for _, entry := range result.Unmatched {
-			sb.WriteString(fmt.Sprintf("  %s    %s\n", security.SanitizeOutput(entry.Original), entry.Hash))
+			sb.WriteString(fmt.Sprintf("  %s    %s\n", security.SanitizeOutput(entry.Original), formatDigests(entry, "  ")))
 		}
 		sb.WriteString("\n")
 	}
@@ -167,6 +183,9 @@ func (f *VerboseFormatter) Format(result *hash.Result) string {
 	// Summary
 	sb.WriteString(fmt.Sprintf("Summary: %d match groups, %d unmatched files",
 		len(result.Matches), len(result.Unmatched)))
+	if wasted := totalWasted(result.Matches); wasted > 0 {
+		sb.WriteString(fmt.Sprintf(", %s reclaimable", FormatBytes(wasted)))
+	}
 
 	return sb.String()
 }
@@ -181,28 +200,35 @@ type JSONLFormatter struct{}
 type jsonOutput struct {
 	Processed   int              `json:"processed"`
 	DurationMS  int64            `json:"duration_ms"`
+	Incomplete  bool             `json:"incomplete,omitempty"`
 	MatchGroups []jsonMatchGroup `json:"match_groups"`
 	Unmatched   []jsonEntry      `json:"unmatched"`
 	Errors      []string         `json:"errors"`
 }
 
 type jsonMatchGroup struct {
-	Hash  string   `json:"hash"`
-	Count int      `json:"count"`
-	Files []string `json:"files"`
+	Hash        string            `json:"hash"`
+	Hashes      map[string]string `json:"hashes,omitempty"`
+	Count       int               `json:"count"`
+	Files       []string          `json:"files"`
+	Size        int64             `json:"size,omitempty"`
+	WastedBytes int64             `json:"wasted_bytes,omitempty"`
 }
 
 type jsonEntry struct {
-	File string `json:"file"`
-	Hash string `json:"hash"`
+	File   string            `json:"file"`
+	Hash   string            `json:"hash"`
+	Hashes map[string]string `json:"hashes,omitempty"`
 }
 
 type jsonlEntry struct {
-	Type      string `json:"type"`
-	Name      string `json:"name"`
-	Hash      string `json:"hash"`
-	Status    string `json:"status"`
-	Timestamp string `json:"timestamp"`
+	Type      string            `json:"type"`
+	Name      string            `json:"name"`
+	Hash      string            `json:"hash"`
+	Hashes    map[string]string `json:"hashes,omitempty"`
+	Status    string            `json:"status"`
+	Error     string            `json:"error,omitempty"`
+	Timestamp string            `json:"timestamp"`
 }
 
 // Format implements Formatter for JSONFormatter.
@@ -210,6 +236,7 @@ func (f *JSONFormatter) Format(result *hash.Result) string {
 	output := jsonOutput{
 		Processed:   result.FilesProcessed,
 		DurationMS:  result.Duration.Milliseconds(),
+		Incomplete:  result.Incomplete,
 		MatchGroups: make([]jsonMatchGroup, 0, len(result.Matches)),
 		Unmatched:   make([]jsonEntry, 0, len(result.Unmatched)),
 		Errors:      make([]string, 0, len(result.Errors)),
@@ -218,22 +245,32 @@ func (f *JSONFormatter) Format(result *hash.Result) string {
 	// Convert match groups
 	for _, group := range result.Matches {
 		files := make([]string, 0, len(group.Entries))
+		var hashes map[string]string
 		for _, entry := range group.Entries {
 			// We don't sanitize here because json.Marshal handles escapes
 			files = append(files, entry.Original)
+			// Every file in a group has identical content, so any file's
+			// digests describe the whole group.
+			if hashes == nil && !entry.IsReference {
+				hashes = multiDigests(entry)
+			}
 		}
 		output.MatchGroups = append(output.MatchGroups, jsonMatchGroup{
-			Hash:  group.Hash,
-			Count: group.Count,
-			Files: files,
+			Hash:        group.Hash,
+			Hashes:      hashes,
+			Count:       group.Count,
+			Files:       files,
+			Size:        group.Size,
+			WastedBytes: group.Wasted,
 		})
 	}
 
 	// Convert unmatched entries
 	for _, entry := range result.Unmatched {
 		output.Unmatched = append(output.Unmatched, jsonEntry{
-			File: entry.Original,
-			Hash: entry.Hash,
+			File:   entry.Original,
+			Hash:   entry.Hash,
+			Hashes: multiDigests(entry),
 		})
 	}
 
@@ -257,16 +294,18 @@ func (f *JSONLFormatter) Format(result *hash.Result) string {
 	now := time.Now().Format(time.RFC3339)
 
 	for _, entry := range result.Entries {
-		status := "success"
+		status, message := "success", ""
 		if entry.Error != nil {
-			status = "error"
+			status, message = "error", entry.Error.Error()
 		}
 
 		item := jsonlEntry{
 			Type:      "file",
 			Name:      entry.Original,
 			Hash:      entry.Hash,
+			Hashes:    multiDigests(entry),
 			Status:    status,
+			Error:     message,
 			Timestamp: now,
 		}
 
@@ -277,11 +316,38 @@ func (f *JSONLFormatter) Format(result *hash.Result) string {
 		}
 	}
 
+	// Paths skipped during discovery have no entry of their own.
+	for _, err := range result.Errors {
+		var skipped *hash.DiscoveryError
+		if !errors.As(err, &skipped) {
+			continue
+		}
+		data, err := json.Marshal(jsonlEntry{Type: "error", Name: skipped.Path, Status: "error", Error: skipped.Err.Error(), Timestamp: now})
+		if err == nil {
+			sb.Write(data)
+			sb.WriteString("\n")
+		}
+	}
+
+	// A trailing marker lets stream consumers tell a cut-short run from a complete one.
+	if result.Incomplete {
+		data, err := json.Marshal(jsonlEntry{Type: "summary", Status: "incomplete", Timestamp: now})
+		if err == nil {
+			sb.Write(data)
+			sb.WriteString("\n")
+		}
+	}
+
 	return strings.TrimSuffix(sb.String(), "\n")
 }
 
 // PlainFormatter outputs tab-separated results for scripting.
-type PlainFormatter struct{}
+type PlainFormatter struct {
+	// Null ends every record, the last included, with a NUL instead of a
+	// newline and prints names verbatim, as find -print0 does, so that
+	// names with newlines survive a round trip through xargs -0.
+	Null bool
+}
 
 // Format implements Formatter for PlainFormatter.
 func (f *PlainFormatter) Format(result *hash.Result) string {
@@ -289,8 +355,13 @@ func (f *PlainFormatter) Format(result *hash.Result) string {
 
 	// Output all entries in input order, tab-separated
 	for _, entry := range result.Entries {
-		if entry.Error == nil {
-			sb.WriteString(fmt.Sprintf("%s\t%s\n", security.SanitizeOutput(entry.Original), entry.Hash))
+		if entry.Error != nil {
+			continue
+		}
+		if f.Null {
+			sb.WriteString(fmt.Sprintf("%s\t%s\x00", entry.Original, formatDigests(entry, "\t")))
+		} else {
+			sb.WriteString(fmt.Sprintf("%s\t%s\n", security.SanitizeOutput(entry.Original), formatDigests(entry, "\t")))
 		}
 	}
 
@@ -298,6 +369,7 @@ func (f *PlainFormatter) Format(result *hash.Result) string {
 }
 
 // CSVFormatter outputs results in CSV format with Type, Name, Hash, Algorithm columns.
+// When several algorithms were computed, each file produces one row per digest.
 type CSVFormatter struct{}
 
 // Format implements Formatter for CSVFormatter.
@@ -310,16 +382,14 @@ func (f *CSVFormatter) Format(result *hash.Result) string {
 			if entry.IsReference {
 				sb.WriteString(fmt.Sprintf("REFERENCE,-,%s,%s\n", entry.Hash, entry.Algorithm))
 			} else {
-				sb.WriteString(fmt.Sprintf("FILE,%s,%s,%s\n",
-					security.SanitizeOutput(entry.Original), entry.Hash, entry.Algorithm))
+				writeCSVFileRows(&sb, entry)
 			}
 		}
 	}
 
 	// Output unmatched files
 	for _, entry := range result.Unmatched {
-		sb.WriteString(fmt.Sprintf("FILE,%s,%s,%s\n",
-			security.SanitizeOutput(entry.Original), entry.Hash, entry.Algorithm))
+		writeCSVFileRows(&sb, entry)
 	}
 
 	// Output orphaned reference hashes
@@ -335,6 +405,60 @@ func (f *CSVFormatter) Format(result *hash.Result) string {
 	return strings.TrimSuffix(sb.String(), "\n")
 }
 
+func writeCSVFileRows(sb *strings.Builder, entry hash.Entry) {
+	name := security.SanitizeOutput(entry.Original)
+	for _, d := range entry.Digests() {
+		sb.WriteString(fmt.Sprintf("FILE,%s,%s,%s\n", name, d.Hash, d.Algorithm))
+	}
+}
+
+// formatDigests renders an entry's digest column. A single digest is printed
+// bare, exactly as before multi-algorithm support; several digests are printed
+// as "algorithm:hash" pairs joined by sep so each value is self-describing.
+func formatDigests(entry hash.Entry, sep string) string {
+	digests := entry.Digests()
+	if len(digests) <= 1 {
+		return entry.Hash
+	}
+	parts := make([]string, len(digests))
+	for i, d := range digests {
+		parts[i] = d.Algorithm + ":" + d.Hash
+	}
+	return strings.Join(parts, sep)
+}
+
+// multiDigests returns the entry's digest map for JSON output, or nil when
+// only one algorithm was computed so the single-algorithm schema is unchanged.
+func multiDigests(entry hash.Entry) map[string]string {
+	if len(entry.Hashes) > 1 {
+		return entry.Hashes
+	}
+	return nil
+}
+
+// totalWasted sums the reclaimable bytes of duplicate groups.
+func totalWasted(groups []hash.MatchGroup) int64 {
+	var total int64
+	for _, g := range groups {
+		total += g.Wasted
+	}
+	return total
+}
+
+// FormatBytes formats a byte count in binary units, e.g. "1.5 MB".
+func FormatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
 // NewFormatter creates a formatter based on the format name.
 func NewFormatter(format string, preserveOrder bool) Formatter {
 	switch format {
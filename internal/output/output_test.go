@@ -3,6 +3,7 @@ package output
 import (
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"strings"
 	"testing"
 	"testing/quick"
@@ -479,6 +480,20 @@ func TestPlainFormatter_TabSeparated(t *testing.T) {
 	}
 }
 
+func TestPlainFormatter_Null(t *testing.T) {
+	result := &hash.Result{
+		Entries: []hash.Entry{
+			{Original: "new\nline", Hash: "hash1"},
+			{Original: "trailing ", Hash: "hash2"},
+			{Original: "failed", Error: fmt.Errorf("read error")},
+		},
+	}
+	want := "new\nline\thash1\x00trailing \thash2\x00"
+	if got := (&PlainFormatter{Null: true}).Format(result); got != want {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+}
+
 func TestNewFormatter_SelectsCorrectFormatter(t *testing.T) {
 	tests := []struct {
 		format        string
@@ -595,6 +610,219 @@ func TestCSVFormatter(t *testing.T) {
 	}
 }
 
+func TestFormatters_MultipleDigests(t *testing.T) {
+	entry := hash.Entry{
+		Original:  "file1.txt",
+		Hash:      "aaaa",
+		Hashes:    map[string]string{"sha256": "aaaa", "md5": "bbbb"},
+		Algorithm: "sha256",
+	}
+	result := &hash.Result{
+		Entries:   []hash.Entry{entry},
+		Unmatched: []hash.Entry{entry},
+	}
+
+	if out := (&DefaultFormatter{}).Format(result); out != "file1.txt    sha256:aaaa  md5:bbbb" {
+		t.Errorf("Default: got %q", out)
+	}
+	if out := (&PlainFormatter{}).Format(result); out != "file1.txt\tsha256:aaaa\tmd5:bbbb" {
+		t.Errorf("Plain: got %q", out)
+	}
+	if out := (&CSVFormatter{}).Format(result); out != "FILE,file1.txt,aaaa,sha256\nFILE,file1.txt,bbbb,md5" {
+		t.Errorf("CSV: got %q", out)
+	}
+
+	var parsed jsonOutput
+	if err := json.Unmarshal([]byte((&JSONFormatter{}).Format(result)), &parsed); err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+	if parsed.Unmatched[0].Hashes["md5"] != "bbbb" {
+		t.Errorf("JSON: expected md5 digest, got %v", parsed.Unmatched[0].Hashes)
+	}
+
+	if out := (&JSONLFormatter{}).Format(result); !strings.Contains(out, `"hashes":{"md5":"bbbb","sha256":"aaaa"}`) {
+		t.Errorf("JSONL: got %q", out)
+	}
+}
+
+func TestFormatters_Incomplete(t *testing.T) {
+	result := &hash.Result{
+		Entries:        []hash.Entry{{Original: "file1.txt", Hash: "aaaa"}},
+		Unmatched:      []hash.Entry{{Original: "file1.txt", Hash: "aaaa"}},
+		FilesProcessed: 1,
+		Incomplete:     true,
+	}
+
+	if out := (&VerboseFormatter{}).Format(result); !strings.Contains(out, "results are incomplete") {
+		t.Errorf("Verbose: missing incomplete notice in %q", out)
+	}
+
+	var parsed jsonOutput
+	if err := json.Unmarshal([]byte((&JSONFormatter{}).Format(result)), &parsed); err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+	if !parsed.Incomplete {
+		t.Error("JSON: expected incomplete to be true")
+	}
+
+	lines := strings.Split((&JSONLFormatter{}).Format(result), "\n")
+	if last := lines[len(lines)-1]; !strings.Contains(last, `"status":"incomplete"`) {
+		t.Errorf("JSONL: expected trailing incomplete marker, got %q", last)
+	}
+}
+
+func TestFormatters_DiscoveryErrors(t *testing.T) {
+	result := &hash.Result{
+		Entries: []hash.Entry{
+			{Original: "a.txt", Hash: "aaaa"},
+			{Original: "b.txt", Error: fs.ErrPermission},
+		},
+		Errors: []error{
+			fs.ErrPermission,
+			&hash.DiscoveryError{Path: "locked", Err: fs.ErrPermission},
+		},
+	}
+
+	var parsed jsonOutput
+	if err := json.Unmarshal([]byte((&JSONFormatter{}).Format(result)), &parsed); err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+	if len(parsed.Errors) != 2 {
+		t.Errorf("JSON: expected 2 errors, got %v", parsed.Errors)
+	}
+
+	lines := strings.Split((&JSONLFormatter{}).Format(result), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("JSONL: expected 3 lines, got %q", lines)
+	}
+	if !strings.Contains(lines[1], `"status":"error","error":"permission denied"`) {
+		t.Errorf("JSONL: file error lacks its message: %q", lines[1])
+	}
+	if !strings.Contains(lines[2], `"type":"error","name":"locked"`) {
+		t.Errorf("JSONL: expected a record for the skipped path, got %q", lines[2])
+	}
+}
+
+func TestFormatters_WastedSpace(t *testing.T) {
+	entries := []hash.Entry{{Original: "a.iso", Hash: "aaaa"}, {Original: "b.iso", Hash: "aaaa"}}
+	result := &hash.Result{
+		Entries: entries,
+		Matches: []hash.MatchGroup{{Hash: "aaaa", Entries: entries, Count: 2, Size: 2048, Wasted: 2048}},
+	}
+
+	if out := (&DefaultFormatter{}).Format(result); out != "a.iso    aaaa\nb.iso    aaaa\n(2 copies of 2.0 KB, 2.0 KB wasted)" {
+		t.Errorf("Default: got %q", out)
+	}
+	if out := (&VerboseFormatter{}).Format(result); !strings.Contains(out, "Group 1 (2 files, 2.0 KB wasted)") || !strings.Contains(out, "2.0 KB reclaimable") {
+		t.Errorf("Verbose: got %q", out)
+	}
+	var parsed jsonOutput
+	if err := json.Unmarshal([]byte((&JSONFormatter{}).Format(result)), &parsed); err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+	if g := parsed.MatchGroups[0]; g.Size != 2048 || g.WastedBytes != 2048 {
+		t.Errorf("JSON: got %+v", g)
+	}
+}
+
+func TestWithSampledLabels(t *testing.T) {
+	sampled := hash.Entry{Original: "vm.img", Hash: "abcd", Hashes: map[string]string{"sampled-xxh64": "abcd"}, Algorithm: "sampled-xxh64"}
+	full := hash.Entry{Original: "a.txt", Hash: "ef01", Hashes: map[string]string{"sha256": "ef01"}, Algorithm: "sha256"}
+
+	result := &hash.Result{Entries: []hash.Entry{sampled}, Unmatched: []hash.Entry{sampled}}
+	if out := WithSampledLabels(&DefaultFormatter{}).Format(result); out != "vm.img    sampled-xxh64:abcd" {
+		t.Errorf("Default: got %q", out)
+	}
+	if out := WithSampledLabels(&CSVFormatter{}).Format(result); out != "FILE,vm.img,abcd,sampled-xxh64" {
+		t.Errorf("CSV already names the algorithm: got %q", out)
+	}
+	if result.Entries[0].Hash != "abcd" {
+		t.Error("WithSampledLabels modified the original result")
+	}
+
+	result = &hash.Result{Entries: []hash.Entry{full}, Unmatched: []hash.Entry{full}}
+	if out := WithSampledLabels(&PlainFormatter{}).Format(result); out != "a.txt\tef01" {
+		t.Errorf("full digests must not be labelled: got %q", out)
+	}
+}
+
+func TestWithEncoding(t *testing.T) {
+	sha := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	sri := "sha256-LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ="
+	entry := hash.Entry{
+		Original:  "file1.txt",
+		Hash:      sha,
+		Hashes:    map[string]string{"sha256": sha, "md5": "5d41402abc4b2a76b9719d911017c592"},
+		Algorithm: "sha256",
+	}
+	result := &hash.Result{
+		Entries:     []hash.Entry{entry},
+		Matches:     []hash.MatchGroup{{Hash: sha, Entries: []hash.Entry{entry, entry}, Count: 2}},
+		PoolMatches: []hash.PoolMatch{{FilePath: "file1.txt", ComputedHash: sha, ProvidedHash: sha, Algorithm: "sha256"}},
+	}
+
+	if _, wrapped := WithEncoding(&PlainFormatter{}, hash.EncodingHex).(*encodingFormatter); wrapped {
+		t.Error("hex encoding should not wrap the formatter")
+	}
+
+	out := WithEncoding(&JSONFormatter{}, hash.EncodingSRI).Format(result)
+	var parsed jsonOutput
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+	if parsed.MatchGroups[0].Hash != sri || parsed.MatchGroups[0].Files[0] != "file1.txt" {
+		t.Errorf("match group not encoded: %+v", parsed.MatchGroups[0])
+	}
+	if strings.Contains(out, sha) {
+		t.Errorf("hex digest leaked into SRI output: %s", out)
+	}
+	if !strings.Contains(out, "5d41402abc4b2a76b9719d911017c592") {
+		t.Errorf("md5 has no SRI form and should stay hex: %s", out)
+	}
+	if result.Matches[0].Hash != sha || result.Entries[0].Hash != sha {
+		t.Error("WithEncoding modified the original result")
+	}
+}
+
+func TestFormatTrees(t *testing.T) {
+	tree := &hash.TreeDigest{
+		Root:      "build",
+		Algorithm: "sha256",
+		Hash:      "root",
+		Files:     2,
+		Directories: []hash.DirDigest{
+			{Path: ".", Hash: "root"},
+			{Path: "bin", Hash: "binhash"},
+		},
+	}
+	trees := []*hash.TreeDigest{tree}
+
+	tests := []struct {
+		format    string
+		breakdown bool
+		want      string
+	}{
+		{"default", false, "build    root"},
+		{"default", true, "build    root\n  bin/    binhash"},
+		{"plain", true, "build\troot\nbuild/bin\tbinhash"},
+		{"csv", true, "TREE,build,root,sha256\nDIR,build/bin,binhash,sha256"},
+		{"jsonl", false, `{"type":"tree","name":"build","hash":"root","algorithm":"sha256"}`},
+	}
+	for _, tt := range tests {
+		if got := FormatTrees(tt.format, trees, tt.breakdown); got != tt.want {
+			t.Errorf("FormatTrees(%s, %v) = %q, want %q", tt.format, tt.breakdown, got, tt.want)
+		}
+	}
+
+	var parsed []jsonTree
+	if err := json.Unmarshal([]byte(FormatTrees("json", trees, true)), &parsed); err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+	if len(parsed) != 1 || parsed[0].Hash != "root" || len(parsed[0].Directories) != 1 {
+		t.Errorf("JSON: unexpected %+v", parsed)
+	}
+}
+
 func TestFormat(t *testing.T) {
 	// Satisfy multiple entries in remediation plan
 	t.Run("Default", TestDefaultFormatter_SingleFile)
@@ -609,3 +837,22 @@ func TestFormat(t *testing.T) {
 func TestNewFormatter(t *testing.T) {
 	TestNewFormatter_SelectsCorrectFormatter(t)
 }
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		size int64
+		want string
+	}{
+		{500, "500 B"},
+		{1024, "1.0 KB"},
+		{1536, "1.5 KB"},
+		{1024 * 1024, "1.0 MB"},
+		{1024 * 1024 * 1024, "1.0 GB"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatBytes(tt.size); got != tt.want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", tt.size, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package output
+
+import (
+	"github.com/Les-El/chexum/internal/hash"
+)
+
+// sampledFormatter labels sampled fingerprints before handing the result to
+// the wrapped formatter.
+type sampledFormatter struct {
+	inner Formatter
+}
+
+// WithSampledLabels wraps f so that every sampled fingerprint is printed as
+// "sampled-sha256:<digest>" wherever a digest would otherwise appear without
+// its algorithm name. Per-algorithm maps and CSV rows already name the
+// algorithm and are left alone. Apply it inside WithEncoding so the label is
+// added after the digest has been encoded.
+func WithSampledLabels(f Formatter) Formatter {
+	return &sampledFormatter{inner: f}
+}
+
+// Format implements Formatter for sampledFormatter.
+func (f *sampledFormatter) Format(result *hash.Result) string {
+	return f.inner.Format(labelResult(result))
+}
+
+// labelResult returns a shallow copy of result with sampled digests labelled.
+// It returns result itself when nothing in it is sampled.
+func labelResult(result *hash.Result) *hash.Result {
+	if !hasSampled(result) {
+		return result
+	}
+	out := *result
+	out.Entries = labelEntries(result.Entries)
+	out.Unmatched = labelEntries(result.Unmatched)
+	out.RefOrphans = labelEntries(result.RefOrphans)
+
+	out.Matches = make([]hash.MatchGroup, len(result.Matches))
+	for i, group := range result.Matches {
+		labelled := group
+		labelled.Entries = labelEntries(group.Entries)
+		if len(group.Entries) > 0 {
+			labelled.Hash = hash.LabelDigest(group.Hash, group.Entries[0].Algorithm)
+		}
+		out.Matches[i] = labelled
+	}
+
+	out.PoolMatches = make([]hash.PoolMatch, len(result.PoolMatches))
+	for i, m := range result.PoolMatches {
+		if m.ProvidedHash == m.ComputedHash {
+			m.ProvidedHash = hash.LabelDigest(m.ProvidedHash, m.Algorithm)
+		}
+		m.ComputedHash = hash.LabelDigest(m.ComputedHash, m.Algorithm)
+		out.PoolMatches[i] = m
+	}
+	return &out
+}
+
+// labelEntries returns copies of entries with the primary digest labelled.
+func labelEntries(entries []hash.Entry) []hash.Entry {
+	out := make([]hash.Entry, len(entries))
+	for i, e := range entries {
+		e.Hash = hash.LabelDigest(e.Hash, e.Algorithm)
+		out[i] = e
+	}
+	return out
+}
+
+// hasSampled reports whether any entry in result carries a sampled digest.
+func hasSampled(result *hash.Result) bool {
+	for _, entries := range [][]hash.Entry{result.Entries, result.Unmatched, result.RefOrphans} {
+		for _, e := range entries {
+			if hash.IsSampled(e.Algorithm) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/Les-El/chexum/internal/hash"
+	"github.com/Les-El/chexum/internal/security"
+)
+
+// jsonTree is the JSON structure for a directory digest.
+type jsonTree struct {
+	Root        string          `json:"root"`
+	Algorithm   string          `json:"algorithm"`
+	Hash        string          `json:"hash"`
+	Files       int             `json:"files"`
+	Directories []jsonTreeEntry `json:"directories,omitempty"`
+}
+
+type jsonTreeEntry struct {
+	Path string `json:"path"`
+	Hash string `json:"hash"`
+}
+
+type jsonlTreeEntry struct {
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Hash      string `json:"hash"`
+	Algorithm string `json:"algorithm"`
+}
+
+// FormatTrees renders directory digests in the given output format.
+// With breakdown, every subdirectory digest follows its tree's root digest.
+func FormatTrees(format string, trees []*hash.TreeDigest, breakdown bool) string {
+	switch format {
+	case "json":
+		return formatTreesJSON(trees, breakdown)
+	case "jsonl":
+		return formatTreesJSONL(trees, breakdown)
+	}
+
+	var sb strings.Builder
+	for i, tree := range trees {
+		subdirs := treeSubdirs(tree, breakdown)
+		root := security.SanitizeOutput(tree.Root)
+		switch format {
+		case "plain":
+			sb.WriteString(fmt.Sprintf("%s\t%s\n", root, tree.Hash))
+			for _, d := range subdirs {
+				sb.WriteString(fmt.Sprintf("%s\t%s\n", security.SanitizeOutput(path.Join(tree.Root, d.Path)), d.Hash))
+			}
+		case "csv":
+			sb.WriteString(fmt.Sprintf("TREE,%s,%s,%s\n", root, tree.Hash, tree.Algorithm))
+			for _, d := range subdirs {
+				sb.WriteString(fmt.Sprintf("DIR,%s,%s,%s\n", security.SanitizeOutput(path.Join(tree.Root, d.Path)), d.Hash, tree.Algorithm))
+			}
+		default:
+			if i > 0 && (breakdown || format == "verbose") {
+				sb.WriteString("\n")
+			}
+			if format == "verbose" {
+				sb.WriteString(fmt.Sprintf("Tree %s (%s, %d files, %d directories)\n",
+					root, tree.Algorithm, tree.Files, len(tree.Directories)))
+			}
+			sb.WriteString(fmt.Sprintf("%s    %s\n", root, tree.Hash))
+			for _, d := range subdirs {
+				sb.WriteString(fmt.Sprintf("  %s/    %s\n", security.SanitizeOutput(d.Path), d.Hash))
+			}
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// treeSubdirs returns the non-root directory digests when a breakdown is requested.
+func treeSubdirs(tree *hash.TreeDigest, breakdown bool) []hash.DirDigest {
+	if !breakdown {
+		return nil
+	}
+	subdirs := make([]hash.DirDigest, 0, len(tree.Directories))
+	for _, d := range tree.Directories {
+		if d.Path != "." {
+			subdirs = append(subdirs, d)
+		}
+	}
+	return subdirs
+}
+
+func formatTreesJSON(trees []*hash.TreeDigest, breakdown bool) string {
+	out := make([]jsonTree, 0, len(trees))
+	for _, tree := range trees {
+		jt := jsonTree{
+			Root:      tree.Root,
+			Algorithm: tree.Algorithm,
+			Hash:      tree.Hash,
+			Files:     tree.Files,
+		}
+		for _, d := range treeSubdirs(tree, breakdown) {
+			jt.Directories = append(jt.Directories, jsonTreeEntry{Path: d.Path, Hash: d.Hash})
+		}
+		out = append(out, jt)
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Sprintf(`{"error": "failed to marshal JSON: %s"}`, err.Error())
+	}
+	return string(data)
+}
+
+func formatTreesJSONL(trees []*hash.TreeDigest, breakdown bool) string {
+	var sb strings.Builder
+	write := func(item jsonlTreeEntry) {
+		if data, err := json.Marshal(item); err == nil {
+			sb.Write(data)
+			sb.WriteString("\n")
+		}
+	}
+	for _, tree := range trees {
+		write(jsonlTreeEntry{Type: "tree", Name: tree.Root, Hash: tree.Hash, Algorithm: tree.Algorithm})
+		for _, d := range treeSubdirs(tree, breakdown) {
+			write(jsonlTreeEntry{Type: "directory", Name: path.Join(tree.Root, d.Path), Hash: d.Hash, Algorithm: tree.Algorithm})
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
@@ -3,33 +3,50 @@
 // Progress indicators are shown for operations taking longer than 100ms.
 // The progress bar displays percentage, count, and ETA. It automatically
 // hides when output is not a TTY.
+//
+// With ShowBytes the bar measures bytes rather than items: the total is the
+// combined size of the work, and throughput and ETA are derived from bytes
+// read. Add is safe to call from several goroutines, so hashing workers can
+// report bytes as they stream a file rather than once it is finished.
+//
+// When the total is not known yet (files are still being discovered), the
+// bar starts with UnknownTotal and shows a spinner with the amount done so
+// far; SetTotal turns it into an ordinary bar once the total is known.
 package progress
 
 import (
 	"fmt"
 	"io"
 	"os"
+	"sync"
 	"time"
 
+	"github.com/Les-El/chexum/internal/output"
 	"github.com/schollz/progressbar/v3"
 	"golang.org/x/term"
 )
 
+// UnknownTotal, as Options.Total, starts the bar without a total.
+const UnknownTotal = -1
+
 // Bar wraps a progress bar with TTY-aware display.
 type Bar struct {
+	mu        sync.Mutex
 	bar       *progressbar.ProgressBar
 	total     int64
 	current   int64
 	startTime time.Time
 	isTTY     bool
 	enabled   bool
+	showBytes bool
 	threshold time.Duration
 	writer    io.Writer
 }
 
 // Options configures the progress bar behavior.
 type Options struct {
-	// Total is the total number of items to process
+	// Total is the total number of items (or bytes, with ShowBytes) to process,
+	// or UnknownTotal
 	Total int64
 	// Description is shown before the progress bar
 	Description string
@@ -59,6 +76,7 @@ func NewBar(opts *Options) *Bar {
 		startTime: time.Now(),
 		isTTY:     isTTY,
 		enabled:   false,
+		showBytes: opts.ShowBytes,
 		threshold: opts.Threshold,
 		writer:    opts.Writer,
 	}
@@ -111,8 +129,10 @@ func configureBar(opts *Options) []progressbar.Option {
 	return barOpts
 }
 
-// Add increments the progress by n.
+// Add increments the progress by n. It is safe for concurrent use.
 func (b *Bar) Add(n int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 	b.current += n
 
 	// Check if we should enable the progress bar
@@ -126,6 +146,17 @@ func (b *Bar) Add(n int64) {
 	}
 }
 
+// SetTotal sets the total once it is known, switching a bar started with
+// UnknownTotal from a spinner to a bar with a percentage and ETA.
+func (b *Bar) SetTotal(total int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.total = total
+	if b.bar != nil {
+		b.bar.ChangeMax64(total)
+	}
+}
+
 // Increment increments the progress by 1.
 func (b *Bar) Increment() {
 	b.Add(1)
@@ -133,7 +164,9 @@ func (b *Bar) Increment() {
 
 // SetCurrent sets the current progress value.
 func (b *Bar) SetCurrent(n int64) {
+	b.mu.Lock()
 	delta := n - b.current
+	b.mu.Unlock()
 	if delta > 0 {
 		b.Add(delta)
 	}
@@ -169,6 +202,8 @@ func (b *Bar) WriteMessage(msg string) {
 
 // IsEnabled returns whether the progress bar is currently being displayed.
 func (b *Bar) IsEnabled() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 	return b.enabled && b.isTTY
 }
 
@@ -179,20 +214,29 @@ func (b *Bar) IsTTY() bool {
 
 // ETA returns the estimated time remaining.
 func (b *Bar) ETA() time.Duration {
-	if b.current == 0 {
+	if b.current == 0 || b.total < 0 {
 		return 0
 	}
 
-	elapsed := time.Since(b.startTime)
-	rate := float64(b.current) / elapsed.Seconds()
+	rate := b.Rate()
 	remaining := float64(b.total-b.current) / rate
 
 	return time.Duration(remaining * float64(time.Second))
 }
 
+// Rate returns the average progress per second since the bar was created
+// (bytes per second when ShowBytes is set).
+func (b *Bar) Rate() float64 {
+	elapsed := time.Since(b.startTime).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(b.current) / elapsed
+}
+
 // Percentage returns the current progress as a percentage.
 func (b *Bar) Percentage() float64 {
-	if b.total == 0 {
+	if b.total <= 0 {
 		return 0
 	}
 	return float64(b.current) / float64(b.total) * 100
@@ -200,5 +244,16 @@ func (b *Bar) Percentage() float64 {
 
 // String returns a string representation of the progress.
 func (b *Bar) String() string {
+	if b.total < 0 {
+		if b.showBytes {
+			return fmt.Sprintf("%s so far, %s/s", output.FormatBytes(b.current), output.FormatBytes(int64(b.Rate())))
+		}
+		return fmt.Sprintf("%d so far", b.current)
+	}
+	if b.showBytes {
+		return fmt.Sprintf("%.1f%% (%s/%s, %s/s, ETA %s)", b.Percentage(),
+			output.FormatBytes(b.current), output.FormatBytes(b.total),
+			output.FormatBytes(int64(b.Rate())), b.ETA().Round(time.Second))
+	}
 	return fmt.Sprintf("%.1f%% (%d/%d)", b.Percentage(), b.current, b.total)
 }
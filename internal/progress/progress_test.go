@@ -3,6 +3,8 @@ package progress
 import (
 	"bytes"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 	"testing/quick"
 	"time"
@@ -315,6 +317,24 @@ func TestProgressBarString(t *testing.T) {
 	}
 }
 
+// TestProgressBarSetTotal tests a bar that learns its total part way through.
+func TestProgressBarSetTotal(t *testing.T) {
+	bar := NewBar(&Options{Total: UnknownTotal, Writer: &bytes.Buffer{}})
+	bar.current = 50
+
+	if got := bar.String(); got != "50 so far" {
+		t.Errorf("String() with unknown total = %q", got)
+	}
+	if bar.Percentage() != 0 || bar.ETA() != 0 {
+		t.Errorf("unknown total: Percentage() = %v, ETA() = %v, want 0", bar.Percentage(), bar.ETA())
+	}
+
+	bar.SetTotal(200)
+	if got := bar.String(); got != "25.0% (50/200)" {
+		t.Errorf("String() after SetTotal = %q", got)
+	}
+}
+
 // TestProgressBarWithZeroThreshold tests that progress bar works with zero threshold.
 func TestProgressBarWithZeroThreshold(t *testing.T) {
 	buf := &bytes.Buffer{}
@@ -390,3 +410,31 @@ func TestPercentage(t *testing.T) {
 func TestString(t *testing.T) {
 	TestProgressBarString(t)
 }
+
+func TestBar_ShowBytes(t *testing.T) {
+	b := NewBar(&Options{Total: 4 * 1024 * 1024, ShowBytes: true, Writer: &bytes.Buffer{}})
+	b.startTime = time.Now().Add(-1 * time.Second)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 512; j++ {
+				b.Add(1024)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if b.current != 2*1024*1024 {
+		t.Fatalf("Expected 2 MB after concurrent adds, got %d", b.current)
+	}
+	if b.Rate() <= 0 {
+		t.Error("Expected positive rate")
+	}
+	s := b.String()
+	if !strings.Contains(s, "2.0 MB/4.0 MB") || !strings.Contains(s, "MB/s") {
+		t.Errorf("Unexpected byte progress string %q", s)
+	}
+}
@@ -234,13 +234,33 @@ func ValidateInputs(files []string, hashes []string, opts Options) error {
 	}
 	// Hashes are already classified and normalized, but we can do a sanity check
 	for _, h := range hashes {
-		if !isValidHex(h) {
+		if !isValidHex(h) && !isEncodedDigest(h) {
 			return fmt.Errorf("invalid hash string format: %s", h)
 		}
 	}
 	return nil
 }
 
+// minEncodedDigestLen is the length of the shortest digest chexum accepts in
+// a non-hex encoding (an MD5 digest in unpadded base64).
+const minEncodedDigestLen = 22
+
+// isEncodedDigest reports whether s could be a digest written in base64,
+// base32, SRI or multihash form: long enough, and made only of characters
+// those encodings use.
+func isEncodedDigest(s string) bool {
+	if len(s) < minEncodedDigestLen {
+		return false
+	}
+	for _, c := range s {
+		if !((c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+			c == '+' || c == '/' || c == '=' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
 func isValidHex(s string) bool {
 	if len(s) == 0 {
 		return false
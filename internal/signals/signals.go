@@ -3,9 +3,15 @@
 // It catches SIGINT (Ctrl-C) and provides graceful shutdown:
 // - First Ctrl-C: Stop processing, show status, run cleanup
 // - Second Ctrl-C: Skip cleanup, exit immediately
+//
+// When a caller has requested a Context, the first Ctrl-C cancels that
+// context instead of exiting. This lets long-running work (such as
+// hash.Computer.ComputeBatch) stop cleanly so partial results can be
+// reported before the process exits with code 130.
 package signals
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
@@ -22,7 +28,8 @@ type Handler struct {
 	cleanupTimeout time.Duration
 	mu             sync.Mutex
 	done           chan struct{}
-	exitFunc       func(int) // Allow overriding os.Exit for testing
+	exitFunc       func(int)          // Allow overriding os.Exit for testing
+	cancel         context.CancelFunc // Set by Context; first interrupt cancels instead of exiting
 }
 
 // NewSignalHandler creates a new signal handler with the given cleanup function.
@@ -40,6 +47,19 @@ func (h *Handler) SetCleanupTimeout(d time.Duration) {
 	h.cleanupTimeout = d
 }
 
+// Context returns a context derived from parent that is cancelled on the
+// first interrupt. Once a context has been requested, the first interrupt no
+// longer exits the process: the caller is expected to notice the
+// cancellation, wind down, and exit with code 130 itself. A second interrupt
+// still forces an immediate exit.
+func (h *Handler) Context(parent context.Context) context.Context {
+	ctx, cancel := context.WithCancel(parent)
+	h.mu.Lock()
+	h.cancel = cancel
+	h.mu.Unlock()
+	return ctx
+}
+
 // Start begins listening for interrupt signals.
 func (h *Handler) Start() {
 	sigChan := make(chan os.Signal, 1)
@@ -67,6 +87,12 @@ func (h *Handler) Stop() {
 	default:
 		close(h.done)
 		signal.Reset(os.Interrupt, syscall.SIGTERM)
+		h.mu.Lock()
+		if h.cancel != nil {
+			// Release the context's resources; any work is finished by now.
+			h.cancel()
+		}
+		h.mu.Unlock()
 	}
 }
 
@@ -76,8 +102,17 @@ func (h *Handler) handleInterrupt() {
 	h.interruptCount++
 	count := h.interruptCount
 	h.interrupted = true
+	cancel := h.cancel
 	h.mu.Unlock()
 
+	if count == 1 && cancel != nil {
+		// First interrupt with a cancellable context: let the caller stop
+		// gracefully and report what it has done so far.
+		fmt.Fprintln(os.Stderr, "\n\nInterrupted. Finishing in-flight work (press Ctrl-C again to force exit)...")
+		cancel()
+		return
+	}
+
 	if count == 1 {
 		// First interrupt: graceful shutdown
 		fmt.Fprintln(os.Stderr, "\n\nInterrupted. Cleaning up...")
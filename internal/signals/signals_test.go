@@ -1,6 +1,7 @@
 package signals
 
 import (
+	"context"
 	"sync"
 	"testing"
 	"testing/quick"
@@ -100,6 +101,30 @@ func TestSIGINTHandling(t *testing.T) {
 	}
 }
 
+func TestContextCancelledOnFirstInterrupt(t *testing.T) {
+	handler := NewSignalHandler(nil)
+	exitCode := -1
+	handler.exitFunc = func(code int) { exitCode = code }
+	handler.Start()
+	defer handler.Stop()
+
+	ctx := handler.Context(context.Background())
+	handler.handleInterrupt()
+
+	if ctx.Err() == nil {
+		t.Error("Context should be cancelled after first signal")
+	}
+	if exitCode != -1 {
+		t.Errorf("First signal should not exit when a context is in use, got exit %d", exitCode)
+	}
+
+	// A second interrupt forces the exit.
+	handler.handleInterrupt()
+	if exitCode != 130 {
+		t.Errorf("Second signal should exit with 130, got %d", exitCode)
+	}
+}
+
 func TestCleanupTimeout(t *testing.T) {
 	tracker := &cleanupTracker{}
 	handler := NewSignalHandler(tracker.run)